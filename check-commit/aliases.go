@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var ErrAliasesConfig = errors.New("invalid tag aliases")
+
+func (c CommitPolicyConfig) validateAliases() error {
+	known := c.knownValues()
+
+	for alias, canonical := range c.Aliases {
+		switch {
+		case !known[canonical]:
+			return fmt.Errorf("alias '%s' refers to '%s', which is neither a tag nor a severity: %w", alias, canonical,
+				ErrAliasesConfig)
+		case known[alias]:
+			return fmt.Errorf("alias '%s' is already a tag or a severity: %w", alias, ErrAliasesConfig)
+		}
+	}
+
+	return nil
+}
+
+// canonicalValue returns the tag or severity the value is an alias of, or the value.
+func (c CommitPolicyConfig) canonicalValue(value string) string {
+	if canonical, ok := c.Aliases[value]; ok {
+		return canonical
+	}
+
+	return value
+}
+
+// checkAliases rejects aliased tags and severities in strict mode, suggesting the
+// canonical ones.
+func (c CommitPolicyConfig) checkAliases(values ...string) error {
+	if !c.StrictAliases {
+		return nil
+	}
+
+	for _, value := range values {
+		if canonical, ok := c.Aliases[value]; ok {
+			return fmt.Errorf("'%s' is an alias, please use '%s' instead: %w", value, canonical, ErrTagScope)
+		}
+	}
+
+	return nil
+}
+
+// canonicalSubject returns the subject with the aliases of its tags and severities
+// replaced by the canonical values, and whether there were some.
+func (c CommitPolicyConfig) canonicalSubject(subject string) (string, bool) {
+	quote := ""
+	if strings.HasPrefix(subject, "'") {
+		quote, subject = "'", subject[1:]
+	}
+
+	r := c.tagRegexp()
+	prefixes := ""
+	aliased := false
+
+	subject = eachTagPrefix(r, subject, func(prefix string, m []int) {
+		canonical := prefix
+		names := []string{"severity", "tag"}
+
+		// replace the last group first, so that the bounds of the other remain valid
+		if start, _ := submatchIndex(r, m, "severity"); start >= 0 && start < m[2*r.SubexpIndex("tag")] {
+			names = []string{"tag", "severity"}
+		}
+
+		for _, name := range names {
+			start, end := submatchIndex(r, m, name)
+			if start < 0 {
+				continue
+			}
+
+			value := c.canonicalValue(prefix[start:end])
+			aliased = aliased || value != prefix[start:end]
+			canonical = canonical[:start] + value + canonical[end:]
+		}
+
+		prefixes += canonical
+	})
+
+	return quote + prefixes + subject, aliased
+}
+
+// resolveAliases rewrites the subjects of the commits with the canonical tags, so that
+// they are reported and matched by the other rules as such. Aliases are left as they
+// are in strict mode, to be rejected. The commits also learn the TagFormat of the
+// policy their Tags are read with.
+func (c CommitPolicyConfig) resolveAliases(commits []commitT) {
+	r := c.tagRegexp()
+	for i := range commits {
+		commits[i].tagFormat = r
+	}
+
+	if c.StrictAliases {
+		return
+	}
+
+	for i, commit := range commits {
+		subject, aliased := c.canonicalSubject(commit.Subject())
+		if !aliased {
+			continue
+		}
+
+		log.Printf("commit %s: tags accepted as '%s'", shortSHA(commit.SHA), subject)
+
+		commits[i].Message = subject + commit.Message[len(commit.Subject()):]
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+const aliasesConf = defaultConf + `
+Aliases:
+  FIX: BUG
+  DOCS: DOC
+  MED: MEDIUM
+`
+
+func TestAliases(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(aliasesConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := parseCommitPolicy(aliasesConf + "StrictAliases: true\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject       string
+		want          string
+		wantStrictErr bool
+	}{
+		{"BUG/MINOR: config: fix the parsing of timeouts", "BUG/MINOR: config: fix the parsing of timeouts", false},
+		{"FIX/MINOR: config: fix the parsing of timeouts", "BUG/MINOR: config: fix the parsing of timeouts", true},
+		{"BUG/MED: config: fix the parsing of timeouts", "BUG/MEDIUM: config: fix the parsing of timeouts", true},
+		{"DOCS: config: describe the timeouts in minutes", "DOC: config: describe the timeouts in minutes", true},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckSubject([]byte(tt.subject)); err != nil {
+			t.Errorf("CheckSubject(%s) error = %v", tt.subject, err)
+		}
+
+		if err := strict.CheckSubject([]byte(tt.subject)); (err != nil) != tt.wantStrictErr {
+			t.Errorf("strict CheckSubject(%s) error = %v, wantErr %t", tt.subject, err, tt.wantStrictErr)
+		}
+
+		commits := []commitT{{SHA: "0123456789abcdef", Message: tt.subject + "\n\nSome body."}}
+		c.resolveAliases(commits)
+
+		if commits[0].Subject() != tt.want || commits[0].Body() != "Some body." {
+			t.Errorf("resolveAliases(%s) = %q, want %q", tt.subject, commits[0].Message, tt.want)
+		}
+
+		if tt.wantStrictErr {
+			if suggestion, ok := strict.suggestSubject(tt.subject); !ok || suggestion != tt.want {
+				t.Errorf("suggestSubject(%s) = %s, %t, want %s", tt.subject, suggestion, ok, tt.want)
+			}
+		}
+	}
+}
+
+func TestValidateAliases(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{"Aliases:\n  FIX: BUGS\n", "Aliases:\n  DOC: BUG\n"} {
+		if _, err := parseCommitPolicy(defaultConf + config); !errors.Is(err, ErrAliasesConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want ErrAliasesConfig", config, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v35/github"
+)
+
+type approvalRuleT struct {
+	Values []string `yaml:"Values"`
+	Count  int      `yaml:"Count"`
+	Team   string   `yaml:"Team"`
+}
+
+type approvalRequirementT struct {
+	Count int
+	Teams []string
+}
+
+// requiredApprovals merges every rule triggered by a tag or severity of the commits.
+func (c CommitPolicyConfig) requiredApprovals(commits []commitT) approvalRequirementT {
+	required := approvalRequirementT{}
+	teams := map[string]bool{}
+
+	for _, rule := range c.Approvals {
+		triggered := false
+
+		for _, commit := range commits {
+			if hasAnyValue(c.subjectTags(commit.Subject()), rule.Values) {
+				triggered = true
+
+				break
+			}
+		}
+
+		if !triggered {
+			continue
+		}
+
+		if rule.Count > required.Count {
+			required.Count = rule.Count
+		}
+
+		if rule.Team != "" && !teams[rule.Team] {
+			teams[rule.Team] = true
+			required.Teams = append(required.Teams, rule.Team)
+		}
+	}
+
+	return required
+}
+
+var ErrApprovals = errors.New("missing approvals")
+
+type teamMembershipFunc func(team, user string) (bool, error)
+
+func (r approvalRequirementT) Check(approvers []string, isMember teamMembershipFunc) error {
+	if len(approvers) < r.Count {
+		return fmt.Errorf("%d approval(s) required by the patch types of this request, got %d [%s]: %w",
+			r.Count, len(approvers), strings.Join(approvers, ", "), ErrApprovals)
+	}
+
+	for _, team := range r.Teams {
+		approved := false
+
+		for _, approver := range approvers {
+			member, err := isMember(team, approver)
+			if err != nil {
+				return fmt.Errorf("unable to verify membership of %s in %s: %w", approver, team, err)
+			}
+
+			if member {
+				approved = true
+
+				break
+			}
+		}
+
+		if !approved {
+			return fmt.Errorf("an approval from a member of %s is required: %w", team, ErrApprovals)
+		}
+	}
+
+	return nil
+}
+
+// latestApprovers keeps the most recent review state of each user, dismissed or
+// superseded approvals do not count.
+func latestApprovers(reviews []*github.PullRequestReview) []string {
+	latest := map[string]string{}
+
+	for _, review := range reviews {
+		if review.GetState() == "COMMENTED" {
+			continue
+		}
+
+		latest[review.GetUser().GetLogin()] = review.GetState()
+	}
+
+	approvers := []string{}
+
+	for user, state := range latest {
+		if state == "APPROVED" {
+			approvers = append(approvers, user)
+		}
+	}
+
+	sort.Strings(approvers)
+
+	return approvers
+}
+
+func checkGithubApprovals(required approvalRequirementT) error {
+	owner, project, prNo, err := githubPullRequest()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := newGithubClient(ctx)
+
+	reviews := []*github.PullRequestReview{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, resp, err := client.PullRequests.ListReviews(ctx, owner, project, prNo, opts)
+		if err != nil {
+			return fmt.Errorf("error fetching reviews: %w", err)
+		}
+
+		reviews = append(reviews, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	isMember := func(team, user string) (bool, error) {
+		teamSlice := strings.SplitN(team, "/", 2)
+		if len(teamSlice) < 2 {
+			return false, fmt.Errorf("team '%s' is not in org/team form: %w", team, ErrApprovals)
+		}
+
+		membership, resp, err := client.Teams.GetTeamMembershipBySlug(ctx, teamSlice[0], teamSlice[1], user)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		return membership.GetState() == "active", nil
+	}
+
+	return required.Check(latestApprovers(reviews), isMember)
+}
+
+func checkGitlabApprovals(required approvalRequirementT) error {
+	client, err := newGitlabClient()
+	if err != nil {
+		return err
+	}
+
+	projectID, mrIID, err := gitlabMergeRequest()
+	if err != nil {
+		return err
+	}
+
+	approvals, _, err := client.MergeRequestApprovals.GetConfiguration(projectID, mrIID)
+	if err != nil {
+		return fmt.Errorf("error fetching approvals: %w", err)
+	}
+
+	approvers := []string{}
+	userIDs := map[string]int{}
+
+	for _, approver := range approvals.ApprovedBy {
+		approvers = append(approvers, approver.User.Username)
+		userIDs[approver.User.Username] = approver.User.ID
+	}
+
+	isMember := func(group, user string) (bool, error) {
+		_, resp, err := client.GroupMembers.GetGroupMember(group, userIDs[user])
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+
+		return err == nil, err
+	}
+
+	return required.Check(approvers, isMember)
+}
+
+func (c CommitPolicyConfig) CheckApprovals(repoEnv string, commits []commitT) error {
+	required := c.requiredApprovals(commits)
+	if required.Count == 0 && len(required.Teams) == 0 {
+		return nil
+	}
+
+	log.Printf("request requires %d approval(s) and approvals from [%s]",
+		required.Count, strings.Join(required.Teams, ", "))
+
+	switch repoEnv {
+	case GITHUB:
+		return checkGithubApprovals(required)
+	case GITLAB:
+		return checkGitlabApprovals(required)
+	}
+
+	return fmt.Errorf("unsupported git environment %s: %w", repoEnv, ErrApprovals)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+)
+
+func TestRequiredApprovals(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{Approvals: []approvalRuleT{
+		{Values: []string{"MAJOR", "CRITICAL"}, Count: 2, Team: "haproxy/maintainers"},
+		{Values: []string{"BUG"}, Count: 1},
+	}}
+
+	tests := []struct {
+		name    string
+		subject string
+		want    approvalRequirementT
+	}{
+		{name: "no rule triggered", subject: "DOC: config: fix typo in example", want: approvalRequirementT{}},
+		{name: "tag", subject: "BUG/MINOR: config: fix crash", want: approvalRequirementT{Count: 1}},
+		{
+			name:    "severity",
+			subject: "BUG/CRITICAL: config: fix crash",
+			want:    approvalRequirementT{Count: 2, Teams: []string{"haproxy/maintainers"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := c.requiredApprovals([]commitT{{Message: tt.subject}})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requiredApprovals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApprovalRequirementCheck(t *testing.T) {
+	t.Parallel()
+
+	isMember := func(team, user string) (bool, error) {
+		return user == "wtarreau", nil
+	}
+
+	required := approvalRequirementT{Count: 2, Teams: []string{"haproxy/maintainers"}}
+
+	if err := required.Check([]string{"alice"}, isMember); err == nil {
+		t.Error("Check() expected an error with too few approvals")
+	}
+
+	if err := required.Check([]string{"alice", "bob"}, isMember); err == nil {
+		t.Error("Check() expected an error without a team approval")
+	}
+
+	if err := required.Check([]string{"alice", "wtarreau"}, isMember); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+}
+
+func TestLatestApprovers(t *testing.T) {
+	t.Parallel()
+
+	review := func(user, state string) *github.PullRequestReview {
+		return &github.PullRequestReview{User: &github.User{Login: github.String(user)}, State: github.String(state)}
+	}
+
+	reviews := []*github.PullRequestReview{
+		review("bob", "APPROVED"),
+		review("alice", "CHANGES_REQUESTED"),
+		review("bob", "COMMENTED"),
+		review("alice", "APPROVED"),
+		review("carol", "APPROVED"),
+		review("carol", "DISMISSED"),
+	}
+
+	if got, want := latestApprovers(reviews), []string{"alice", "bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("latestApprovers() = %v, want %v", got, want)
+	}
+}
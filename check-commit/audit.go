@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	exceptionLabel    = "label-exemption"
+	exceptionOverride = "policy-override"
+)
+
+// exceptionT is an exception to the policy exercised during a run.
+type exceptionT struct {
+	Rule    string `json:"rule"`
+	Kind    string `json:"kind"`
+	SHA     string `json:"sha,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// auditRecordT is a line of the audit log: an exception with who exercised it, where
+// and when.
+type auditRecordT struct {
+	Time       string `json:"time"`
+	Actor      string `json:"actor,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Request    string `json:"request,omitempty"`
+	Run        string `json:"run,omitempty"`
+	exceptionT
+}
+
+// auditContext returns the record fields describing the run, from the CI environment.
+func auditContext(repoEnv string) auditRecordT {
+	record := auditRecordT{Time: time.Now().UTC().Format(time.RFC3339), Run: runURL(repoEnv)}
+
+	switch repoEnv {
+	case GITHUB:
+		record.Actor = os.Getenv("GITHUB_ACTOR")
+		record.Repository = os.Getenv("GITHUB_REPOSITORY")
+
+		if _, _, number, err := githubPullRequest(); err == nil {
+			record.Request = fmt.Sprint(number)
+		}
+	case GITLAB:
+		record.Actor = os.Getenv("GITLAB_USER_LOGIN")
+		record.Repository = os.Getenv("CI_PROJECT_PATH")
+		record.Request = os.Getenv("CI_MERGE_REQUEST_IID")
+	}
+
+	return record
+}
+
+// commitCountExemption returns the label exempting the request from MaxCommits, if any.
+func (c CommitPolicyConfig) commitCountExemption(labels []string) string {
+	for _, label := range labels {
+		for _, exempt := range c.MaxCommitsExemptLabels {
+			if label == exempt {
+				return label
+			}
+		}
+	}
+
+	return ""
+}
+
+// checkCommitCount is CheckCommitCount recording the use of an exemption label.
+func (c CommitPolicyConfig) checkCommitCount(count int, labels []string, report *reportT) {
+	if label := c.commitCountExemption(labels); label != "" && c.MaxCommits > 0 && count > c.MaxCommits {
+		report.AddException(exceptionT{
+			Rule:   ruleMaxCommits,
+			Kind:   exceptionLabel,
+			Reason: fmt.Sprintf("%d commits over the limit of %d allowed by label '%s'", count, c.MaxCommits, label),
+		})
+	}
+
+	report.AddError(ruleMaxCommits, c.CheckCommitCount(count, labels))
+}
+
+// recordOverrides records the keys of a central policy overridden by the repository.
+func (c CommitPolicyConfig) recordOverrides(report *reportT) {
+	for _, key := range c.overrides {
+		report.AddException(exceptionT{
+			Rule:   rulePolicy,
+			Kind:   exceptionOverride,
+			Reason: fmt.Sprintf("%s of the central policy overridden by the repository policy", key),
+		})
+	}
+}
+
+// writeAuditLog appends a JSON line per exception to the audit log, so that the log
+// accumulates across runs.
+func writeAuditLog(filename, repoEnv string, exceptions []exceptionT) error {
+	const auditFileMode = 0o644
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, auditFileMode)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	context := auditContext(repoEnv)
+	encoder := json.NewEncoder(f)
+
+	for _, exception := range exceptions {
+		record := context
+		record.exceptionT = exception
+
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("error writing audit log: %w", err)
+		}
+	}
+
+	log.Printf("%d exception(s) written to the audit log %s", len(exceptions), filename)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAuditLog(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{MaxCommits: 1, MaxCommitsExemptLabels: []string{"series"}}
+	report := reportT{}
+
+	c.checkCommitCount(3, []string{"series"}, &report)
+	c.checkCommitCount(1, []string{"series"}, &report)
+
+	if len(report.Findings) != 0 || len(report.Exceptions) != 1 {
+		t.Fatalf("checkCommitCount() findings %v, exceptions %v", report.Findings, report.Exceptions)
+	}
+
+	filename := filepath.Join(t.TempDir(), "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		if err := writeAuditLog(filename, LOCAL, report.Exceptions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log has %d lines, want 2:\n%s", len(lines), data)
+	}
+
+	var record map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record["rule"] != ruleMaxCommits || record["kind"] != exceptionLabel || record["time"] == "" ||
+		!strings.Contains(record["reason"], "label 'series'") {
+		t.Errorf("audit record = %v", record)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// autosquashPrefixes are the prefixes of the subjects of the commits created by git commit
+// --fixup and --squash, to be squashed by git rebase --autosquash.
+var autosquashPrefixes = []string{"fixup!", "squash!", "amend!"}
+
+// autosquashT rejects the commits still to be squashed into another one, downgraded to a
+// warning with Severity.
+type autosquashT struct {
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var ErrAutosquashConfig = errors.New("invalid autosquash rule")
+
+func (a autosquashT) validate() error {
+	if !validSeverity(a.Severity) {
+		return fmt.Errorf("autosquash rule: unknown severity '%s': %w", a.Severity, ErrAutosquashConfig)
+	}
+
+	return nil
+}
+
+func (a autosquashT) severity() string {
+	if a.Severity == "" {
+		return severityError
+	}
+
+	return a.Severity
+}
+
+// autosquashPrefix returns the autosquash prefix of the subject, if any.
+func autosquashPrefix(subject string) string {
+	for _, prefix := range autosquashPrefixes {
+		if strings.HasPrefix(subject, prefix+" ") {
+			return prefix
+		}
+	}
+
+	return ""
+}
+
+var ErrAutosquash = errors.New("commit to be squashed")
+
+func (a autosquashT) Check(subject string) error {
+	prefix := autosquashPrefix(subject)
+	if prefix == "" {
+		return nil
+	}
+
+	return fmt.Errorf("'%s' commit, run 'git rebase -i --autosquash' onto the target branch to squash it "+
+		"before merging: %w", prefix, ErrAutosquash)
+}
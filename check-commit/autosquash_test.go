@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAutosquash(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		subject string
+		wantErr bool
+	}{
+		{"fixup! BUG/MINOR: mux: fix a crash on close", true},
+		{"squash! MINOR: cli: add a command", true},
+		{"amend! MINOR: cli: add a command", true},
+		{"MINOR: cli: add the fixup! command", false},
+		{"fixup!BUG/MINOR: mux: fix a crash on close", false},
+	}
+
+	for _, tt := range tests {
+		if err := (autosquashT{}).Check(tt.subject); (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrAutosquash) {
+			t.Errorf("Check(%q) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	for _, severity := range []string{"", severityWarning} {
+		c, err := parseCommitPolicy(defaultConf + "Autosquash:\n  Severity: '" + severity + "'\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		commits := []commitT{{SHA: "0123456789abcdef", Message: "fixup! BUG/MINOR: mux: fix a crash on close"}}
+		report := c.newReport(commits)
+		c.checkCommits(commits, &report)
+
+		want := c.Autosquash.severity()
+		if len(report.Findings) != 1 || report.Findings[0].Rule != ruleAutosquash || report.Findings[0].Severity != want {
+			t.Errorf("%s: checkCommits() findings = %+v", want, report.Findings)
+		}
+	}
+
+	if _, err := parseCommitPolicy("Autosquash:\n  Severity: fatal\n"); !errors.Is(err, ErrAutosquashConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrAutosquashConfig)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	botValidate = "validate"
+	botExempt   = "exempt"
+
+	botDependabot = "dependabot"
+	botRenovate   = "renovate"
+
+	exceptionBot = "bot-exemption"
+)
+
+// botPrefixRegexp matches the prefix the bots can be configured to add, such as
+// "build(deps): ".
+const botPrefixRegexp = `^(?:[a-z]+(?:\([^)]*\))?!?: )?`
+
+// botT recognizes the commits of a dependency update bot, by the login or name of
+// their author, and the subjects the bot writes.
+type botT struct {
+	Authors []string
+	Subject *regexp.Regexp
+}
+
+// bots are the built-in recognizers of Bots.
+var bots = map[string]botT{
+	botDependabot: {
+		Authors: []string{"dependabot[bot]", "dependabot-preview[bot]", "dependabot"},
+		Subject: regexp.MustCompile(botPrefixRegexp + `(?:\[Security\] )?(?i:bump|update) (?:` +
+			`\S+ (?:requirement )?from .+ to .+|the \S+ group(?: across \d+ director(?:y|ies))? with \d+ updates?)` +
+			`(?: in /\S*)?$`),
+	},
+	botRenovate: {
+		Authors: []string{"renovate[bot]", "renovate-bot", "renovate"},
+		Subject: regexp.MustCompile(botPrefixRegexp + `(?i:update .+ to \S+|update all(?: non-major)? dependencies|` +
+			`pin dependenc(?:y .+ to .+|ies)|lock file maintenance|replace .+ with .+)` +
+			`(?: \[SECURITY\])?(?: \([^)]*\))?$`),
+	},
+}
+
+var ErrBotsConfig = errors.New("invalid bots")
+
+func botNames() []string {
+	names := make([]string, 0, len(bots))
+	for name := range bots {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// validateBots checks that Bots sets known bots to validate or exempt.
+func (c CommitPolicyConfig) validateBots() error {
+	for name, mode := range c.Bots {
+		if _, ok := bots[name]; !ok {
+			names := botNames()
+
+			return fmt.Errorf("Bots: unknown bot '%s'%s, expected one of %s: %w", name, didYouMean(name, names),
+				strings.Join(names, ", "), ErrBotsConfig)
+		}
+
+		if mode != botValidate && mode != botExempt {
+			return fmt.Errorf("Bots: bot '%s': unknown mode '%s', expected %s or %s: %w", name, mode, botValidate,
+				botExempt, ErrBotsConfig)
+		}
+	}
+
+	return nil
+}
+
+// commitBot returns the name of the bot of Bots authoring the commit, if any: by the
+// login the forge reports when it is known, else by the name the author claims.
+func (c CommitPolicyConfig) commitBot(commit commitT) string {
+	candidates := []string{commit.Login}
+
+	if commit.Login == "" {
+		identities := authorIdentities(commit.Author)
+		candidates = []string{identities[0]}
+
+		if len(identities) > 1 {
+			candidates = append(candidates, identities[1])
+		}
+	}
+
+	for _, name := range botNames() {
+		if _, ok := c.Bots[name]; !ok {
+			continue
+		}
+
+		for _, candidate := range candidates {
+			for _, author := range bots[name].Authors {
+				if candidate != "" && strings.EqualFold(candidate, author) {
+					return name
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+var ErrBotFormat = errors.New("invalid bot commit subject")
+
+// checkBotSubject checks the subject of a commit of the bot against the format the bot
+// writes, in place of the conventions of the policy.
+func checkBotSubject(bot, subject string) error {
+	if bots[bot].Subject.MatchString(subject) {
+		return nil
+	}
+
+	return fmt.Errorf("subject of a %s commit is not in the format of %s: %w", bot, bot, ErrBotFormat)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBotSubjects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		bot     string
+		subject string
+		wantErr bool
+	}{
+		{botDependabot, "Bump lodash from 4.17.15 to 4.17.21", false},
+		{botDependabot, "Bump github.com/google/go-github/v35 from 35.2.0 to 35.3.0 in /check-commit", false},
+		{botDependabot, "build(deps): bump the npm group across 2 directories with 5 updates", false},
+		{botDependabot, "[Security] Bump urllib3 from 1.26.4 to 1.26.5", false},
+		{botDependabot, "Update rake requirement from ~> 12.3 to ~> 13.0", false},
+		{botDependabot, "Bump everything", true},
+		{botRenovate, "Update dependency lodash to v4.17.21 [SECURITY]", false},
+		{botRenovate, "chore(deps): update actions/checkout action to v4", false},
+		{botRenovate, "Update all non-major dependencies", false},
+		{botRenovate, "Lock file maintenance", false},
+		{botRenovate, "Pin dependencies", false},
+		{botRenovate, "BUG/MINOR: deps: fix lodash", true},
+	}
+
+	for _, tt := range tests {
+		if err := checkBotSubject(tt.bot, tt.subject); (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrBotFormat) {
+			t.Errorf("checkBotSubject(%s, %q) error = %v, wantErr %v", tt.bot, tt.subject, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBots(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + "Bots:\n  dependabot: validate\n  renovate: exempt\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dependabot := "dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com>"
+	commits := []commitT{
+		{SHA: "0123456789abcdef", Author: dependabot, Message: "Bump lodash from 4.17.15 to 4.17.21"},
+		{SHA: "1123456789abcdef", Author: dependabot, Message: "Bump everything"},
+		{SHA: "2123456789abcdef", Author: "Renovate Bot <bot@renovateapp.com>", Login: "renovate[bot]", Message: "wip"},
+		{SHA: "3123456789abcdef", Author: "Jane Doe <jane@example.com>", Message: "Bump lodash from 4.17.15 to 4.17.21"},
+		{SHA: "4123456789abcdef", Author: "renovate <mallory@evil.example>", Login: "mallory", Message: "wip"},
+	}
+
+	kept, exceptions := c.skipCommits(commits)
+	if len(kept) != 4 || len(exceptions) != 1 || exceptions[0].Kind != exceptionBot {
+		t.Fatalf("skipCommits() = %+v, %+v", kept, exceptions)
+	}
+
+	report := c.newReport(kept)
+	c.checkCommits(kept, &report)
+
+	if len(report.Findings) != 3 || report.Findings[0].Rule != ruleBotFormat || report.Findings[0].SHA != commits[1].SHA ||
+		report.Findings[1].Rule != ruleTag || report.Findings[1].SHA != commits[3].SHA ||
+		report.Findings[2].Rule != ruleTag || report.Findings[2].SHA != commits[4].SHA {
+		t.Errorf("checkCommits() findings = %+v", report.Findings)
+	}
+
+	for _, config := range []string{"Bots:\n  dependabots: validate\n", "Bots:\n  renovate: skip\n"} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrBotsConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrBotsConfig)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const branchOverridesKey = "BranchOverrides"
+
+// branchOverrideT layers policy keys over the rest of the policy when the request
+// targets a branch matching one of the patterns, e.g. stricter rules for release/*.
+type branchOverrideT struct {
+	Branches []string           `yaml:"Branches"`
+	Policy   CommitPolicyConfig `yaml:"Policy"`
+}
+
+var ErrBranchOverride = errors.New("invalid branch override")
+
+func (o branchOverrideT) validate(i int) error {
+	if len(o.Branches) == 0 {
+		return fmt.Errorf("%s entry %d has no branch pattern: %w", branchOverridesKey, i+1, ErrBranchOverride)
+	}
+
+	for _, pattern := range o.Branches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s entry %d: invalid pattern '%s': %s: %w", branchOverridesKey, i+1, pattern, err,
+				ErrBranchOverride)
+		}
+	}
+
+	if len(o.Policy.BranchOverrides) > 0 {
+		return fmt.Errorf("%s entry %d: overrides cannot be nested: %w", branchOverridesKey, i+1, ErrBranchOverride)
+	}
+
+	return nil
+}
+
+func (o branchOverrideT) matches(branch string) bool {
+	for _, pattern := range o.Branches {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// targetBranch returns the branch the pull/merge request targets, or an empty string
+// outside of requests.
+func targetBranch() string {
+	if branch := os.Getenv("GITHUB_BASE_REF"); branch != "" {
+		return branch
+	}
+
+	return os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME")
+}
+
+// applyBranchOverrides returns the policy with the BranchOverrides matching the target
+// branch merged over it in order, later entries winning. All the entries are validated,
+// matching the branch or not.
+func applyBranchOverrides(config, branch string) (string, error) {
+	document := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return config, nil // reported when loading the policy
+	}
+
+	if _, ok := document[branchOverridesKey]; !ok {
+		return config, nil
+	}
+
+	var commitPolicy CommitPolicyConfig
+	if err := unmarshalPolicy(config, &commitPolicy); err != nil {
+		return "", fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	overrides, _ := document[branchOverridesKey].([]interface{})
+	delete(document, branchOverridesKey)
+
+	for i, override := range commitPolicy.BranchOverrides {
+		if err := override.validate(i); err != nil {
+			return "", fmt.Errorf("error loading commit policy: %w", err)
+		}
+
+		if branch == "" || !override.matches(branch) {
+			continue
+		}
+
+		log.Printf("applying the overrides of %s for the target branch %s", strings.Join(override.Branches, ", "), branch)
+
+		entry, _ := overrides[i].(map[interface{}]interface{})
+		if policy, ok := entry["Policy"].(map[interface{}]interface{}); ok {
+			document = mergePolicies(document, policy)
+		}
+	}
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	return string(data), nil
+}
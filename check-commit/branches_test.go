@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyBranchOverrides(t *testing.T) {
+	t.Parallel()
+
+	const config = `
+MaxCommits: 50
+HelpText: default
+BranchOverrides:
+  - Branches: [release/*, stable]
+    Policy:
+      MaxCommits: 5
+  - Branches: [release/2.*]
+    Policy:
+      HelpText: legacy release
+`
+
+	tests := []struct {
+		branch         string
+		wantMaxCommits int
+		wantHelpText   string
+	}{
+		{"", 50, "default"},
+		{"develop", 50, "default"},
+		{"stable", 5, "default"},
+		{"release/3.0", 5, "default"},
+		{"release/2.8", 5, "legacy release"},
+		{"release/2.8/fixes", 50, "default"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.branch, func(t *testing.T) {
+			t.Parallel()
+
+			merged, err := applyBranchOverrides(config, tt.branch)
+			if err != nil {
+				t.Fatalf("applyBranchOverrides() error = %v", err)
+			}
+
+			c, err := parseCommitPolicy(merged)
+			if err != nil || c.MaxCommits != tt.wantMaxCommits || c.HelpText != tt.wantHelpText {
+				t.Errorf("applyBranchOverrides(%s) = %d, %q, %v, want %d, %q", tt.branch, c.MaxCommits, c.HelpText, err,
+					tt.wantMaxCommits, tt.wantHelpText)
+			}
+		})
+	}
+}
+
+func TestApplyBranchOverridesErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr error
+	}{
+		{"no pattern", "BranchOverrides:\n  - Policy:\n      MaxCommits: 5\n", ErrBranchOverride},
+		{"invalid pattern", "BranchOverrides:\n  - Branches: ['release/[']\n", ErrBranchOverride},
+		{"nested", "BranchOverrides:\n  - Branches: [main]\n    Policy:\n      BranchOverrides:\n        - Branches: [x]\n",
+			ErrBranchOverride},
+		{"unknown key of a branch that does not match", "BranchOverrides:\n  - Branches: [main]\n    Policy:\n" +
+			"      MaxCommit: 5\n", ErrPolicySchema},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := applyBranchOverrides(tt.config, "develop"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("applyBranchOverrides() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
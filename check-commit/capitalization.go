@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	capitalizationAny   = "any"
+	capitalizationLower = "require-lower"
+	capitalizationUpper = "require-upper"
+)
+
+// capitalizationT sets the case of the first word after the tags and the component:
+// "BUG/MINOR: fix x" with require-lower, "BUG/MINOR: Fix x" with require-upper, or
+// either with any, the default.
+type capitalizationT struct {
+	Mode     string `yaml:"Mode"`
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var ErrCapitalizationConfig = errors.New("invalid capitalization rule")
+
+func (c capitalizationT) validate() error {
+	switch c.Mode {
+	case "", capitalizationAny, capitalizationLower, capitalizationUpper:
+	default:
+		return fmt.Errorf("capitalization rule: unknown mode '%s', expected %s: %w", c.Mode,
+			strings.Join([]string{capitalizationAny, capitalizationLower, capitalizationUpper}, ", "),
+			ErrCapitalizationConfig)
+	}
+
+	if !validSeverity(c.Severity) {
+		return fmt.Errorf("capitalization rule: unknown severity '%s': %w", c.Severity, ErrCapitalizationConfig)
+	}
+
+	return nil
+}
+
+func (c capitalizationT) severity() string {
+	if c.Severity == "" {
+		return severityError
+	}
+
+	return c.Severity
+}
+
+var ErrCapitalization = errors.New("invalid capitalization of the subject")
+
+// firstWord returns the first word of the text of a subject, past its tags and
+// component, and the word in the case of the Mode. Words cased otherwise than by their
+// first letter, e.g. HTTP or eBPF, are left as is.
+func (c capitalizationT) firstWord(text string) (string, string) {
+	if c.Mode != capitalizationLower && c.Mode != capitalizationUpper {
+		return "", ""
+	}
+
+	if component, ok := subjectComponent(text); ok {
+		text = strings.TrimPrefix(text, component+": ")
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	word := []rune(fields[0])
+	if !unicode.IsLetter(word[0]) || strings.ToLower(string(word[1:])) != string(word[1:]) {
+		return fields[0], fields[0]
+	}
+
+	if c.Mode == capitalizationUpper {
+		return fields[0], string(unicode.ToUpper(word[0])) + string(word[1:])
+	}
+
+	return fields[0], string(unicode.ToLower(word[0])) + string(word[1:])
+}
+
+// recase returns the text of a subject with its first word in the case of the Mode.
+func (c capitalizationT) recase(text string) string {
+	word, want := c.firstWord(text)
+	if word == want {
+		return text
+	}
+
+	prefix := ""
+	if component, ok := subjectComponent(text); ok {
+		prefix = component + ": "
+	}
+
+	return prefix + strings.Replace(strings.TrimPrefix(text, prefix), word, want, 1)
+}
+
+// Check checks the first word of the text of a subject.
+func (c capitalizationT) Check(text string) error {
+	word, want := c.firstWord(text)
+	if word == want {
+		return nil
+	}
+
+	return fmt.Errorf("subject must start with '%s' rather than '%s' after the tags: %w", want, word,
+		ErrCapitalization)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCapitalization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mode    string
+		text    string
+		wantErr bool
+	}{
+		{capitalizationLower, "fix the parsing of the timeouts", false},
+		{capitalizationLower, "Fix the parsing of the timeouts", true},
+		{capitalizationLower, "mux-h2: Fix the stream ids", true},
+		{capitalizationLower, "HTTP/2 streams leak on errors", false},
+		{capitalizationLower, "eBPF: fix the loader", false},
+		{capitalizationUpper, "Fix the parsing of the timeouts", false},
+		{capitalizationUpper, "config: fix the parsing of the timeouts", true},
+		{capitalizationUpper, "'show sess' hangs on empty lists", false},
+		{capitalizationAny, "Fix the parsing of the timeouts", false},
+		{"", "fix the parsing of the timeouts", false},
+	}
+
+	for _, tt := range tests {
+		err := capitalizationT{Mode: tt.mode}.Check(tt.text)
+		if (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrCapitalization) {
+			t.Errorf("Check(%s, %q) error = %v, wantErr %v", tt.mode, tt.text, err, tt.wantErr)
+		}
+	}
+
+	want := "subject must start with 'Fix' rather than 'fix' after the tags: " + ErrCapitalization.Error()
+	if err := (capitalizationT{Mode: capitalizationUpper}).Check("config: fix it"); err == nil || err.Error() != want {
+		t.Errorf("Check() error = %v, want %s", err, want)
+	}
+
+	if _, err := parseCommitPolicy("Capitalization:\n  Mode: lower\n"); !errors.Is(err, ErrCapitalizationConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrCapitalizationConfig)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const centralOverridesKey = "OverridableKeys"
+
+var ErrCentralPolicy = errors.New("invalid central policy")
+
+// centralPolicyReader reads the policy files of a central repository given as
+// "owner/repo" or "owner/repo@ref", through the API of the forge.
+func centralPolicyReader(repoEnv, central string) (policyReaderFunc, error) {
+	repo, ref := central, ""
+	if i := strings.LastIndex(central, "@"); i >= 0 {
+		repo, ref = central[:i], central[i+1:]
+	}
+
+	slash := strings.Index(repo, "/")
+	if slash <= 0 || slash == len(repo)-1 {
+		return nil, fmt.Errorf("'%s' is not in owner/repo[@ref] form: %w", central, ErrCentralPolicy)
+	}
+
+	log.Printf("using the central policy of %s", central)
+
+	if read, ok := forgeFileReader(repoEnv, repo, ref); ok {
+		return read, nil
+	}
+
+	return nil, fmt.Errorf("central policies are read through the GitHub or GitLab API: %w", ErrCentralPolicy)
+}
+
+// forgeFileReader reads the files of another repository of the forge at ref, its
+// default branch when ref is empty. It is not available outside of GitHub and GitLab.
+func forgeFileReader(repoEnv, repo, ref string) (policyReaderFunc, bool) {
+	slash := strings.Index(repo, "/")
+
+	switch repoEnv {
+	case GITHUB:
+		return func(name string) (string, error) {
+			return fetchGithubRepoFile(repo[:slash], repo[slash+1:], ref, name)
+		}, true
+	case GITLAB:
+		if ref == "" {
+			ref = "HEAD"
+		}
+
+		return func(name string) (string, error) {
+			return fetchGitlabProjectFile(repo, ref, name)
+		}, true
+	}
+
+	return nil, false
+}
+
+// applyOverrides returns the central configuration with the top-level keys of the local
+// one that the central configuration allows under OverridableKeys. Other local keys are
+// ignored with a warning, and the allowlist itself cannot be overridden. The keys
+// actually overridden are returned along.
+func applyOverrides(central, local string) (string, []string, error) {
+	effective := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(central), &effective); err != nil {
+		return "", nil, fmt.Errorf("error loading central policy: %w", err)
+	}
+
+	overrides := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(local), &overrides); err != nil {
+		return "", nil, fmt.Errorf("error loading local policy: %w", err)
+	}
+
+	allowed := map[string]bool{}
+
+	if keys, ok := effective[centralOverridesKey].([]interface{}); ok {
+		for _, key := range keys {
+			allowed[fmt.Sprint(key)] = true
+		}
+	}
+
+	ignored := []string{}
+	applied := []string{}
+
+	for key, value := range overrides {
+		if !allowed[key] || key == centralOverridesKey {
+			ignored = append(ignored, key)
+
+			continue
+		}
+
+		effective[key] = value
+		applied = append(applied, key)
+	}
+
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		log.Printf("warning: the central policy does not allow overriding %s, ignored", strings.Join(ignored, ", "))
+	}
+
+	data, err := yaml.Marshal(effective)
+	if err != nil {
+		return "", nil, fmt.Errorf("error merging policies: %w", err)
+	}
+
+	sort.Strings(applied)
+
+	return string(data), applied, nil
+}
+
+// loadCentralPolicy loads the central policy, signed with publicKey if given, with the
+// allowed overrides of the repository policy.
+func loadCentralPolicy(central, local policyReaderFunc, publicKey, preset, profile string) (CommitPolicyConfig,
+	error) {
+	config, err := readVerifiedPolicy(central, publicKey, preset, profile)
+	if err != nil {
+		return CommitPolicyConfig{}, err
+	}
+
+	overrides, err := readPolicyFiles(local, nil)
+	if err != nil && !errors.Is(err, ErrPolicyNotFound) {
+		return CommitPolicyConfig{}, err
+	}
+
+	// the environment overrides are subject to OverridableKeys like the repository policy
+	if overrides, err = applyEnvOverrides(overrides, os.Environ()); err != nil {
+		return CommitPolicyConfig{}, err
+	}
+
+	var applied []string
+
+	if overrides != "" {
+		if config, applied, err = applyOverrides(config, overrides); err != nil {
+			return CommitPolicyConfig{}, err
+		}
+	}
+
+	commitPolicy, err := parseCommitPolicy(config)
+	commitPolicy.overrides = applied
+
+	return commitPolicy, err
+}
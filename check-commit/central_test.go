@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadCentralPolicy(t *testing.T) {
+	t.Parallel()
+
+	reader := func(files map[string]string) policyReaderFunc {
+		return func(name string) (string, error) {
+			if content, ok := files[name]; ok {
+				return content, nil
+			}
+
+			return "", ErrPolicyNotFound
+		}
+	}
+
+	central := reader(map[string]string{policyFile: `
+HelpText: "see the governance handbook"
+MaxCommits: 10
+RequireEnglish: true
+OverridableKeys: [MaxCommits, HelpText]
+`})
+
+	tests := []struct {
+		name           string
+		local          map[string]string
+		wantMaxCommits int
+		wantHelpText   string
+	}{
+		{name: "no local policy", local: map[string]string{}, wantMaxCommits: 10, wantHelpText: "see the governance handbook"},
+		{
+			name: "allowed overrides",
+			local: map[string]string{policyFile: `
+MaxCommits: 3
+HelpText: "see CONTRIBUTING"
+`},
+			wantMaxCommits: 3,
+			wantHelpText:   "see CONTRIBUTING",
+		},
+		{
+			name: "disallowed overrides",
+			local: map[string]string{policyFile: `
+MaxCommits: 3
+RequireEnglish: false
+OverridableKeys: [RequireEnglish]
+`},
+			wantMaxCommits: 3,
+			wantHelpText:   "see the governance handbook",
+		},
+	}
+
+	for _, tt := range tests {
+		c, err := loadCentralPolicy(central, reader(tt.local), "", "", "")
+		if err != nil {
+			t.Fatalf("%s: loadCentralPolicy() error = %v", tt.name, err)
+		}
+
+		if c.MaxCommits != tt.wantMaxCommits || c.HelpText != tt.wantHelpText || !c.RequireEnglish {
+			t.Errorf("%s: loadCentralPolicy() = %+v", tt.name, c)
+		}
+	}
+}
+
+func TestCentralPolicyReader(t *testing.T) {
+	t.Parallel()
+
+	for _, central := range []string{"haproxy", "haproxy/", "/.commit-policy@main"} {
+		if _, err := centralPolicyReader(GITHUB, central); !errors.Is(err, ErrCentralPolicy) {
+			t.Errorf("centralPolicyReader(%s) error = %v, want ErrCentralPolicy", central, err)
+		}
+	}
+
+	if _, err := centralPolicyReader(LOCAL, "haproxy/.commit-policy"); !errors.Is(err, ErrCentralPolicy) {
+		t.Errorf("centralPolicyReader() outside of CI error = %v, want ErrCentralPolicy", err)
+	}
+}
+
+func TestRecordOverrides(t *testing.T) {
+	t.Parallel()
+
+	_, applied, err := applyOverrides("MaxCommits: 10\nOverridableKeys: [MaxCommits, HelpText]\n",
+		"MaxCommits: 3\nHelpText: help\nRequireEnglish: false\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := reportT{}
+	CommitPolicyConfig{overrides: applied}.recordOverrides(&report)
+
+	if len(report.Exceptions) != 2 || report.Exceptions[0].Kind != exceptionOverride {
+		t.Errorf("recordOverrides() = %+v", report.Exceptions)
+	}
+}
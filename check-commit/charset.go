@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// charsetT restricts the characters of subjects, for the changelog tooling that cannot
+// handle others: only ASCII by default, or any character but emoji unless AllowEmoji,
+// with AllowNonASCII. Control characters are always rejected, and the characters of
+// Allow always accepted.
+type charsetT struct {
+	AllowNonASCII bool   `yaml:"AllowNonASCII"`
+	AllowEmoji    bool   `yaml:"AllowEmoji"`
+	Allow         string `yaml:"Allow"`
+	Severity      string `yaml:"Severity"`
+	Shadow        bool   `yaml:"Shadow"`
+}
+
+var ErrCharsetConfig = errors.New("invalid charset rule")
+
+func (c charsetT) validate() error {
+	for _, r := range c.Allow {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("charset rule: control character %U cannot be allowed: %w", r, ErrCharsetConfig)
+		}
+	}
+
+	if !validSeverity(c.Severity) {
+		return fmt.Errorf("charset rule: unknown severity '%s': %w", c.Severity, ErrCharsetConfig)
+	}
+
+	return nil
+}
+
+func (c charsetT) severity() string {
+	if c.Severity == "" {
+		return severityError
+	}
+
+	return c.Severity
+}
+
+// emojiRanges are the blocks of the emoji and of their modifiers.
+var emojiRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1}, // miscellaneous symbols, dingbats
+		{Lo: 0x2b00, Hi: 0x2bff, Stride: 1}, // miscellaneous symbols and arrows
+		{Lo: 0xfe0f, Hi: 0xfe0f, Stride: 1}, // emoji presentation selector
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1}, // pictographs, emoticons, flags, skin tones
+	},
+}
+
+var ErrCharset = errors.New("invalid characters in subject")
+
+// Check checks the characters of the subject, once normalized.
+func (c charsetT) Check(subject string) error {
+	normalized, _ := normalizeSubject(subject)
+
+	for _, r := range normalized {
+		switch {
+		case unicode.IsControl(r):
+			return fmt.Errorf("subject contains the control character %U: %w", r, ErrCharset)
+		case strings.ContainsRune(c.Allow, r) || r <= unicode.MaxASCII:
+		case !c.AllowEmoji && unicode.Is(emojiRanges, r):
+			return fmt.Errorf("subject contains the emoji '%c' (%U): %w", r, r, ErrCharset)
+		case !c.AllowNonASCII:
+			return fmt.Errorf("subject contains the non-ASCII character '%c' (%U): %w", r, r, ErrCharset)
+		}
+	}
+
+	return nil
+}
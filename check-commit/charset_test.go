@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCharset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		charset charsetT
+		subject string
+		wantErr string
+	}{
+		{"ascii", charsetT{}, "BUG/MINOR: mux: fix a crash on close", ""},
+		{"non-ascii", charsetT{}, "DOC: fix the name of José", "subject contains the non-ASCII character 'é' (U+00E9)"},
+		{"allowed", charsetT{Allow: "é"}, "DOC: fix the name of José", ""},
+		{"zero-width", charsetT{}, "DOC: fix the\u200b name of Jose", ""},
+		{"non-ascii allowed", charsetT{AllowNonASCII: true}, "DOC: fix the name of José", ""},
+		{"emoji", charsetT{AllowNonASCII: true}, "MINOR: cli: add rockets 🚀", "subject contains the emoji '🚀' (U+1F680)"},
+		{"emoji allowed", charsetT{AllowNonASCII: true, AllowEmoji: true}, "MINOR: cli: add rockets 🚀", ""},
+		{"control", charsetT{AllowNonASCII: true}, "MINOR: cli: add \x1b[1mbold", "subject contains the control character U+001B"},
+	}
+
+	for _, tt := range tests {
+		err := tt.charset.Check(tt.subject)
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrCharset) || err.Error() != tt.wantErr+": "+ErrCharset.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := parseCommitPolicy("Charset:\n  Allow: \"\\t\"\n"); !errors.Is(err, ErrCharsetConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrCharsetConfig)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -10,9 +11,10 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"unicode"
+	"time"
 	"unicode/utf8"
 
 	"github.com/google/go-github/v35/github"
@@ -23,8 +25,9 @@ import (
 )
 
 type patchTypeT struct {
-	Values []string `yaml:"Values"`
-	Scope  string   `yaml:"Scope"`
+	Values        []string `yaml:"Values"`
+	Scope         string   `yaml:"Scope"`
+	ScopeRequired bool     `yaml:"ScopeRequired"`
 }
 
 type tagAlternativesT struct {
@@ -33,10 +36,80 @@ type tagAlternativesT struct {
 }
 
 type CommitPolicyConfig struct {
-	PatchScopes map[string][]string   `yaml:"PatchScopes"`
-	PatchTypes  map[string]patchTypeT `yaml:"PatchTypes"`
-	TagOrder    []tagAlternativesT    `yaml:"TagOrder"`
-	HelpText    string                `yaml:"HelpText"`
+	Version                int                           `yaml:"Version"`
+	PatchScopes            map[string][]string           `yaml:"PatchScopes"`
+	ScopeSources           map[string]scopeSourceT       `yaml:"ScopeSources"`
+	PatchTypes             map[string]patchTypeT         `yaml:"PatchTypes"`
+	TagOrder               []tagAlternativesT            `yaml:"TagOrder"`
+	TagFormat              string                        `yaml:"TagFormat"`
+	Descriptions           map[string]string             `yaml:"Descriptions"`
+	HelpText               string                        `yaml:"HelpText"`
+	RequireEnglish         bool                          `yaml:"RequireEnglish"`
+	ProtectedBranches      []string                      `yaml:"ProtectedBranches"`
+	MaxCommits             int                           `yaml:"MaxCommits"`
+	MaxCommitsExemptLabels []string                      `yaml:"MaxCommitsExemptLabels"`
+	CustomRules            []customRuleT                 `yaml:"CustomRules"`
+	Approvals              []approvalRuleT               `yaml:"Approvals"`
+	BreakingValues         []string                      `yaml:"BreakingValues"`
+	ChangelogSections      map[string]string             `yaml:"ChangelogSections"`
+	VersionBump            versionBumpT                  `yaml:"VersionBump"`
+	AllowRevertOfRevert    bool                          `yaml:"AllowRevertOfRevert"`
+	DiffHeuristics         diffHeuristicsT               `yaml:"DiffHeuristics"`
+	DiffCrossCheck         bool                          `yaml:"DiffCrossCheck"`
+	Documentation          documentationT                `yaml:"Documentation"`
+	LabelRules             []labelRuleT                  `yaml:"LabelRules"`
+	LinkedIssues           linkedIssuesT                 `yaml:"LinkedIssues"`
+	Encoding               encodingT                     `yaml:"Encoding"`
+	CommitSize             commitSizeT                   `yaml:"CommitSize"`
+	SensitivePaths         []sensitivePathT              `yaml:"SensitivePaths"`
+	PathRules              []pathRuleT                   `yaml:"PathRules"`
+	VersionFile            versionFileT                  `yaml:"VersionFile"`
+	Signatures             signaturesT                   `yaml:"Signatures"`
+	TagConstraints         tagConstraintsT               `yaml:"TagConstraints"`
+	Components             componentsT                   `yaml:"Components"`
+	ForbiddenWords         forbiddenWordsT               `yaml:"ForbiddenWords"`
+	ImperativeMood         imperativeMoodT               `yaml:"ImperativeMood"`
+	TrailingPunctuation    trailingPunctuationT          `yaml:"TrailingPunctuation"`
+	Capitalization         capitalizationT               `yaml:"Capitalization"`
+	Charset                charsetT                      `yaml:"Charset"`
+	Whitespace             whitespaceT                   `yaml:"Whitespace"`
+	TicketReference        ticketReferenceT              `yaml:"TicketReference"`
+	Autosquash             autosquashT                   `yaml:"Autosquash"`
+	Reverts                revertsT                      `yaml:"Reverts"`
+	SpellCheck             spellCheckT                   `yaml:"SpellCheck"`
+	DuplicateSubjects      duplicateSubjectsT            `yaml:"DuplicateSubjects"`
+	SubjectURLs            subjectURLsT                  `yaml:"SubjectURLs"`
+	VagueSubjects          vagueSubjectsT                `yaml:"VagueSubjects"`
+	ForbiddenChars         forbiddenCharsT               `yaml:"ForbiddenChars"`
+	Denylist               denylistT                     `yaml:"Denylist"`
+	TrailingIssue          trailingIssueT                `yaml:"TrailingIssue"`
+	Deprecated             map[string]string             `yaml:"Deprecated"`
+	DeprecatedErrorFrom    string                        `yaml:"DeprecatedErrorFrom"`
+	Ignore                 ignoreT                       `yaml:"Ignore"`
+	ExemptAuthors          []string                      `yaml:"ExemptAuthors"`
+	Bots                   map[string]string             `yaml:"Bots"`
+	BranchOverrides        []branchOverrideT             `yaml:"BranchOverrides"`
+	Profile                string                        `yaml:"Profile"`
+	Profiles               map[string]CommitPolicyConfig `yaml:"Profiles"`
+	SubjectMinLen          int                           `yaml:"SubjectMinLen"`
+	SubjectMaxLen          int                           `yaml:"SubjectMaxLen"`
+	MinWords               int                           `yaml:"MinWords"`
+	TagMinWords            map[string]int                `yaml:"TagMinWords"`
+	MaxWords               int                           `yaml:"MaxWords"`
+	Severities             map[string]string             `yaml:"Severities"`
+	EnforceAfter           map[string]string             `yaml:"EnforceAfter"`
+	RuleHelp               map[string]ruleHelpT          `yaml:"RuleHelp"`
+	Locale                 string                        `yaml:"Locale"`
+	Messages               map[string]catalogT           `yaml:"Messages"`
+	Aliases                map[string]string             `yaml:"Aliases"`
+	StrictAliases          bool                          `yaml:"StrictAliases"`
+	OverridableKeys        []string                      `yaml:"OverridableKeys"`
+	FixComment             bool                          `yaml:"FixComment"`
+	Shadow                 bool                          `yaml:"Shadow"`
+	LintIgnore             []string                      `yaml:"LintIgnore"`
+	Tests                  []policyTestT                 `yaml:"Tests"`
+
+	overrides []string // keys of the central policy overridden by the repository
 }
 
 const (
@@ -82,43 +155,130 @@ TagOrder:
 
 	GITHUB = "Github"
 	GITLAB = "Gitlab"
+	LOCAL  = "Local"
 )
 
 var ErrSubjectMessageFormat = errors.New("invalid subject message format")
 
-func checkSubjectText(subject string) error {
+var ErrSubjectLimits = errors.New("invalid subject limits")
+
+func orDefault(value, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// subjectLimits returns the configured bounds of the subject length and word count,
+// the HAProxy ones by default.
+func (c CommitPolicyConfig) subjectLimits() (minLen, maxLen, minWords, maxWords int) {
+	return orDefault(c.SubjectMinLen, MINSUBJECTLEN), orDefault(c.SubjectMaxLen, MAXSUBJECTLEN),
+		orDefault(c.MinWords, MINSUBJECTPARTS), orDefault(c.MaxWords, MAXSUBJECTPARTS)
+}
+
+func (c CommitPolicyConfig) validateSubjectLimits() error {
+	if c.SubjectMinLen < 0 || c.SubjectMaxLen < 0 || c.MinWords < 0 || c.MaxWords < 0 {
+		return fmt.Errorf("subject limits cannot be negative: %w", ErrSubjectLimits)
+	}
+
+	minLen, maxLen, minWords, maxWords := c.subjectLimits()
+
+	switch {
+	case minLen > maxLen:
+		return fmt.Errorf("SubjectMinLen %d is greater than SubjectMaxLen %d: %w", minLen, maxLen, ErrSubjectLimits)
+	case minWords > maxWords:
+		return fmt.Errorf("MinWords %d is greater than MaxWords %d: %w", minWords, maxWords, ErrSubjectLimits)
+	}
+
+	return c.validateTagMinWords(maxWords)
+}
+
+// validateTagMinWords checks that the TagMinWords are those of tags or severities of
+// the policy, within MaxWords.
+func (c CommitPolicyConfig) validateTagMinWords(maxWords int) error {
+	known := c.knownValues()
+	values := make([]string, 0, len(known))
+
+	for value := range known {
+		values = append(values, value)
+	}
+
+	sort.Strings(values)
+
+	for value, minWords := range c.TagMinWords {
+		if !known[value] {
+			return fmt.Errorf("TagMinWords: '%s' is not a tag or severity%s: %w", value, didYouMean(value, values),
+				ErrSubjectLimits)
+		}
+
+		if minWords < 1 || minWords > maxWords {
+			return fmt.Errorf("TagMinWords: '%s': %d words is not within 1 to MaxWords %d: %w", value, minWords,
+				maxWords, ErrSubjectLimits)
+		}
+	}
+
+	return nil
+}
+
+// tagMinWords returns the fewest words of the subjects with the tags: the largest of the
+// TagMinWords of their tags and severities, else MinWords.
+func (c CommitPolicyConfig) tagMinWords(tags []subjectTagT) int {
+	_, _, minWords, _ := c.subjectLimits()
+	found := false
+
+	for _, tag := range tags {
+		for _, value := range []string{tag.Tag, tag.Severity} {
+			if n, ok := c.TagMinWords[value]; ok && value != "" && (!found || n > minWords) {
+				minWords, found = n, true
+			}
+		}
+	}
+
+	return minWords
+}
+
+func (c CommitPolicyConfig) checkSubjectText(subject string, tags []subjectTagT) error {
 	subjectLen := utf8.RuneCountInString(subject)
 	subjectParts := strings.Fields(subject)
 	subjectPartsLen := len(subjectParts)
+	minLen, maxLen, _, maxWords := c.subjectLimits()
+	minWords := c.tagMinWords(tags)
 
-	if subject != strings.Join(subjectParts, " ") {
-		return fmt.Errorf(
-			"malformatted subject string (trailing or double spaces?): '%s' (%w)",
-			subject, ErrSubjectMessageFormat)
-	}
-
-	if subjectPartsLen < MINSUBJECTPARTS || subjectPartsLen > MAXSUBJECTPARTS {
+	if subjectPartsLen < minWords || subjectPartsLen > maxWords {
 		return fmt.Errorf(
 			"subject word count out of bounds [words %d < %d < %d] '%s': %w",
-			MINSUBJECTPARTS, subjectPartsLen, MAXSUBJECTPARTS, subjectParts, ErrSubjectMessageFormat)
+			minWords, subjectPartsLen, maxWords, subjectParts, ErrSubjectMessageFormat)
 	}
 
-	if subjectLen < MINSUBJECTLEN || subjectLen > MAXSUBJECTLEN {
+	if subjectLen < minLen || subjectLen > maxLen {
 		return fmt.Errorf(
 			"subject length out of bounds [len %d < %d < %d] '%s': %w",
-			MINSUBJECTLEN, subjectLen, MAXSUBJECTLEN, subject, ErrSubjectMessageFormat)
+			minLen, subjectLen, maxLen, subject, ErrSubjectMessageFormat)
 	}
 
 	return nil
 }
 
+// requiresScope tells whether the tag belongs to one of the patch types that require
+// a severity.
+func (c CommitPolicyConfig) requiresScope(tag string, patchTypes []string) bool {
+	for _, name := range patchTypes {
+		if c.PatchTypes[name].ScopeRequired && containsString(c.PatchTypes[name].Values, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c CommitPolicyConfig) CheckPatchTypes(tag, severity string, patchTypeName string) bool {
 	tagScopeOK := false
 
 	for _, allowedTag := range c.PatchTypes[patchTypeName].Values {
 		if tag == allowedTag {
 			if severity == "" {
-				tagScopeOK = true
+				tagScopeOK = !c.PatchTypes[patchTypeName].ScopeRequired
 
 				break
 			}
@@ -145,63 +305,129 @@ func (c CommitPolicyConfig) CheckPatchTypes(tag, severity string, patchTypeName
 var ErrTagScope = errors.New("invalid tag and or severity")
 
 func (c CommitPolicyConfig) CheckSubject(rawSubject []byte) error {
-	// check for ascii-only before anything else
-	for i := 0; i < len(rawSubject); i++ {
-		if rawSubject[i] > unicode.MaxASCII {
-			log.Printf("non-ascii characters detected in in subject:\n%s", hex.Dump(rawSubject))
+	subject := normalizedSubject(rawSubject)
+	if err := c.checkBreakingMarker(string(subject)); err != nil {
+		return err
+	}
 
-			return fmt.Errorf("non-ascii characters in commit subject: %w", ErrTagScope)
-		}
+	text, tags, err := c.consumeTags(subject)
+	if err != nil {
+		return err
 	}
-	// 5 subgroups, 4. is "/severity", 5. is "severity"
-	r := regexp.MustCompile(`^(?P<match>(?P<tag>[A-Z]+)(\/(?P<severity>[A-Z]+))?: )`)
 
-	tTag := []byte("$tag")
-	tScope := []byte("$severity")
-	result := []byte{}
+	return c.checkSubjectWording(string(text), tags)
+}
 
-	candidates := []string{}
+// normalizedSubject returns the normalized form of the subject the rules apply to, with
+// its spacing collapsed: encoding oddities are only reported and the spacing is left to
+// the whitespace rule.
+func normalizedSubject(rawSubject []byte) []byte {
+	normalized, issues := normalizeSubject(string(rawSubject))
+	for _, issue := range issues {
+		log.Printf("warning: %s, raw subject:\n%s", issue, hex.Dump(rawSubject))
+	}
 
-	var tag, severity string
+	return []byte(strings.Join(strings.Fields(normalized), " "))
+}
+
+// tagPrefix returns the canonical tag and severity of the prefix of the subject the
+// submatch of the tag regexp delimits, after checking them against the Aliases.
+func (c CommitPolicyConfig) tagPrefix(r *regexp.Regexp, rawSubject []byte, submatch []int) (string, string, error) {
+	tagPart := rawSubject[submatch[0]:submatch[1]]
+	tag := string(r.Expand(nil, []byte("$tag"), tagPart, submatch))
+	severity := string(r.Expand(nil, []byte("$severity"), tagPart, submatch))
 
-	for _, tagAlternative := range c.TagOrder {
-		tagOK := tagAlternative.Optional
+	if err := c.checkAliases(tag, severity); err != nil {
+		return "", "", err
+	}
 
+	return c.canonicalValue(tag), c.canonicalValue(severity), nil
+}
+
+// acceptedTag tells whether one of the patch types of the i-th TagOrder alternative
+// accepts the tag and severity.
+func (c CommitPolicyConfig) acceptedTag(i int, alternative tagAlternativesT, tag, severity string) bool {
+	for _, pType := range alternative.PatchTypes { // we allow more than one set of tags in a position
+		accepted := c.CheckPatchTypes(tag, severity, pType)
+		tracef("TagOrder alternative %d: patch type %s accepts tag '%s' severity '%s': %t", i+1, pType, tag,
+			severity, accepted)
+
+		if accepted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// consumeTags reads the tags of the TagOrder alternatives off the subject, returning
+// its text past them along with the tags.
+func (c CommitPolicyConfig) consumeTags(rawSubject []byte) ([]byte, []subjectTagT, error) {
+	r := c.tagRegexp()
+	candidates := []string{}
+	consumed := []subjectTagT{}
+
+	for i, tagAlternative := range c.TagOrder {
 		submatch := r.FindSubmatchIndex(rawSubject)
+		tracef("TagOrder alternative %d [%s]: tag prefix regexp %s on '%s': match %v", i+1,
+			strings.Join(tagAlternative.PatchTypes, ", "), r, rawSubject, submatch)
+
 		if len(submatch) == 0 { // no match
+			if !tagAlternative.Optional {
+				return nil, nil, c.tagError("", "", tagAlternative.PatchTypes)
+			}
+
 			continue
 		}
 
-		tagPart := rawSubject[submatch[0]:submatch[1]]
+		tag, severity, err := c.tagPrefix(r, rawSubject, submatch)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		tag = string(r.Expand(result, tTag, tagPart, submatch))
-		severity = string(r.Expand(result, tScope, tagPart, submatch))
+		candidates = append(candidates, string(rawSubject[submatch[0]:submatch[1]]))
 
-		for _, pType := range tagAlternative.PatchTypes { // we allow more than one set of tags in a position
-			if c.CheckPatchTypes(tag, severity, pType) { // we found what we were looking for, so consume input
-				rawSubject = rawSubject[submatch[1]:]
-				tagOK = tagOK || true
+		if c.acceptedTag(i, tagAlternative, tag, severity) { // we found what we were looking for, so consume input
+			rawSubject = rawSubject[submatch[1]:]
+			consumed = append(consumed, subjectTagT{Tag: tag, Severity: severity})
 
-				break
-			}
+			continue
 		}
 
-		candidates = append(candidates, string(tagPart))
-
-		if !tagOK {
+		if !tagAlternative.Optional {
 			log.Printf("unable to find match in %s\n", candidates)
 
-			return fmt.Errorf("invalid tag or no tag found, searched through [%s]: %w",
-				strings.Join(tagAlternative.PatchTypes, ", "), ErrTagScope)
+			if err := repeatedTagError(subjectTagT{Tag: tag, Severity: severity}, consumed); err != nil {
+				return nil, nil, err
+			}
+
+			return nil, nil, c.tagError(tag, severity, tagAlternative.PatchTypes)
 		}
 	}
 
-	submatch := r.FindSubmatchIndex(rawSubject)
-	if len(submatch) != 0 { // no match
-		return fmt.Errorf("detected unprocessed tags, %w", ErrTagScope)
+	// without TagOrder, prefixes such as kernel subsystems are free-form
+	if left := matchTags(r, string(rawSubject)); len(c.TagOrder) > 0 && len(left) > 0 {
+		if err := c.stackedTagError(left[0], consumed); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, fmt.Errorf("detected unprocessed tags, %w", ErrTagScope)
 	}
 
-	return checkSubjectText(string(rawSubject))
+	return rawSubject, consumed, nil
+}
+
+// checkSubjectWording checks the text of the subject past the tags.
+func (c CommitPolicyConfig) checkSubjectWording(text string, tags []subjectTagT) error {
+	if err := c.checkSubjectText(text, tags); err != nil {
+		return err
+	}
+
+	if c.RequireEnglish {
+		return checkSubjectLanguage(text)
+	}
+
+	return nil
 }
 
 func (c CommitPolicyConfig) IsEmpty() bool {
@@ -234,152 +460,626 @@ func readGitEnvironment() (string, error) {
 }
 
 func LoadCommitPolicy(filename string) (CommitPolicyConfig, error) {
-	var commitPolicy CommitPolicyConfig
-
 	var config string
 
 	if data, err := ioutil.ReadFile(filename); err != nil {
 		log.Printf("warning: using built-in fallback configuration with HAProxy defaults (%s)", err)
 
 		config = defaultConf
-	} else {
-		config = string(data)
+	} else if config, err = decodePolicy(string(data)); err != nil {
+		return CommitPolicyConfig{}, err
+	} else if config, err = applyPreset(config, ""); err != nil {
+		return CommitPolicyConfig{}, err
+	}
+
+	return parseCommitPolicy(config)
+}
+
+// validateRules checks the settings of the rules, returning the first invalid one.
+func (c CommitPolicyConfig) validateRules() error {
+	validators := []func() error{c.validateSubjectLimits, c.validateSeverities, c.validateEnforceAfter,
+		c.validateRuleHelp, c.validateLocale, c.validateAliases, c.validateDeprecated, c.validateTagFormat,
+		c.validateDescriptions, c.validateScopeSources, c.validateBots}
+
+	for _, rule := range c.CustomRules {
+		validators = append(validators, rule.validate)
+	}
+
+	validators = append(validators, c.DiffHeuristics.validate)
+
+	for _, rule := range c.LabelRules {
+		validators = append(validators, rule.validate)
+	}
+
+	validators = append(validators, c.LinkedIssues.validate, c.Documentation.validate, c.Encoding.validate,
+		c.CommitSize.validate)
+
+	for _, rule := range c.SensitivePaths {
+		validators = append(validators, rule.validate)
+	}
+
+	for _, rule := range c.PathRules {
+		validators = append(validators, rule.validate)
+	}
+
+	validators = append(validators, c.VersionFile.validate, c.Signatures.validate, c.TagConstraints.validate,
+		c.Components.validate, c.ForbiddenWords.validate, c.ImperativeMood.validate, c.TrailingPunctuation.validate,
+		c.Capitalization.validate, c.Charset.validate, c.Whitespace.validate, c.TicketReference.validate,
+		c.Autosquash.validate, c.Reverts.validate, c.SpellCheck.validate, c.DuplicateSubjects.validate,
+		c.SubjectURLs.validate, c.VagueSubjects.validate,
+		c.ForbiddenChars.validate, c.Denylist.validate, c.TrailingIssue.validate, c.Ignore.validate)
+
+	for _, test := range c.Tests {
+		validators = append(validators, test.validate)
 	}
 
-	if err := yaml.Unmarshal([]byte(config), &commitPolicy); err != nil {
+	for _, validate := range validators {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseCommitPolicy(config string) (CommitPolicyConfig, error) {
+	var commitPolicy CommitPolicyConfig
+
+	if err := unmarshalPolicy(config, &commitPolicy); err != nil {
+		return CommitPolicyConfig{}, fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	if err := commitPolicy.validateReferences(config); err != nil {
 		return CommitPolicyConfig{}, fmt.Errorf("error loading commit policy: %w", err)
 	}
 
+	if err := commitPolicy.validateRules(); err != nil {
+		return CommitPolicyConfig{}, fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	commitPolicy = commitPolicy.withDiffCrossCheck()
+
+	logLintWarnings(lintPolicy(config, commitPolicy, deprecatedKeys))
+
 	return commitPolicy, nil
 }
 
-func getGithubCommitSubjects() ([]string, error) {
-	token := os.Getenv("API_TOKEN")
-	repo := os.Getenv("GITHUB_REPOSITORY")
-	ref := os.Getenv("GITHUB_REF")
+func readGithubEvent(event interface{}) error {
+	data, err := ioutil.ReadFile(os.Getenv("GITHUB_EVENT_PATH"))
+	if err != nil {
+		return fmt.Errorf("error reading event payload: %w", err)
+	}
+
+	if err := json.Unmarshal(data, event); err != nil {
+		return fmt.Errorf("error parsing event payload: %w", err)
+	}
+
+	return nil
+}
+
+func getGithubCommits(repoPath string) ([]commitT, error) {
 	event := os.Getenv("GITHUB_EVENT_NAME")
 
-	ctx := context.Background()
+	switch event {
+	case "pull_request", "pull_request_review":
+		return getGithubPullRequestCommits(repoPath)
+	case "push":
+		return getGithubPushCommits(repoPath)
+	default:
+		return nil, fmt.Errorf("unsupported event name: %s", event)
+	}
+}
 
+func newGithubClient(ctx context.Context) *github.Client {
 	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
+		&oauth2.Token{AccessToken: os.Getenv("API_TOKEN")},
 	)
+
+	if client := cachingHTTPClient(); client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	}
+
 	tc := oauth2.NewClient(ctx, ts)
-	githubClient := github.NewClient(tc)
 
-	if event == "pull_request" {
-		repoSlice := strings.SplitN(repo, "/", 2)
-		if len(repoSlice) < 2 {
-			return nil, fmt.Errorf("error fetching owner and project from repo %s", repo)
-		}
-		owner := repoSlice[0]
-		project := repoSlice[1]
+	return github.NewClient(tc)
+}
 
-		refSlice := strings.SplitN(ref, "/", 4)
-		if len(refSlice) < 3 {
-			return nil, fmt.Errorf("error fetching pr from ref %s", ref)
-		}
-		prNo, err := strconv.Atoi(refSlice[2])
-		if err != nil {
-			return nil, fmt.Errorf("Error fetching pr number from %s: %w", refSlice[2], err)
-		}
+// githubPullRequest returns the owner, project and number of the pull request being checked.
+func githubPullRequest() (string, string, int, error) {
+	repo := os.Getenv("GITHUB_REPOSITORY")
+	ref := os.Getenv("GITHUB_REF")
 
-		commits, _, err := githubClient.PullRequests.ListCommits(ctx, owner, project, prNo, &github.ListOptions{})
-		if err != nil {
-			return nil, fmt.Errorf("error fetching commits: %w", err)
+	repoSlice := strings.SplitN(repo, "/", 2)
+	if len(repoSlice) < 2 {
+		return "", "", 0, fmt.Errorf("error fetching owner and project from repo %s", repo)
+	}
+	owner := repoSlice[0]
+	project := repoSlice[1]
+
+	refSlice := strings.SplitN(ref, "/", 4)
+	if len(refSlice) < 3 {
+		return "", "", 0, fmt.Errorf("error fetching pr from ref %s", ref)
+	}
+	prNo, err := strconv.Atoi(refSlice[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("Error fetching pr number from %s: %w", refSlice[2], err)
+	}
+
+	return owner, project, prNo, nil
+}
+
+func getGithubPullRequestCommits(repoPath string) ([]commitT, error) {
+	// fast path: a single-commit PR can be read from the local clone without an API round trip
+	var event github.PullRequestEvent
+	if err := readGithubEvent(&event); err == nil && event.GetPullRequest().GetCommits() == 1 {
+		commit, err := gitShowCommit(repoPath, event.GetPullRequest().GetHead().GetSHA())
+		if err == nil {
+			return []commitT{commit}, nil
 		}
 
-		subjects := []string{}
-		for _, c := range commits {
-			l := strings.SplitN(c.Commit.GetMessage(), "\n", 2)
-			if len(l) > 0 {
-				subjects = append(subjects, l[0])
-			}
+		log.Printf("unable to read single commit locally, falling back to API (%s)", err)
+	}
+
+	owner, project, prNo, err := githubPullRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	githubClient := newGithubClient(ctx)
+
+	commits, _, err := githubClient.PullRequests.ListCommits(ctx, owner, project, prNo, &github.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching commits: %w", err)
+	}
+
+	result := []commitT{}
+	for _, c := range commits {
+		result = append(result, commitT{
+			SHA:     c.GetSHA(),
+			Author:  authorString(c.GetCommit().GetAuthor().GetName(), c.GetCommit().GetAuthor().GetEmail()),
+			Login:   c.GetAuthor().GetLogin(),
+			Message: c.GetCommit().GetMessage(),
+		})
+	}
+	return result, nil
+}
+
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+func pushEventCommit(c *github.HeadCommit) commitT {
+	return commitT{
+		SHA:     c.GetID(),
+		Author:  authorString(c.GetAuthor().GetName(), c.GetAuthor().GetEmail()),
+		Login:   c.GetAuthor().GetLogin(),
+		Message: c.GetMessage(),
+	}
+}
+
+func getGithubPushCommits(repoPath string) ([]commitT, error) {
+	var event github.PushEvent
+	if err := readGithubEvent(&event); err != nil {
+		return nil, err
+	}
+
+	// fast path: the payload fully describes a single pushed commit, no need to walk the history
+	if len(event.Commits) == 1 && event.HeadCommit != nil {
+		return []commitT{pushEventCommit(event.HeadCommit)}, nil
+	}
+
+	if before := event.GetBefore(); before != "" && before != zeroSHA {
+		commits, err := gitLogCommits(repoPath, before+".."+event.GetAfter())
+		if err == nil {
+			return commits, nil
 		}
-		return subjects, nil
-	} else {
-		return nil, fmt.Errorf("unsupported event name: %s", event)
+
+		log.Printf("warning: unable to read pushed range locally, using event payload (%s)", err)
 	}
+
+	// the payload lists at most 20 commits, which is the best we can do without a usable clone
+	commits := []commitT{}
+	for _, c := range event.Commits {
+		commits = append(commits, pushEventCommit(c))
+	}
+
+	return commits, nil
 }
 
-func getGitlabCommitSubjects() ([]string, error) {
+func newGitlabClient() (*gitlab.Client, error) {
 	gitlab_url := os.Getenv("CI_API_V4_URL")
 	token := os.Getenv("API_TOKEN")
-	mri := os.Getenv("CI_MERGE_REQUEST_IID")
-	project := os.Getenv("CI_MERGE_REQUEST_PROJECT_ID")
 
-	gitlabClient, err := gitlab.NewClient(token, gitlab.WithBaseURL(gitlab_url))
+	options := []gitlab.ClientOptionFunc{gitlab.WithBaseURL(gitlab_url)}
+	if client := cachingHTTPClient(); client != nil {
+		options = append(options, gitlab.WithHTTPClient(client))
+	}
+
+	gitlabClient, err := gitlab.NewClient(token, options...)
 	if err != nil {
-		log.Fatalf("Failed to create gitlab client: %v", err)
+		return nil, fmt.Errorf("failed to create gitlab client: %w", err)
 	}
 
+	return gitlabClient, nil
+}
+
+// gitlabMergeRequest returns the project id and the merge request iid being checked.
+func gitlabMergeRequest() (int, int, error) {
+	mri := os.Getenv("CI_MERGE_REQUEST_IID")
+	project := os.Getenv("CI_MERGE_REQUEST_PROJECT_ID")
+
 	mrIID, err := strconv.Atoi(mri)
 	if err != nil {
-		return nil, fmt.Errorf("invalid merge request id %s", mri)
+		return 0, 0, fmt.Errorf("invalid merge request id %s", mri)
 	}
 
 	projectID, err := strconv.Atoi(project)
 	if err != nil {
-		return nil, fmt.Errorf("invalid project id %s", project)
+		return 0, 0, fmt.Errorf("invalid project id %s", project)
+	}
+
+	return projectID, mrIID, nil
+}
+
+func getGitlabCommits() ([]commitT, error) {
+	gitlabClient, err := newGitlabClient()
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	projectID, mrIID, err := gitlabMergeRequest()
+	if err != nil {
+		return nil, err
 	}
+
 	commits, _, err := gitlabClient.MergeRequests.GetMergeRequestCommits(projectID, mrIID, &gitlab.GetMergeRequestCommitsOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("error fetching commits: %w", err)
 	}
 
-	subjects := []string{}
+	result := []commitT{}
 	for _, c := range commits {
-		l := strings.SplitN(c.Message, "\n", 2)
-		if len(l) > 0 {
-			subjects = append(subjects, l[0])
-		}
+		result = append(result, commitT{
+			SHA:     c.ID,
+			Author:  authorString(c.AuthorName, c.AuthorEmail),
+			Message: c.Message,
+		})
 	}
 
-	return subjects, nil
+	return result, nil
 }
 
-func getCommitSubjects(repoEnv string) ([]string, error) {
-	if repoEnv == GITHUB {
-		return getGithubCommitSubjects()
+func getCommits(repoEnv, repoPath, revRange string) ([]commitT, error) {
+	if repoEnv == LOCAL {
+		return gitLogCommits(repoPath, revRange)
+	} else if repoEnv == GITHUB {
+		return getGithubCommits(repoPath)
 	} else if repoEnv == GITLAB {
-		return getGitlabCommitSubjects()
+		return getGitlabCommits()
 	}
 	return nil, fmt.Errorf("unrecognized git environment %s", repoEnv)
 }
 
+// getSourceBranch returns the branch a pull/merge request was opened from, or an
+// empty string for events that are not tied to one. On GitHub, it is read from the
+// payload of the pull request events, reviews included, for which GITHUB_HEAD_REF is
+// not set.
+func getSourceBranch(repoEnv string) string {
+	switch repoEnv {
+	case GITHUB:
+		switch os.Getenv("GITHUB_EVENT_NAME") {
+		case "pull_request", "pull_request_target", "pull_request_review", "pull_request_review_comment":
+			var event github.PullRequestEvent
+			if err := readGithubEvent(&event); err != nil {
+				log.Printf("warning: unable to read the pull request (%s)", err)
+
+				return ""
+			}
+
+			return event.GetPullRequest().GetHead().GetRef()
+		}
+	case GITLAB:
+		return os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME")
+	}
+
+	return ""
+}
+
+var ErrProtectedBranch = errors.New("request opened from a protected branch")
+
+func (c CommitPolicyConfig) CheckSourceBranch(branch string) error {
+	if branch == "" {
+		return nil
+	}
+
+	for _, pattern := range c.ProtectedBranches {
+		matched, err := path.Match(pattern, branch)
+		if err != nil {
+			log.Printf("warning: ignoring invalid protected branch pattern '%s': %s", pattern, err)
+
+			continue
+		}
+
+		if matched {
+			return fmt.Errorf("source branch '%s' matches protected pattern '%s', "+
+				"please push your changes to a dedicated branch and open the request from there: %w",
+				branch, pattern, ErrProtectedBranch)
+		}
+	}
+
+	return nil
+}
+
+func getRequestLabels(repoEnv string) []string {
+	labels := []string{}
+
+	switch repoEnv {
+	case GITHUB:
+		var event github.PullRequestEvent
+		if err := readGithubEvent(&event); err != nil {
+			log.Printf("warning: unable to read pull request labels (%s)", err)
+
+			return labels
+		}
+
+		for _, label := range event.GetPullRequest().Labels {
+			labels = append(labels, label.GetName())
+		}
+	case GITLAB:
+		for _, label := range strings.Split(os.Getenv("CI_MERGE_REQUEST_LABELS"), ",") {
+			if label != "" {
+				labels = append(labels, label)
+			}
+		}
+	}
+
+	return labels
+}
+
+var ErrTooManyCommits = errors.New("too many commits")
+
+func (c CommitPolicyConfig) CheckCommitCount(count int, labels []string) error {
+	if c.MaxCommits <= 0 || count <= c.MaxCommits {
+		return nil
+	}
+
+	if label := c.commitCountExemption(labels); label != "" {
+		log.Printf("commit count limit skipped because of label '%s'", label)
+
+		return nil
+	}
+
+	return fmt.Errorf("request contains %d commits, %d over the limit of %d; "+
+		"squash them with 'git rebase -i HEAD~%d' (mark the extra commits as 'squash' or 'fixup') "+
+		"and force-push the branch: %w",
+		count, count-c.MaxCommits, c.MaxCommits, count, ErrTooManyCommits)
+}
+
 var ErrSubjectList = errors.New("subjects contain errors")
 
-func (c CommitPolicyConfig) CheckSubjectList(subjects []string) error {
-	errors := false
+func shortSHA(sha string) string {
+	const shortSHALen = 8
 
-	for _, subject := range subjects {
-		subject = strings.Trim(subject, "'")
-		if err := c.CheckSubject([]byte(subject)); err != nil {
-			log.Printf("%s, original subject message '%s'", err, subject)
+	if len(sha) > shortSHALen {
+		return sha[:shortSHALen]
+	}
 
-			errors = true
+	return sha
+}
+
+func (c CommitPolicyConfig) checkCommits(commits []commitT, report *reportT) {
+	now := time.Now()
+	tagFormat := c.tagRegexp()
+
+	for _, commit := range commits {
+		commit.tagFormat = tagFormat
+		subject := strings.Trim(commit.Subject(), "'")
+		tags, text := matchTags(tagFormat, subject), stripTags(tagFormat, subject)
+
+		if c.checkUnformattedCommit(commit, subject, report) {
+			continue
 		}
+
+		if err := c.checkCommitSubject(subject); err != nil {
+			report.AddCommitError(subjectRule(err), severityError, commit, err)
+
+			if subjectRule(err) == ruleTag && c.downgradedTags() {
+				err = c.checkSubjectWording(text, tags)
+				report.AddCommitError(subjectRule(err), severityError, commit, err)
+			}
+		}
+
+		report.AddCommitFinding(ruleTagConstraints, c.TagConstraints.severity(), c.TagConstraints.Shadow, commit,
+			c.TagConstraints.Check(tags))
+		report.AddCommitFinding(ruleComponent, c.Components.severity(), c.Components.Shadow, commit,
+			c.Components.Check(text))
+		report.AddCommitError(ruleDeprecatedTag, c.deprecationSeverity(now), commit, c.checkDeprecatedTags(tags, now))
+		c.checkSubjectStyle(commit, subject, text, report)
+
+		if !c.AllowRevertOfRevert {
+			report.AddCommitError(ruleRevertOfRevert, severityError, commit, checkRevertOfRevert(subject))
+		}
+
+		for _, rule := range c.CustomRules {
+			if !rule.appliesTo(commit) {
+				continue
+			}
+
+			severity := severityError
+			if rule.IsWarning() {
+				severity = severityWarning
+			}
+
+			report.AddCommitFinding(ruleCustomPrefix+rule.Name, severity, rule.Shadow, commit, rule.Check(commit))
+		}
+
+		c.checkDiffHeuristics(commit, report)
+		report.AddCommitFinding(ruleDocumentation, c.Documentation.severity(), c.Documentation.Shadow, commit,
+			c.Documentation.Check(commit))
+		report.AddCommitFinding(ruleCommitSize, c.CommitSize.severity(), c.CommitSize.Shadow, commit,
+			c.CommitSize.Check(commit))
+		report.AddCommitFinding(ruleTicketReference, c.TicketReference.severity(), c.TicketReference.Shadow, commit,
+			c.TicketReference.Check(commit))
+		report.AddCommitFinding(ruleDenylist, c.Denylist.severity(), c.Denylist.Shadow, commit, c.Denylist.Check(commit))
+
+		for _, rule := range c.SensitivePaths {
+			report.AddCommitFinding(ruleSensitivePaths, rule.severity(), rule.Shadow, commit, rule.Check(commit))
+		}
+
+		for _, rule := range c.PathRules {
+			report.AddCommitFinding(rulePathRules, rule.severity(), rule.Shadow, commit, rule.Check(commit))
+		}
+	}
+
+	c.checkDuplicateSubjects(commits, report)
+}
+
+// checkUnformattedCommit checks the commits whose subject does not follow the policy on
+// purpose: those of the Bots, and the fixup commits to be squashed. It tells whether the
+// other rules skip the commit.
+func (c CommitPolicyConfig) checkUnformattedCommit(commit commitT, subject string, report *reportT) bool {
+	if bot := c.commitBot(commit); bot != "" {
+		if c.Bots[bot] == botValidate {
+			report.AddCommitError(ruleBotFormat, severityError, commit, checkBotSubject(bot, subject))
+		}
+
+		return true
+	}
+
+	if autosquashPrefix(subject) != "" {
+		report.AddCommitFinding(ruleAutosquash, c.Autosquash.severity(), c.Autosquash.Shadow, commit,
+			c.Autosquash.Check(subject))
+
+		return true
 	}
 
-	if errors {
+	return false
+}
+
+// checkSubjectStyle applies the rules about the wording of the subject of the commit,
+// text being the subject past its tags.
+func (c CommitPolicyConfig) checkSubjectStyle(commit commitT, subject, text string, report *reportT) {
+	report.AddCommitFinding(ruleForbiddenWords, c.ForbiddenWords.severity(), c.ForbiddenWords.Shadow, commit,
+		c.ForbiddenWords.Check(subject, text))
+	report.AddCommitFinding(ruleImperativeMood, c.ImperativeMood.severity(), c.ImperativeMood.Shadow, commit,
+		c.ImperativeMood.Check(text))
+	report.AddCommitFinding(ruleTrailingPunct, c.TrailingPunctuation.severity(), c.TrailingPunctuation.Shadow,
+		commit, c.TrailingPunctuation.Check(subject))
+	report.AddCommitFinding(ruleCapitalization, c.Capitalization.severity(), c.Capitalization.Shadow, commit,
+		c.Capitalization.Check(text))
+	report.AddCommitFinding(ruleCharset, c.Charset.severity(), c.Charset.Shadow, commit, c.Charset.Check(subject))
+	report.AddCommitFinding(ruleWhitespace, c.Whitespace.severity(), c.Whitespace.Shadow, commit,
+		c.Whitespace.Check(subject))
+	report.AddCommitFinding(ruleForbiddenChars, c.ForbiddenChars.severity(), c.ForbiddenChars.Shadow, commit,
+		c.ForbiddenChars.Check(subject))
+	report.AddCommitFinding(ruleSpelling, c.SpellCheck.severity(), c.SpellCheck.Shadow, commit,
+		c.SpellCheck.Check(text))
+	report.AddCommitFinding(ruleSubjectURL, c.SubjectURLs.severity(), c.SubjectURLs.Shadow, commit,
+		c.SubjectURLs.Check(subject))
+	report.AddCommitFinding(ruleVagueSubject, c.VagueSubjects.severity(), c.VagueSubjects.Shadow, commit,
+		c.VagueSubjects.Check(text))
+	report.AddCommitFinding(ruleTrailingIssue, c.TrailingIssue.severity(), c.TrailingIssue.Shadow, commit,
+		c.TrailingIssue.Check(subject))
+}
+
+func (c CommitPolicyConfig) CheckCommitList(commits []commitT) error {
+	report := c.newReport(nil)
+
+	c.checkCommits(commits, &report)
+
+	if report.Count(severityError) > 0 {
 		return ErrSubjectList
 	}
 
 	return nil
 }
 
-const requiredCmdlineArgs = 2
+func (c CommitPolicyConfig) CheckSubjectList(subjects []string) error {
+	commits := make([]commitT, 0, len(subjects))
+	for _, subject := range subjects {
+		commits = append(commits, commitT{Message: subject})
+	}
 
-func main() {
-	var repoPath string
+	return c.CheckCommitList(commits)
+}
 
-	if len(os.Args) < requiredCmdlineArgs {
-		repoPath = "."
-	} else {
-		repoPath = os.Args[1]
+func mergeReports(opts optionsT) error {
+	reports := []jsonReportT{}
+
+	for _, filename := range opts.reports {
+		report, err := readJSONReport(filename)
+		if err != nil {
+			return err
+		}
+
+		reports = append(reports, report)
 	}
 
-	commitPolicy, err := LoadCommitPolicy(path.Join(repoPath, ".check-commit.yml"))
+	merged, err := mergeJSONReports(reports)
+	if err != nil {
+		log.Printf("warning: %s", err)
+	}
+
+	gitEnv, _ := readGitEnvironment()
+	if err := opts.writeReports(merged, commitURLPrefix(gitEnv, ".")); err != nil {
+		return err
+	}
+
+	log.Printf("merged %d report(s): %d commit(s), %d error(s), %d warning(s)",
+		len(reports), merged.Commits, merged.Errors, merged.Warnings)
+	logStats("rule hits", merged.Stats)
+
+	if merged.Errors > 0 {
+		return ErrSubjectList
+	}
+
+	return err
+}
+
+// resolveRepositoryFiles returns the policy completed with the files of the tree of rev
+// it points to: the values of the ScopeSources, the Dictionary of SpellCheck, the
+// AllowedSigners of Signatures and the File of Denylist.
+func (c CommitPolicyConfig) resolveRepositoryFiles(repoPath, rev string) (CommitPolicyConfig, error) {
+	c, err := c.resolveScopeSources(repoPath, rev)
+	if err != nil {
+		return c, err
+	}
+
+	if c, err = c.resolveDictionary(repoPath, rev); err != nil {
+		return c, err
+	}
+
+	if c, err = c.resolveAllowedSigners(repoPath, rev); err != nil {
+		return c, err
+	}
+
+	return c.resolveDenylist(repoPath, rev)
+}
+
+// readsRepositoryFiles tells whether the policy refers to files of the repository.
+func (c CommitPolicyConfig) readsRepositoryFiles() bool {
+	return len(c.ScopeSources) > 0 || c.SpellCheck.Verify && c.SpellCheck.Dictionary != "" || c.Denylist.File != "" ||
+		c.Signatures.Require && c.Signatures.AllowedSigners != ""
+}
+
+// resolvePolicyFiles returns the policy completed with the files of the repository it
+// refers to, read from the base revision like the policy with --policy-from-base. Every
+// way of loading a policy to check commits goes through it.
+func (opts optionsT) resolvePolicyFiles(c CommitPolicyConfig, repoEnv string) (CommitPolicyConfig, error) {
+	rev := "HEAD"
+	if opts.policyFromBase && c.readsRepositoryFiles() {
+		var err error
+		if rev, err = localBaseRevision(repoEnv, opts.repoPath, opts.revRange); err != nil {
+			return c, err
+		}
+	}
+
+	return c.resolveRepositoryFiles(opts.repoPath, rev)
+}
+
+// loadRunPolicy loads the policy the options designate along with the files of the
+// repository it refers to, exiting on error.
+func loadRunPolicy(opts optionsT, gitEnv string) CommitPolicyConfig {
+	commitPolicy, err := loadEffectivePolicy(opts, gitEnv)
 	if err != nil {
 		log.Fatalf("error reading configuration: %s", err)
 	}
@@ -388,19 +1088,211 @@ func main() {
 		log.Printf("WARNING: using empty configuration (i.e. no verification)")
 	}
 
-	gitEnv, err := readGitEnvironment()
+	return commitPolicy
+}
+
+// runChecks checks the commits selected by the options and returns the report along
+// with the policy and the environment they were checked in.
+func runChecks(opts optionsT) (CommitPolicyConfig, string, reportT) {
+	repoPath := opts.repoPath
+	stopwatch := newStopwatch()
+
+	gitEnv := LOCAL
+	if opts.revRange == "" {
+		var err error
+		if gitEnv, err = readGitEnvironment(); err != nil {
+			log.Fatalf("couldn't auto-detect running environment, please set GITHUB_REF and GITHUB_BASE_REF manually: %s",
+				err)
+		}
+	}
+
+	commitPolicy := loadRunPolicy(opts, gitEnv)
+	stopwatch.lap("load policy")
+
+	commits, err := getCommits(gitEnv, repoPath, opts.revRange)
 	if err != nil {
-		log.Fatalf("couldn't auto-detect running environment, please set GITHUB_REF and GITHUB_BASE_REF manually: %s", err)
+		log.Fatalf("error getting commits: %s", err)
+	}
+
+	stopwatch.lap("fetch commits")
+
+	commits, ignored := commitPolicy.skipCommits(commits)
+
+	if opts.shard.Count > 0 {
+		total := len(commits)
+		commits = opts.shard.Select(commits)
+		log.Printf("shard %s: checking %d of %d commits", opts.shard, len(commits), total)
+	}
+
+	commitPolicy.resolveAliases(commits)
+	commitPolicy.loadDiffs(repoPath, commits)
+	stopwatch.lap("load diffs")
+
+	report := commitPolicy.newReport(commits)
+	report.shadow = commitPolicy.Shadow
+	commitPolicy.recordOverrides(&report)
+
+	for _, exception := range ignored {
+		report.AddException(exception)
+	}
+
+	commitPolicy.checkRequest(gitEnv, commits, &report)
+	commitPolicy.checkCommits(commits, &report)
+	commitPolicy.checkEncodings(repoPath, commits, &report)
+	commitPolicy.checkReverts(repoPath, commits, &report)
+	commitPolicy.checkSignatures(gitEnv, repoPath, commits, &report)
+	stopwatch.lap("checks")
+
+	if opts.shadowPolicy != "" {
+		if err := checkShadowPolicy(opts, gitEnv, &report); err != nil {
+			log.Printf("warning: shadow policy not evaluated: %s", err)
+		}
+
+		stopwatch.lap("shadow policy")
+	}
+
+	report.timings = stopwatch.timings
+
+	return commitPolicy, gitEnv, report
+}
+
+// checkRequest runs the checks of the pull or merge request as a whole, if any.
+func (c CommitPolicyConfig) checkRequest(gitEnv string, commits []commitT, report *reportT) {
+	sourceBranch := getSourceBranch(gitEnv)
+	if sourceBranch == "" {
+		return
+	}
+
+	report.AddError(ruleProtectedBranch, c.CheckSourceBranch(sourceBranch))
+	c.checkCommitCount(len(commits), getRequestLabels(gitEnv), report)
+
+	if len(c.LabelRules) > 0 {
+		report.AddError(ruleLabels, c.CheckLabels(commits, fetchRequestLabels(gitEnv)))
+	}
+	report.AddError(ruleApprovals, c.CheckApprovals(gitEnv, commits))
+	c.checkLinkedIssues(gitEnv, commits, report)
+	report.AddFinding(ruleVersionFile, c.VersionFile.severity(), c.VersionFile.Shadow, c.CheckVersionFile(commits))
+}
+
+// publish sets the step outputs, writes the requested reports and comments the request.
+func (c CommitPolicyConfig) publish(opts optionsT, gitEnv string, report reportT) {
+	repoPath := opts.repoPath
+
+	jsonReport := report.JSON(opts.shard)
+	outputs := c.outputs(report)
+
+	if isDefaultBranchPush(gitEnv) {
+		outputs = append(outputs, c.versionOutputs(repoPath, report.Commits)...)
+	}
+
+	if opts.reword != "" {
+		if written, err := c.writeRewordScript(opts.reword, report.Commits); err != nil {
+			log.Printf("warning: %s", err)
+		} else if written {
+			jsonReport.RewordScript = opts.reword
+			outputs = append(outputs, outputT{Name: "reword_script", Value: opts.reword})
+		}
+	}
+
+	if err := writeGithubOutputs(outputs); err != nil {
+		log.Printf("warning: unable to set step outputs: %s", err)
 	}
 
-	subjects, err := getCommitSubjects(gitEnv)
+	if err := opts.writeReports(jsonReport, commitURLPrefix(gitEnv, repoPath)); err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	stats := report.stats()
+	logStats("rule hits", stats)
+
+	if opts.stats != "" {
+		if total, err := updateStatsFile(opts.stats, stats); err != nil {
+			log.Printf("warning: %s", err)
+		} else {
+			logStats("cumulative rule hits", total)
+		}
+	}
+
+	if opts.auditLog != "" && len(report.Exceptions) > 0 {
+		if err := writeAuditLog(opts.auditLog, gitEnv, report.Exceptions); err != nil {
+			log.Printf("warning: %s", err)
+		}
+	}
+
+	if opts.debugBundle != "" {
+		if err := c.writeDebugBundle(opts.debugBundle, gitEnv, opts, report); err != nil {
+			log.Printf("warning: %s", err)
+		}
+	}
+
+	if c.FixComment && getSourceBranch(gitEnv) != "" {
+		note := ""
+		if jsonReport.RewordScript != "" {
+			note = rewordArtifactNote(gitEnv, jsonReport.RewordScript)
+		}
+
+		body := c.fixInstructions(report, requestHeadSHA(gitEnv), note)
+		if err := postFixComment(gitEnv, body, report.Count(severityError) > 0); err != nil {
+			log.Printf("warning: unable to post the fix instructions: %s", err)
+		}
+	}
+}
+
+func main() {
+	opts, err := parseOptions(os.Args[1:])
 	if err != nil {
-		log.Fatalf("error getting commit subjects: %s", err)
+		log.Fatalf("%s", err)
+	}
+
+	if opts.merge {
+		if err := mergeReports(opts); err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		return
+	}
+
+	if opts.configCommand != "" {
+		if err := runConfigCommand(opts); err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		return
+	}
+
+	opts.policyDir = gitPathPrefix(opts.repoPath)
+	opts.repoPath = gitRepositoryRoot(opts.repoPath)
+	repoPath := opts.repoPath
+
+	if ran, err := runCommand(opts); ran {
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		return
+	}
+
+	if opts.watch {
+		log.Fatalf("%s", watch(opts))
 	}
 
-	if err := commitPolicy.CheckSubjectList(subjects); err != nil {
-		log.Printf("encountered one or more commit message errors\n")
-		log.Fatalf("%s\n", commitPolicy.HelpText)
+	commitPolicy, gitEnv, report := runChecks(opts)
+
+	if opts.review {
+		if err := commitPolicy.review(repoPath, report); err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		return
+	}
+
+	commitPolicy.publish(opts, gitEnv, report)
+
+	if errors := report.Count(severityError); errors > 0 {
+		commitPolicy.logSuggestions(report)
+		logFailureSummary(report)
+		log.Printf("encountered %d error(s)\n", errors)
+		log.Fatalf("%s\n", strings.Join(commitPolicy.helpLines(report), "\n"))
 	}
 
 	log.Printf("check completed without errors\n")
@@ -1,6 +1,13 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestCheckSubject(t *testing.T) {
 	t.Parallel()
@@ -31,6 +38,11 @@ func TestCheckSubject(t *testing.T) {
 			args:    args{subject: "BUG/: config: default implementation"},
 			wantErr: true,
 		},
+		{
+			name:    "missing tag",
+			args:    args{subject: "config: add default location of path to the configuration file"},
+			wantErr: true,
+		},
 		{
 			name:    "wrong tag",
 			args:    args{subject: "WRONG: config: default implementation"},
@@ -42,14 +54,14 @@ func TestCheckSubject(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:    "double spaces",
+			name:    "double spaces are left to the whitespace rule",
 			args:    args{subject: "BUG/MEDIUM: config:  default implementation"},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name:    "trailing spaces",
+			name:    "trailing spaces are left to the whitespace rule",
 			args:    args{subject: "BUG/MEDIUM: config: default implementation "},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name:    "unprocessed tags remain",
@@ -68,3 +80,307 @@ func TestCheckSubject(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSubjectNormalization(t *testing.T) {
+	t.Parallel()
+
+	c, _ := LoadCommitPolicy("")
+
+	tests := []struct {
+		name    string
+		subject string
+	}{
+		{"non-breaking space is normalized", "BUG/MEDIUM: config:\u00a0default implementation"},
+		{"byte order mark is stripped", "\ufeffBUG/MEDIUM: config: default implementation"},
+		{"normalized whitespace is left to the whitespace rule", "BUG/MEDIUM: config: \u2003default implementation"},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckSubject([]byte(tt.subject)); err != nil {
+			t.Errorf("%s: CheckSubject() error = %v", tt.name, err)
+		}
+	}
+}
+
+func TestCheckSubjectListWhitespace(t *testing.T) {
+	t.Parallel()
+
+	c, _ := LoadCommitPolicy("")
+
+	tests := []struct {
+		name    string
+		subject string
+		wantErr error
+	}{
+		{"single spaces", "BUG/MEDIUM: config: default implementation", nil},
+		{"double spaces", "BUG/MEDIUM: config:  default implementation", ErrSubjectList},
+		{"trailing spaces", "BUG/MEDIUM: config: default implementation ", ErrSubjectList},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckSubjectList([]string{tt.subject}); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: CheckSubjectList() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckSubjectLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  string
+		subject string
+		wantErr bool
+	}{
+		{name: "default minimum length", config: "", subject: "fix the parser", wantErr: true},
+		{name: "lower minimum length", config: "SubjectMinLen: 10\n", subject: "fix the parser", wantErr: false},
+		{name: "default word count", config: "", subject: "fix parser crashes", wantErr: false},
+		{name: "higher minimum word count", config: "MinWords: 4\n", subject: "fix parser crashes", wantErr: true},
+		{name: "lower maximum length", config: "SubjectMaxLen: 20\n", subject: "fix parser crashes on empty files",
+			wantErr: true},
+		{name: "lower maximum word count", config: "MaxWords: 4\n", subject: "fix parser crashes on empty files",
+			wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := parseCommitPolicy(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.CheckSubject([]byte(tt.subject)); (err != nil) != tt.wantErr {
+				t.Errorf("CheckSubject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, config := range []string{"SubjectMinLen: 120\n", "MinWords: 5\nMaxWords: 4\n", "MaxWords: -1\n"} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrSubjectLimits) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want ErrSubjectLimits", config, err)
+		}
+	}
+}
+
+func TestCheckSubjectTagMinWords(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + "TagMinWords:\n  MAJOR: 8\n  DOC: 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject string
+		wantErr bool
+	}{
+		{"BUG/MINOR: fix parser crashes", false},
+		{"BUG/MAJOR: fix parser crashes", true},
+		{"MAJOR: fix the parser crashes on empty configuration files", false},
+		{"DOC: document reloads", false},
+		{"DOC/MAJOR: document reloads", true},
+		{"CLEANUP: remove typos", true},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckSubject([]byte(tt.subject)); (err != nil) != tt.wantErr {
+			t.Errorf("CheckSubject(%q) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	if got, want := c.expectedFormat(ruleSubjectFormat), "15 to 100 characters and 3 to 15 words after the tags, "+
+		"the fewest words being 1 for DOC, 8 for MAJOR"; got != want {
+		t.Errorf("expectedFormat() = %q, want %q", got, want)
+	}
+
+	for _, config := range []string{"TagMinWords:\n  MAJR: 8\n", "TagMinWords:\n  MAJOR: 20\n",
+		"TagMinWords:\n  DOC: 0\n"} {
+		if _, err := parseCommitPolicy(defaultConf + config); !errors.Is(err, ErrSubjectLimits) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want ErrSubjectLimits", config, err)
+		}
+	}
+}
+
+func TestCheckSubjectStackedTags(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject string
+		wantErr string
+	}{
+		{"BUG: MINOR: fix the parser crashes", "tags 'BUG' and 'MINOR' are stacked, please write them as 'BUG/MINOR'"},
+		{"MINOR: BUG: fix the parser crashes", "tags 'MINOR' and 'BUG' are stacked, please write them as 'BUG/MINOR'"},
+		{"BUG/MINOR: MAJOR: fix the parser crashes", "tag 'MAJOR' is stacked after 'BUG/MINOR', expected a single " +
+			"tag prefix"},
+		{"BUG: BUG: fix the parser crashes", "tag 'BUG' is repeated, please write it once"},
+		{"BUG/MINOR: MINOR: fix the parser crashes", "tag 'MINOR' is repeated, please write it once"},
+		{"BUG/MINOR: FOO: fix the parser crashes", "detected unprocessed tags"},
+	}
+
+	for _, tt := range tests {
+		err := c.CheckSubject([]byte(tt.subject))
+		if !errors.Is(err, ErrTagScope) || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("CheckSubject(%q) error = %v, want %s", tt.subject, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckSubjectScopeRequired(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(`
+PatchScopes:
+  Severities: [MINOR, MEDIUM, MAJOR]
+PatchTypes:
+  Fixes:
+    Values: [BUG]
+    Scope: Severities
+    ScopeRequired: true
+  Others:
+    Values: [DOC, CLEANUP]
+    Scope: Severities
+TagOrder:
+  - PatchTypes: [Fixes, Others]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject string
+		wantErr bool
+	}{
+		{"BUG/MINOR: config: fix the parsing of timeouts", false},
+		{"BUG: config: fix the parsing of timeouts", true},
+		{"DOC: config: describe the timeouts in minutes", false},
+		{"DOC/MINOR: config: describe the timeouts in minutes", false},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckSubject([]byte(tt.subject)); (err != nil) != tt.wantErr {
+			t.Errorf("CheckSubject(%s) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	if _, err := parseCommitPolicy("PatchTypes:\n  Fixes:\n    Values: [BUG]\n    ScopeRequired: true\n"); !errors.Is(err,
+		ErrPolicySchema) {
+		t.Errorf("parseCommitPolicy() without scope error = %v, want ErrPolicySchema", err)
+	}
+}
+
+func TestCheckSourceBranch(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{ProtectedBranches: []string{"master", "release/*"}}
+
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr bool
+	}{
+		{name: "no request", branch: "", wantErr: false},
+		{name: "topic branch", branch: "fix-parser", wantErr: false},
+		{name: "exact match", branch: "master", wantErr: true},
+		{name: "glob match", branch: "release/2.4", wantErr: true},
+		{name: "glob does not cross slashes", branch: "release/2.4/fix", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := c.CheckSourceBranch(tt.branch); (err != nil) != tt.wantErr {
+				t.Errorf("CheckSourceBranch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// setTestEnv sets the environment variables for the duration of the test, which must
+// not be parallel.
+func setTestEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	for name, value := range env {
+		previous, set := os.LookupEnv(name)
+		name := name
+
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckRequestReview(t *testing.T) {
+	payload := filepath.Join(t.TempDir(), "event.json")
+	if err := ioutil.WriteFile(payload, []byte(`{"pull_request": {"head": {"ref": "master"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	setTestEnv(t, map[string]string{
+		"GITHUB_EVENT_NAME": "pull_request_review", "GITHUB_EVENT_PATH": payload, "GITHUB_HEAD_REF": "",
+	})
+
+	c := CommitPolicyConfig{ProtectedBranches: []string{"master"}}
+	report := reportT{}
+	c.checkRequest(GITHUB, nil, &report)
+
+	if len(report.Findings) != 1 || report.Findings[0].Rule != ruleProtectedBranch {
+		t.Errorf("checkRequest() findings = %+v, want a %s finding", report.Findings, ruleProtectedBranch)
+	}
+}
+
+func TestCheckCommitCount(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{MaxCommits: 1, MaxCommitsExemptLabels: []string{"series"}}
+
+	tests := []struct {
+		name    string
+		count   int
+		labels  []string
+		wantErr bool
+	}{
+		{name: "single commit", count: 1, wantErr: false},
+		{name: "too many commits", count: 3, labels: []string{"bug"}, wantErr: true},
+		{name: "exempt label", count: 3, labels: []string{"bug", "series"}, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := c.CheckCommitCount(tt.count, tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("CheckCommitCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (CommitPolicyConfig{}).CheckCommitCount(100, nil); err != nil {
+		t.Errorf("CheckCommitCount() without limit error = %v", err)
+	}
+
+	report := reportT{severities: map[string]string{ruleMaxCommits: severityWarning}}
+	c.checkCommitCount(3, nil, &report)
+
+	if report.Count(severityWarning) != 1 || report.Count(severityError) != 0 {
+		t.Errorf("checkCommitCount() with a warning severity findings = %+v", report.Findings)
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type shardT struct {
+	Index int // 1-based
+	Count int // 0 when not sharding
+}
+
+var ErrShard = errors.New("invalid shard")
+
+func parseShard(value string) (shardT, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return shardT{}, fmt.Errorf("'%s' is not in i/n form: %w", value, ErrShard)
+	}
+
+	index, err1 := strconv.Atoi(parts[0])
+	count, err2 := strconv.Atoi(parts[1])
+
+	if err1 != nil || err2 != nil || count < 1 || index < 1 || index > count {
+		return shardT{}, fmt.Errorf("'%s' must be i/n with 1 <= i <= n: %w", value, ErrShard)
+	}
+
+	return shardT{Index: index, Count: count}, nil
+}
+
+func (s shardT) String() string {
+	if s.Count == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// Select returns the contiguous slice of commits of the shard; shards of the same
+// list are disjoint, cover it entirely and differ in size by at most one commit.
+func (s shardT) Select(commits []commitT) []commitT {
+	if s.Count == 0 {
+		return commits
+	}
+
+	start := len(commits) * (s.Index - 1) / s.Count
+	end := len(commits) * s.Index / s.Count
+
+	return commits[start:end]
+}
+
+const (
+	formatText = "text"
+	formatHTML = "html"
+)
+
+var ErrFormat = errors.New("invalid report format")
+
+type optionsT struct {
+	repoPath   string
+	revRange   string
+	shard      shardT
+	jsonReport string
+	format     string
+	reword     string
+	output     string
+	merge      bool
+	reports    []string
+	watch      bool
+	review     bool
+	lint       bool
+	doctor     bool
+	interval   time.Duration
+
+	configCommand string // subcommand of config
+	fromHistory   bool
+	force         bool
+	policies      []string // policy files of config lint
+	selfTest      bool
+
+	policyFromBase bool
+	policyKey      string
+	centralPolicy  string
+	auditLog       string
+	stats          string
+	shadowPolicy   string
+	debugBundle    string
+	gitDir         string
+	config         string
+	preset         string
+	profile        string
+	orgPolicy      bool
+	policyDir      string // directory of the repository path, relative to the repository root
+}
+
+// parseSubcommand returns the options of the subcommand the arguments start with, if
+// any, and the arguments past it.
+func parseSubcommand(args []string) (optionsT, []string, error) {
+	opts := optionsT{}
+
+	if len(args) == 0 {
+		return opts, args, nil
+	}
+
+	switch args[0] {
+	case "watch":
+		opts.watch = true
+	case "review":
+		opts.review = true
+	case "lint":
+		opts.lint = true
+	case "doctor":
+		opts.doctor = true
+	case "config":
+		if len(args) < 2 || args[1] != configInit && args[1] != configLint {
+			return optionsT{}, nil, fmt.Errorf("expected config %s or config %s: %w", configInit, configLint,
+				ErrConfigCommand)
+		}
+
+		opts.configCommand = args[1]
+
+		return opts, args[2:], nil
+	default:
+		return opts, args, nil
+	}
+
+	return opts, args[1:], nil
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintf(fs.Output(), "usage: check-commit [options] [repository path]\n"+
+		"       check-commit --merge [--json file] report.json...\n"+
+		"       check-commit watch [--interval duration] [repository path]\n"+
+		"       check-commit review [--range revision range] [repository path]\n"+
+		"       check-commit lint [--policy-key key] [repository path]\n"+
+		"       check-commit doctor [--range revision range] [repository path]\n"+
+		"       check-commit config init [--preset name] [--from-history] [--force] [repository path]\n"+
+		"       check-commit config lint [--policy-key key] [policy file...]\n\noptions:\n")
+	fs.PrintDefaults()
+}
+
+// addReportFlags declares the options selecting the commits to check and the reports
+// of the run.
+func (opts *optionsT) addReportFlags(fs *flag.FlagSet, shard *string) {
+	fs.StringVar(&opts.revRange, "range", "",
+		"check the commits of a revision range of the local clone (e.g. v2.8.0..HEAD) instead of the CI request")
+	fs.StringVar(shard, "shard", "", "only check the i-th of n equal parts of the commits, e.g. 2/8")
+	fs.StringVar(&opts.jsonReport, "json", "", "write the report as JSON to this file")
+	fs.StringVar(&opts.format, "format", formatText, "report format: text (log only) or html")
+	fs.StringVar(&opts.output, "output", "", "write the html report to this file instead of the standard output")
+	fs.StringVar(&opts.reword, "reword-script", "",
+		"write a script applying the suggested subjects to this file, when there are some")
+	fs.StringVar(&opts.auditLog, "audit-log", os.Getenv("CHECK_COMMIT_AUDIT_LOG"),
+		"append a JSON line per exercised policy exception to this file (default $CHECK_COMMIT_AUDIT_LOG)")
+	fs.StringVar(&opts.stats, "stats", "", "add the rule hit counts of the run to the cumulative ones of this JSON file")
+	fs.StringVar(&opts.debugBundle, "debug-bundle", "",
+		"write a sanitized archive describing the run to this file, to attach to bug reports")
+}
+
+// addPolicyFlags declares the options locating the policy and selecting its variant.
+func (opts *optionsT) addPolicyFlags(fs *flag.FlagSet) {
+	// the input of the action reaches its container as INPUT_PROFILE
+	defaultProfile := os.Getenv("CHECK_COMMIT_PROFILE")
+	if defaultProfile == "" {
+		defaultProfile = os.Getenv("INPUT_PROFILE")
+	}
+
+	fs.StringVar(&opts.config, "config", os.Getenv("CHECK_COMMIT_CONFIG"),
+		"path of the policy, relative to the repository root or absolute (default $CHECK_COMMIT_CONFIG, else "+
+			policyFile+" is looked for from the repository path up to the root, then in .github)")
+	fs.StringVar(&opts.preset, "preset", os.Getenv("CHECK_COMMIT_PRESET"),
+		"built-in configuration used without a policy file, and built on by a policy naming none: "+
+			strings.Join(presetNames(), ", ")+" (default $CHECK_COMMIT_PRESET, else "+defaultPreset+")")
+	fs.StringVar(&opts.profile, "profile", defaultProfile,
+		"profile of the policy to apply, e.g. strict (default $CHECK_COMMIT_PROFILE, else the profile action input, "+
+			"else the Profile of the policy)")
+	fs.BoolVar(&opts.orgPolicy, "org-policy", true,
+		"without a policy file, read the one of the "+orgPolicyRepo+" repository of the owner through the GitHub API")
+	fs.BoolVar(&opts.policyFromBase, "policy-from-base", false,
+		"read "+policyFile+" from the base revision of the request instead of the checked out one")
+	fs.StringVar(&opts.policyKey, "policy-key", os.Getenv("CHECK_COMMIT_POLICY_KEY"),
+		"minisign public key the policy must be signed with (default $CHECK_COMMIT_POLICY_KEY)")
+	fs.StringVar(&opts.centralPolicy, "central-policy", os.Getenv("CHECK_COMMIT_CENTRAL_POLICY"),
+		"owner/repo[@ref] of a central repository holding the policy (default $CHECK_COMMIT_CENTRAL_POLICY)")
+	fs.StringVar(&opts.shadowPolicy, "shadow-policy", "",
+		"also evaluate this alternate policy, reporting its findings without failing the check")
+}
+
+// addModeFlags declares the options of the modes and subcommands.
+func (opts *optionsT) addModeFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.gitDir, "git-dir", "",
+		"path of the repository itself, e.g. a bare mirror, instead of a repository path argument")
+	fs.BoolVar(&opts.merge, "merge", false, "merge the JSON reports given as arguments instead of checking commits")
+	fs.BoolVar(&opts.selfTest, "self-test", false,
+		"run the Tests of the policy, checking their sample commits get the expected outcome, instead of checking commits")
+	fs.BoolVar(&opts.fromHistory, "from-history", false,
+		"config init: also accept the tags most used by the history (the --range, else HEAD)")
+	fs.BoolVar(&opts.force, "force", false, "config init: overwrite an existing policy")
+	fs.DurationVar(&opts.interval, "interval", time.Second, "how often watch looks for new commits")
+}
+
+func parseOptions(args []string) (optionsT, error) {
+	opts, args, err := parseSubcommand(args)
+	if err != nil {
+		return optionsT{}, err
+	}
+
+	var shard string
+
+	fs := flag.NewFlagSet("check-commit", flag.ContinueOnError)
+	fs.Usage = func() { printUsage(fs) }
+	opts.addReportFlags(fs, &shard)
+	opts.addPolicyFlags(fs)
+	opts.addModeFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return optionsT{}, err
+	}
+
+	if opts.format != formatText && opts.format != formatHTML {
+		return optionsT{}, fmt.Errorf("'%s' is neither %s nor %s: %w", opts.format, formatText, formatHTML, ErrFormat)
+	}
+
+	if shard != "" {
+		if opts.shard, err = parseShard(shard); err != nil {
+			return optionsT{}, err
+		}
+	}
+
+	if opts.merge {
+		opts.reports = fs.Args()
+
+		return opts, nil
+	}
+
+	if opts.configCommand == configLint {
+		opts.policies = fs.Args()
+
+		return opts, nil
+	}
+
+	opts.repoPath = "."
+
+	switch {
+	case opts.gitDir != "" && fs.NArg() > 0:
+		return optionsT{}, fmt.Errorf("--git-dir and a repository path are mutually exclusive: %w", ErrRepository)
+	case opts.gitDir != "":
+		opts.repoPath = opts.gitDir // git commands run from the git directory as from a bare repository
+	case fs.NArg() > 0:
+		opts.repoPath = fs.Arg(0)
+	}
+
+	return opts, nil
+}
+
+var ErrRepository = errors.New("invalid repository")
+
+var ErrConfigCommand = errors.New("unknown config subcommand")
+
+// runCommand runs the subcommands and modes that do not check commits, telling whether
+// one was run.
+func runCommand(opts optionsT) (bool, error) {
+	switch {
+	case opts.lint:
+		return true, lintCommand(opts)
+	case opts.doctor:
+		return true, doctor(opts, os.Stdout)
+	case opts.selfTest:
+		return true, selfTest(opts, os.Stdout)
+	}
+
+	return false, nil
+}
+
+// runConfigCommand runs the config subcommand; config lint does not need a repository.
+func runConfigCommand(opts optionsT) error {
+	if opts.configCommand == configLint {
+		return configLintCommand(opts)
+	}
+
+	opts.repoPath = gitRepositoryRoot(opts.repoPath)
+
+	return initCommand(opts)
+}
+
+// writeReports writes the JSON and HTML reports requested by the options.
+func (opts optionsT) writeReports(report jsonReportT, commitURL string) error {
+	if opts.jsonReport != "" {
+		if err := writeJSONReport(opts.jsonReport, report); err != nil {
+			return err
+		}
+	}
+
+	if opts.format != formatHTML {
+		return nil
+	}
+
+	if opts.output == "" {
+		return writeHTMLReport(os.Stdout, report, commitURL)
+	}
+
+	f, err := os.Create(opts.output)
+	if err != nil {
+		return fmt.Errorf("error writing HTML report: %w", err)
+	}
+	defer f.Close()
+
+	return writeHTMLReport(f, report, commitURL)
+}
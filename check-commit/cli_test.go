@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    shardT
+		wantErr bool
+	}{
+		{value: "1/1", want: shardT{Index: 1, Count: 1}},
+		{value: "3/8", want: shardT{Index: 3, Count: 8}},
+		{value: "0/8", wantErr: true},
+		{value: "9/8", wantErr: true},
+		{value: "2", wantErr: true},
+		{value: "a/b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseShard(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseShard(%s) = %v, %v, want %v, wantErr %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestShardSelect(t *testing.T) {
+	t.Parallel()
+
+	commits := []commitT{}
+	for i := 0; i < 10; i++ {
+		commits = append(commits, commitT{SHA: fmt.Sprint(i)})
+	}
+
+	for _, count := range []int{1, 3, 4, 10, 12} {
+		seen := []string{}
+
+		for index := 1; index <= count; index++ {
+			part := shardT{Index: index, Count: count}.Select(commits)
+			if len(part) < len(commits)/count || len(part) > len(commits)/count+1 {
+				t.Errorf("shard %d/%d has %d commits", index, count, len(part))
+			}
+
+			for _, c := range part {
+				seen = append(seen, c.SHA)
+			}
+		}
+
+		if fmt.Sprint(seen) != "[0 1 2 3 4 5 6 7 8 9]" {
+			t.Errorf("shards of %d cover %v", count, seen)
+		}
+	}
+
+	if got := (shardT{}).Select(commits); len(got) != len(commits) {
+		t.Errorf("Select() without shard = %d commits, want %d", len(got), len(commits))
+	}
+}
+
+func TestMergeJSONReports(t *testing.T) {
+	t.Parallel()
+
+	shard := func(name string, errs int) jsonReportT {
+		report := jsonReportT{Shards: []string{name}, Commits: 5, Errors: errs}
+		for i := 0; i < errs; i++ {
+			report.Findings = append(report.Findings, findingT{Rule: ruleTag, Severity: severityError})
+		}
+
+		return report
+	}
+
+	merged, err := mergeJSONReports([]jsonReportT{shard("2/2", 1), shard("1/2", 2)})
+	if err != nil {
+		t.Fatalf("mergeJSONReports() error = %v", err)
+	}
+
+	if merged.Commits != 10 || merged.Errors != 3 || len(merged.Findings) != 3 {
+		t.Errorf("mergeJSONReports() = %+v", merged)
+	}
+
+	tests := [][]jsonReportT{
+		{shard("1/3", 0), shard("2/3", 0)},
+		{shard("1/2", 0), shard("1/2", 0)},
+		{shard("1/2", 0), shard("2/3", 0)},
+	}
+
+	for _, reports := range tests {
+		if _, err := mergeJSONReports(reports); !errors.Is(err, ErrIncompleteShards) {
+			t.Errorf("mergeJSONReports(%v) error = %v, want ErrIncompleteShards", reports, err)
+		}
+	}
+}
+
+func TestParseOptionsGitDir(t *testing.T) {
+	t.Parallel()
+
+	opts, err := parseOptions([]string{"--git-dir", "/srv/mirrors/haproxy.git"})
+	if err != nil || opts.repoPath != "/srv/mirrors/haproxy.git" {
+		t.Errorf("parseOptions() = %+v, %v", opts, err)
+	}
+
+	if _, err := parseOptions([]string{"--git-dir", "/srv/mirrors/haproxy.git", "."}); !errors.Is(err, ErrRepository) {
+		t.Errorf("parseOptions() error = %v, want %v", err, ErrRepository)
+	}
+}
+
+func TestParseOptionsConfig(t *testing.T) {
+	t.Parallel()
+
+	opts, err := parseOptions([]string{"config", "init", "--preset", "kernel", "--from-history", "repo"})
+	if err != nil || opts.configCommand != configInit || opts.preset != "kernel" || !opts.fromHistory ||
+		opts.repoPath != "repo" {
+		t.Errorf("parseOptions() = %+v, %v", opts, err)
+	}
+
+	opts, err = parseOptions([]string{"config", "lint", "a.yml", "b.yml"})
+	if err != nil || opts.configCommand != configLint || !reflect.DeepEqual(opts.policies, []string{"a.yml", "b.yml"}) {
+		t.Errorf("parseOptions() = %+v, %v", opts, err)
+	}
+
+	if _, err := parseOptions([]string{"config", "show"}); !errors.Is(err, ErrConfigCommand) {
+		t.Errorf("parseOptions() error = %v, want %v", err, ErrConfigCommand)
+	}
+}
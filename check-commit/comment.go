@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/xanzy/go-gitlab"
+)
+
+// commentMarker identifies the sticky comment of check-commit among the request ones.
+const commentMarker = "<!-- check-commit -->"
+
+// fixInstructions returns the markdown body of the sticky comment: a checklist of the
+// commits with errors, their violations and a recipe to fix them. headSHA is the
+// last commit of the request, which can simply be amended. artifactNote, if not empty,
+// tells where to get the reword script of the run.
+func (c CommitPolicyConfig) fixInstructions(report reportT, headSHA, artifactNote string) string {
+	var b strings.Builder
+
+	b.WriteString(commentMarker + "\n")
+
+	if report.Count(severityError) == 0 {
+		b.WriteString("### check-commit: all commits comply\n\nThanks for fixing them!\n")
+
+		return b.String()
+	}
+
+	request := []string{}
+
+	for _, finding := range report.Findings {
+		if finding.SHA == "" && finding.Severity == severityError && !finding.Shadow {
+			request = append(request, finding.Message)
+		}
+	}
+
+	items := c.newReview(report).items
+	failing := 0
+
+	for _, item := range items {
+		if hasErrors(item.Findings) {
+			failing++
+		}
+	}
+
+	fmt.Fprintf(&b, "### check-commit: %d commit(s) need to be fixed\n\n", failing)
+
+	for _, message := range request {
+		fmt.Fprintf(&b, "- [ ] %s\n", message)
+	}
+
+	for _, item := range items {
+		if hasErrors(item.Findings) {
+			c.writeCommitInstructions(&b, item, headSHA)
+		}
+	}
+
+	if artifactNote != "" {
+		b.WriteString("\n" + artifactNote + "\n")
+	}
+
+	b.WriteString("\nOnce all commits are fixed, update the request with `git push --force-with-lease`.\n")
+
+	if c.HelpText != "" {
+		b.WriteString("\n" + c.HelpText + "\n")
+	}
+
+	return b.String()
+}
+
+// writeCommitInstructions writes the checklist item of a commit with errors: its
+// violations, with the help of their rules, and the recipe to fix it.
+func (c CommitPolicyConfig) writeCommitInstructions(b *strings.Builder, item reviewItemT, headSHA string) {
+	fmt.Fprintf(b, "- [ ] `%s` %s\n", shortSHA(item.Commit.SHA), markdownCode(item.Commit.Subject()))
+
+	for _, finding := range item.Findings {
+		prefix := ""
+		if finding.Severity == severityWarning {
+			prefix = "warning: "
+		}
+
+		fmt.Fprintf(b, "  - %s%s\n", prefix, finding.Message)
+
+		if help := c.ruleHelp(finding); help != "" {
+			fmt.Fprintf(b, "    %s\n", help)
+		}
+	}
+
+	b.WriteString("\n  ```sh\n")
+
+	for _, line := range fixRecipe(item, headSHA) {
+		b.WriteString("  " + line + "\n")
+	}
+
+	b.WriteString("  ```\n")
+}
+
+func hasErrors(findings []findingT) bool {
+	for _, finding := range findings {
+		if finding.Severity == severityError && !finding.Shadow {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markdownCode returns s as inline code, even when it contains backquotes.
+func markdownCode(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+
+	return fence + " " + s + " " + fence
+}
+
+// fixRecipe returns the commands rewording a commit: an amend for the last commit of
+// the request, an interactive rebase stopping on the commit otherwise.
+func fixRecipe(item reviewItemT, headSHA string) []string {
+	amend := "git commit --amend"
+	if item.Suggestion != "" {
+		amend += " -m " + shellQuote(item.Suggestion)
+	}
+
+	if item.Commit.SHA == headSHA {
+		return []string{"# last commit of the request", amend}
+	}
+
+	short := shortSHA(item.Commit.SHA)
+
+	return []string{
+		"# stop on the commit, fix it and resume the rebase",
+		fmt.Sprintf(`git -c sequence.editor="sed -i 's/^pick %s/edit %s/'" rebase -i %s~1`, short, short, short),
+		amend,
+		"git rebase --continue",
+	}
+}
+
+// requestHeadSHA returns the last commit of the request.
+func requestHeadSHA(repoEnv string) string {
+	switch repoEnv {
+	case GITHUB:
+		var event github.PullRequestEvent
+		if err := readGithubEvent(&event); err == nil {
+			return event.GetPullRequest().GetHead().GetSHA()
+		}
+	case GITLAB:
+		return os.Getenv("CI_COMMIT_SHA")
+	}
+
+	return ""
+}
+
+// postFixComment creates or updates the sticky comment of the request. A comment is
+// only created when there are errors, but an existing one is always updated so that
+// it never shows outdated instructions.
+func postFixComment(repoEnv, body string, hasErrors bool) error {
+	switch repoEnv {
+	case GITHUB:
+		return postGithubComment(body, hasErrors)
+	case GITLAB:
+		return postGitlabComment(body, hasErrors)
+	}
+
+	return nil
+}
+
+func postGithubComment(body string, create bool) error {
+	owner, project, prNo, err := githubPullRequest()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := newGithubClient(ctx)
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, project, prNo, opts)
+		if err != nil {
+			return fmt.Errorf("error fetching comments: %w", err)
+		}
+
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.GetBody(), commentMarker) {
+				_, _, err := client.Issues.EditComment(ctx, owner, project, comment.GetID(), &github.IssueComment{Body: &body})
+				if err != nil {
+					return fmt.Errorf("error updating comment: %w", err)
+				}
+
+				return nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	if !create {
+		return nil
+	}
+
+	if _, _, err := client.Issues.CreateComment(ctx, owner, project, prNo, &github.IssueComment{Body: &body}); err != nil {
+		return fmt.Errorf("error creating comment: %w", err)
+	}
+
+	return nil
+}
+
+func postGitlabComment(body string, create bool) error {
+	client, err := newGitlabClient()
+	if err != nil {
+		return err
+	}
+
+	projectID, mrIID, err := gitlabMergeRequest()
+	if err != nil {
+		return err
+	}
+
+	opts := &gitlab.ListMergeRequestNotesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+
+	for {
+		notes, resp, err := client.Notes.ListMergeRequestNotes(projectID, mrIID, opts)
+		if err != nil {
+			return fmt.Errorf("error fetching comments: %w", err)
+		}
+
+		for _, note := range notes {
+			if strings.HasPrefix(note.Body, commentMarker) {
+				_, _, err := client.Notes.UpdateMergeRequestNote(projectID, mrIID, note.ID,
+					&gitlab.UpdateMergeRequestNoteOptions{Body: &body})
+				if err != nil {
+					return fmt.Errorf("error updating comment: %w", err)
+				}
+
+				return nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	if !create {
+		return nil
+	}
+
+	if _, _, err := client.Notes.CreateMergeRequestNote(projectID, mrIID,
+		&gitlab.CreateMergeRequestNoteOptions{Body: &body}); err != nil {
+		return fmt.Errorf("error creating comment: %w", err)
+	}
+
+	return nil
+}
+
+// rewordArtifactNote tells how to apply the reword script uploaded by the workflow.
+func rewordArtifactNote(repoEnv, filename string) string {
+	run := "this run"
+	if url := runURL(repoEnv); url != "" {
+		run = "[this run](" + url + ")"
+	}
+
+	return fmt.Sprintf("All suggested subjects can be applied at once with the reword script: download `%s` "+
+		"from the artifacts of %s and run `sh %s` from your branch.", filepath.Base(filename), run, filepath.Base(filename))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFixInstructions(t *testing.T) {
+	t.Parallel()
+
+	c, err := LoadCommitPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := reportT{Commits: []commitT{
+		{SHA: "1111111111", Message: "bug/minor: comment: first failing commit"},
+		{SHA: "2222222222", Message: "MINOR: comment: compliant commit of the series"},
+		{SHA: "3333333333", Message: "wip"},
+	}}
+	report.AddError(ruleMaxCommits, ErrTooManyCommits)
+	c.checkCommits(report.Commits, &report)
+
+	body := c.fixInstructions(report, "3333333333", rewordArtifactNote("", "out/reword.sh"))
+
+	for _, want := range []string{
+		commentMarker + "\n### check-commit: 2 commit(s) need to be fixed",
+		"- [ ] too many commits\n",
+		"- [ ] `11111111` ` bug/minor: comment: first failing commit `\n",
+		`git -c sequence.editor="sed -i 's/^pick 11111111/edit 11111111/'" rebase -i 11111111~1`,
+		"git commit --amend -m 'BUG/MINOR: comment: first failing commit'",
+		"- [ ] `33333333` ` wip `\n",
+		"  # last commit of the request\n  git commit --amend\n",
+		"download `reword.sh` from the artifacts of this run and run `sh reword.sh`",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("fixInstructions() does not contain %q:\n%s", want, body)
+		}
+	}
+
+	if strings.Contains(body, "22222222") {
+		t.Errorf("fixInstructions() lists a compliant commit:\n%s", body)
+	}
+
+	if body := c.fixInstructions(reportT{}, "", ""); !strings.Contains(body, "all commits comply") {
+		t.Errorf("fixInstructions() without errors = %s", body)
+	}
+}
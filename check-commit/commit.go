@@ -0,0 +1,75 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+type commitT struct {
+	SHA     string
+	Author  string // "Name <email>"
+	Login   string // account of the author on the forge, when known
+	Message string
+	// Files is only filled for commits some rule needs the diff of, see HasDiff
+	Files   []fileDiffT
+	HasDiff bool
+	// NamesOnly is set when Files lack the changed lines, as in partial clones
+	// whose blobs cannot be fetched
+	NamesOnly bool
+
+	tagFormat *regexp.Regexp // of the policy, set when resolving the aliases
+}
+
+func commitSubject(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
+
+func (c commitT) Subject() string {
+	return commitSubject(c.Message)
+}
+
+// Tags returns the tag prefixes of the subject, in the TagFormat of the policy or the
+// TAG/SEVERITY format when the commit was not resolved against one.
+func (c commitT) Tags() []subjectTagT {
+	if c.tagFormat == nil {
+		return matchTags(tagPrefixRegexp(), c.Subject())
+	}
+
+	return matchTags(c.tagFormat, c.Subject())
+}
+
+// Body returns the message without its subject line and surrounding blank lines.
+func (c commitT) Body() string {
+	parts := strings.SplitN(c.Message, "\n", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return strings.Trim(parts[1], "\n")
+}
+
+// Trailers returns the "Key: value" lines of the last body paragraph, if that
+// paragraph consists of trailers only.
+func (c commitT) Trailers() []string {
+	body := strings.TrimRight(c.Body(), "\n ")
+	if body == "" {
+		return nil
+	}
+
+	paragraphs := strings.Split(body, "\n\n")
+	last := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+
+	r := regexp.MustCompile(`^[A-Za-z0-9-]+: `)
+
+	for _, line := range last {
+		if !r.MatchString(line) {
+			return nil
+		}
+	}
+
+	return last
+}
+
+func authorString(name, email string) string {
+	return name + " <" + email + ">"
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// componentsT is the registry of the components subjects name after their tags, as
+// in "BUG/MEDIUM: mux-h2: ...": the known Values, or names matching Regex.
+type componentsT struct {
+	Values   []string `yaml:"Values"`
+	Regex    string   `yaml:"Regex"`
+	Required bool     `yaml:"Required"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrComponentsConfig = errors.New("invalid components rule")
+
+func (c componentsT) validate() error {
+	if _, err := regexp.Compile(c.Regex); err != nil {
+		return fmt.Errorf("components rule: %s: %w", err, ErrComponentsConfig)
+	}
+
+	if !validSeverity(c.Severity) {
+		return fmt.Errorf("components rule: unknown severity '%s': %w", c.Severity, ErrComponentsConfig)
+	}
+
+	return nil
+}
+
+func (c componentsT) severity() string {
+	if c.Severity == "" {
+		return severityError
+	}
+
+	return c.Severity
+}
+
+func (c componentsT) enabled() bool {
+	return len(c.Values) > 0 || c.Regex != "" || c.Required
+}
+
+var componentRegexp = regexp.MustCompile(`^([a-z0-9][a-z0-9_./-]*): `)
+
+// subjectComponent returns the component the text of a subject, past its tags, starts
+// with, if any.
+func subjectComponent(text string) (string, bool) {
+	m := componentRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+
+	return m[1], true
+}
+
+func (c componentsT) known(component string) bool {
+	if containsString(c.Values, component) {
+		return true
+	}
+
+	return c.Regex != "" && regexp.MustCompile(c.Regex).MatchString(component) // validated when loading
+}
+
+var ErrComponent = errors.New("invalid component")
+
+// Check checks the component of the text of a subject, past its tags.
+func (c componentsT) Check(text string) error {
+	if !c.enabled() {
+		return nil
+	}
+
+	component, ok := subjectComponent(text)
+
+	switch {
+	case !ok && c.Required:
+		return fmt.Errorf("no component after the tags, e.g. 'BUG/MINOR: config: ...': %w", ErrComponent)
+	case !ok || (len(c.Values) == 0 && c.Regex == "") || c.known(component):
+		return nil
+	}
+
+	message := fmt.Sprintf("unknown component '%s'", component)
+	if suggestion := didYouMean(component, c.Values); suggestion != "" {
+		message += suggestion
+	} else if len(c.Values) > 0 {
+		message += fmt.Sprintf(", expected one of %s", abbreviateList(c.Values))
+	}
+
+	return fmt.Errorf("%s: %w", strings.TrimSuffix(message, "?"), ErrComponent)
+}
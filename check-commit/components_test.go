@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestComponentsCheck(t *testing.T) {
+	t.Parallel()
+
+	registry := componentsT{Values: []string{"config", "mux-h2", "mux-quic"}, Regex: `^contrib/`}
+
+	tests := []struct {
+		name       string
+		components componentsT
+		subject    string
+		wantErr    bool
+	}{
+		{"disabled", componentsT{}, "BUG/MEDIUM: anything: fix it", false},
+		{"known", registry, "BUG/MEDIUM: mux-h2: fix the window update", false},
+		{"matching the regex", registry, "MINOR: contrib/prometheus: add a metric", false},
+		{"unknown", registry, "BUG/MEDIUM: mux-h3: fix the window update", true},
+		{"optional", registry, "BUG/MEDIUM: fix the window update", false},
+		{"required", componentsT{Required: true}, "BUG/MEDIUM: fix the window update", true},
+		{"required and free-form", componentsT{Required: true}, "BUG/MEDIUM: h2: fix the window update", false},
+	}
+
+	var c CommitPolicyConfig
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.components.Check(c.stripTagPrefixes(tt.subject)); (err != nil) != tt.wantErr {
+				t.Errorf("Check(%s) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+			}
+		})
+	}
+
+	err := registry.Check("mux-h3: fix the window update")
+	if !errors.Is(err, ErrComponent) || err.Error() != "unknown component 'mux-h3', did you mean 'mux-h2': invalid component" {
+		t.Errorf("Check() error = %v", err)
+	}
+}
+
+func TestSubjectComponent(t *testing.T) {
+	t.Parallel()
+
+	var c CommitPolicyConfig
+
+	for subject, want := range map[string]string{
+		"BUG/MEDIUM: mux-h2: fix the window update": "mux-h2",
+		"BUG/MINOR: MAJOR: h1/htx: fix parsing":     "h1/htx",
+		"MINOR: add a keyword":                      "",
+	} {
+		if got, _ := subjectComponent(c.stripTagPrefixes(subject)); got != want {
+			t.Errorf("subjectComponent(%s) = %s, want %s", subject, got, want)
+		}
+	}
+}
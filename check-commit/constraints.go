@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// tagConstraintsT restricts how tags combine in chained subjects such as
+// "BUG/MINOR: MAJOR: ...": the tags of an Exclusive group cannot appear together, and
+// the tags listed in Order must appear in that order.
+type tagConstraintsT struct {
+	Exclusive [][]string `yaml:"Exclusive"`
+	Order     []string   `yaml:"Order"`
+	Severity  string     `yaml:"Severity"`
+	Shadow    bool       `yaml:"Shadow"`
+}
+
+const minExclusiveGroup = 2
+
+var ErrTagConstraintsConfig = errors.New("invalid tag constraints")
+
+func (t tagConstraintsT) validate() error {
+	for _, group := range t.Exclusive {
+		if len(group) < minExclusiveGroup {
+			return fmt.Errorf("tag constraints: exclusive group [%s] needs at least two tags: %w",
+				strings.Join(group, ", "), ErrTagConstraintsConfig)
+		}
+	}
+
+	for i, tag := range t.Order {
+		if containsString(t.Order[:i], tag) {
+			return fmt.Errorf("tag constraints: tag %s listed twice in Order: %w", tag, ErrTagConstraintsConfig)
+		}
+	}
+
+	if !validSeverity(t.Severity) {
+		return fmt.Errorf("tag constraints: unknown severity '%s': %w", t.Severity, ErrTagConstraintsConfig)
+	}
+
+	return nil
+}
+
+func (t tagConstraintsT) severity() string {
+	if t.Severity == "" {
+		return severityError
+	}
+
+	return t.Severity
+}
+
+var ErrTagConstraint = errors.New("tag constraint violated")
+
+// Check reports the first constraint the tags of a subject violate, tags and
+// severities alike counting for the exclusive groups.
+func (t tagConstraintsT) Check(tags []subjectTagT) error {
+	for _, group := range t.Exclusive {
+		found := []string{}
+
+		for _, value := range group {
+			if hasAnyValue(tags, []string{value}) {
+				found = append(found, value)
+			}
+		}
+
+		if len(found) > 1 {
+			return fmt.Errorf("tags %s cannot be combined (exclusive group [%s]): %w",
+				strings.Join(found, " and "), strings.Join(group, ", "), ErrTagConstraint)
+		}
+	}
+
+	previous := ""
+	rank := -1
+
+	for _, tag := range tags {
+		index := -1
+
+		for i, name := range t.Order {
+			if name == tag.Tag {
+				index = i
+			}
+		}
+
+		if index < 0 {
+			continue
+		}
+
+		if index < rank {
+			return fmt.Errorf("tag %s must come before %s (order [%s]): %w",
+				tag.Tag, previous, strings.Join(t.Order, ", "), ErrTagConstraint)
+		}
+
+		previous, rank = tag.Tag, index
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTagConstraintsCheck(t *testing.T) {
+	t.Parallel()
+
+	constraints := tagConstraintsT{
+		Exclusive: [][]string{{"BUG", "CLEANUP"}, {"MINOR", "MAJOR"}},
+		Order:     []string{"BUG", "BUILD", "DOC"},
+	}
+
+	tests := []struct {
+		name    string
+		subject string
+		wantErr bool
+	}{
+		{"single tag", "BUG/MINOR: mux: fix a crash on close", false},
+		{"ordered chain", "BUG/MINOR: DOC: mux: fix a crash on close", false},
+		{"exclusive tags", "BUG/MINOR: CLEANUP: mux: fix a crash on close", true},
+		{"exclusive severity and tag", "BUG/MINOR: MAJOR: mux: fix a crash on close", true},
+		{"out of order", "DOC: BUILD: mux: document the build", true},
+		{"unordered tags ignored", "BUG/MINOR: TEST: DOC: mux: fix a crash on close", false},
+	}
+
+	var c CommitPolicyConfig
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := constraints.Check(c.subjectTags(tt.subject))
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrTagConstraint)) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagConstraintsValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, constraints := range []tagConstraintsT{
+		{Exclusive: [][]string{{"BUG"}}},
+		{Order: []string{"BUG", "DOC", "BUG"}},
+		{Severity: "fatal"},
+	} {
+		if err := constraints.validate(); !errors.Is(err, ErrTagConstraintsConfig) {
+			t.Errorf("validate(%+v) = %v", constraints, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+type timingT struct {
+	Phase   string  `json:"phase"`
+	Seconds float64 `json:"seconds"`
+}
+
+// stopwatchT measures the successive phases of a run.
+type stopwatchT struct {
+	last    time.Time
+	timings []timingT
+}
+
+func newStopwatch() *stopwatchT {
+	return &stopwatchT{last: time.Now()}
+}
+
+// lap records the time elapsed since the previous lap as the duration of the phase.
+func (s *stopwatchT) lap(phase string) {
+	now := time.Now()
+	s.timings = append(s.timings, timingT{phase, now.Sub(s.last).Seconds()})
+	s.last = now
+}
+
+const redacted = "[redacted]"
+
+// debugVariablePrefixes select the environment variables describing the CI context.
+var debugVariablePrefixes = []string{"GITHUB_", "CI_", "GITLAB_", "RUNNER_", "CHECK_COMMIT_"}
+
+func isSecretVariable(name string) bool {
+	for _, word := range []string{"TOKEN", "SECRET", "PASSWORD", "KEY"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// debugVariables returns the CI variables with the secret ones redacted, along with the
+// secret values to scrub from the rest of the bundle.
+func debugVariables(environ []string) (map[string]string, []string) {
+	variables := map[string]string{}
+	secrets := []string{}
+
+	for _, entry := range environ {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name, value := parts[0], parts[1]
+
+		if isSecretVariable(name) {
+			if value != "" {
+				secrets = append(secrets, value)
+			}
+
+			value = redacted
+		}
+
+		for _, prefix := range debugVariablePrefixes {
+			if strings.HasPrefix(name, prefix) {
+				variables[name] = value
+			}
+		}
+	}
+
+	return variables, secrets
+}
+
+type debugEnvironmentT struct {
+	GoVersion    string            `json:"go_version"`
+	Platform     string            `json:"platform"`
+	Environment  string            `json:"environment"`
+	Range        string            `json:"range,omitempty"`
+	Base         string            `json:"base,omitempty"`
+	Head         string            `json:"head,omitempty"`
+	SourceBranch string            `json:"source_branch,omitempty"`
+	Arguments    []string          `json:"arguments"`
+	Variables    map[string]string `json:"variables"`
+}
+
+type debugSubjectT struct {
+	SHA     string `json:"sha"`
+	Subject string `json:"subject"` // Go-quoted, so that invisible characters show
+}
+
+// debugFiles builds the content of the bundle, scrubbed of the secret values found in
+// the environment.
+func (c CommitPolicyConfig) debugFiles(gitEnv string, opts optionsT, report reportT) (map[string][]byte, error) {
+	variables, secrets := debugVariables(os.Environ())
+
+	environment := debugEnvironmentT{
+		GoVersion:    runtime.Version(),
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		Environment:  gitEnv,
+		Range:        opts.revRange,
+		Base:         requestBase(gitEnv, opts.revRange),
+		Head:         requestHeadSHA(gitEnv),
+		SourceBranch: getSourceBranch(gitEnv),
+		Arguments:    os.Args[1:],
+		Variables:    variables,
+	}
+
+	subjects := make([]debugSubjectT, 0, len(report.Commits))
+	for _, commit := range report.Commits {
+		subjects = append(subjects, debugSubjectT{commit.SHA, strconv.Quote(commit.Subject())})
+	}
+
+	files := map[string][]byte{}
+
+	policy, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding the policy: %w", err)
+	}
+
+	files["policy.yml"] = policy
+
+	for name, content := range map[string]interface{}{
+		"environment.json": environment,
+		"subjects.json":    subjects,
+		"report.json":      report.JSON(opts.shard),
+		"timings.json":     report.timings,
+	} {
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s: %w", name, err)
+		}
+
+		files[name] = data
+	}
+
+	for name, data := range files {
+		for _, secret := range secrets {
+			data = []byte(strings.ReplaceAll(string(data), secret, redacted))
+		}
+
+		files[name] = data
+	}
+
+	return files, nil
+}
+
+// writeDebugBundle writes a gzipped tarball describing the run, to attach to bug reports.
+func (c CommitPolicyConfig) writeDebugBundle(filename, gitEnv string, opts optionsT, report reportT) error {
+	files, err := c.debugFiles(gitEnv, opts, report)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error writing debug bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		header := &tar.Header{Name: "check-commit-debug/" + name, Mode: 0o644, Size: int64(len(files[name])),
+			ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing debug bundle: %w", err)
+		}
+
+		if _, err := tw.Write(files[name]); err != nil {
+			return fmt.Errorf("error writing debug bundle: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error writing debug bundle: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error writing debug bundle: %w", err)
+	}
+
+	return f.Close()
+}
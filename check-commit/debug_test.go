@@ -0,0 +1,92 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDebugVariables(t *testing.T) {
+	t.Parallel()
+
+	variables, secrets := debugVariables([]string{
+		"GITHUB_REPOSITORY=haproxy/haproxy",
+		"GITHUB_TOKEN=ghs_secret",
+		"CI_JOB_TOKEN=",
+		"API_TOKEN=glpat-secret",
+		"HOME=/root",
+	})
+
+	want := map[string]string{
+		"GITHUB_REPOSITORY": "haproxy/haproxy",
+		"GITHUB_TOKEN":      redacted,
+		"CI_JOB_TOKEN":      redacted,
+	}
+
+	if !reflect.DeepEqual(variables, want) {
+		t.Errorf("debugVariables() = %v, want %v", variables, want)
+	}
+
+	if !reflect.DeepEqual(secrets, []string{"ghs_secret", "glpat-secret"}) {
+		t.Errorf("debugVariables() secrets = %v", secrets)
+	}
+}
+
+func TestWriteDebugBundle(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := commitT{SHA: "1111111111111111111111111111111111111111", Message: "BUG/MINOR: mux:\u200b fix a crash"}
+	report := reportT{Commits: []commitT{commit}, timings: []timingT{{"checks", 0.5}}}
+	c.checkCommits(report.Commits, &report)
+
+	filename := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := c.writeDebugBundle(filename, LOCAL, optionsT{revRange: "HEAD~1..HEAD"}, report); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		names = append(names, header.Name)
+	}
+
+	want := []string{
+		"check-commit-debug/environment.json",
+		"check-commit-debug/policy.yml",
+		"check-commit-debug/report.json",
+		"check-commit-debug/subjects.json",
+		"check-commit-debug/timings.json",
+	}
+
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("writeDebugBundle() files = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// denylistT rejects the messages containing one of the words or phrases of the File of
+// the repository, one per line, such as profanity, before they reach a public branch.
+// Subjects are always checked, bodies with Body.
+type denylistT struct {
+	File     string `yaml:"File"`
+	Body     bool   `yaml:"Body"`
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+
+	words []string // of the File
+}
+
+var ErrDenylistConfig = errors.New("invalid denylist rule")
+
+func (d denylistT) validate() error {
+	if d.File != "" && !inRepository(d.File) {
+		return fmt.Errorf("denylist rule: '%s' is not in the repository: %w", d.File, ErrDenylistConfig)
+	}
+
+	if d.File == "" && d.Body {
+		return fmt.Errorf("denylist rule without File: %w", ErrDenylistConfig)
+	}
+
+	if !validSeverity(d.Severity) {
+		return fmt.Errorf("denylist rule: unknown severity '%s': %w", d.Severity, ErrDenylistConfig)
+	}
+
+	return nil
+}
+
+func (d denylistT) severity() string {
+	if d.Severity == "" {
+		return severityError
+	}
+
+	return d.Severity
+}
+
+// parseDenylist reads the words of a denylist file, ignoring its blank lines and #
+// comments.
+func parseDenylist(content string) []string {
+	words := []string{}
+
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+
+	return words
+}
+
+// resolveDenylist returns the policy with the File of the denylist read from the tree of
+// rev.
+func (c CommitPolicyConfig) resolveDenylist(repoPath, rev string) (CommitPolicyConfig, error) {
+	if c.Denylist.File == "" {
+		return c, nil
+	}
+
+	content, err := runGit(repoPath, "show", rev+":"+path.Clean(c.Denylist.File))
+	if err != nil {
+		return c, fmt.Errorf("error reading %s: %s: %w", c.Denylist.File, err, ErrDenylistConfig)
+	}
+
+	c.Denylist.words = parseDenylist(content)
+
+	return c, nil
+}
+
+// maskWord hides the word but its first letter, so that the findings do not spread it.
+func maskWord(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+
+	return string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+}
+
+var ErrDenylisted = errors.New("denylisted word in message")
+
+func (d denylistT) Check(commit commitT) error {
+	parts := []string{"subject", commit.Subject()}
+	if d.Body {
+		parts = append(parts, "body", commit.Body())
+	}
+
+	for i := 0; i < len(parts); i += 2 {
+		for _, word := range d.words {
+			if wordRegexp(word).MatchString(parts[i+1]) {
+				return fmt.Errorf("%s contains the denylisted '%s', please reword it: %w", parts[i], maskWord(word),
+					ErrDenylisted)
+			}
+		}
+	}
+
+	return nil
+}
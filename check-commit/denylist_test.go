@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestDenylist(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+
+	denylist := "# words not to publish\ndarn\nproject falcon\n"
+	if err := ioutil.WriteFile(filepath.Join(repo, "denylist.txt"), []byte(denylist), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "MINOR: denylist: add the denylist"}} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := parseCommitPolicy("Denylist:\n  File: denylist.txt\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = c.resolveDenylist(repo, "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+
+	subjectOnly := c.Denylist
+	withBody := c.Denylist
+	withBody.Body = true
+
+	tests := []struct {
+		name    string
+		rule    denylistT
+		message string
+		wantErr string
+	}{
+		{"clean", subjectOnly, "MINOR: mux: fix a crash on close", ""},
+		{"word", subjectOnly, "MINOR: mux: fix this Darn crash", "subject contains the denylisted 'd***', please reword it"},
+		{"phrase", subjectOnly, "MINOR: mux: prepare project  falcon", "subject contains the denylisted " +
+			"'p*************', please reword it"},
+		{"part of a word", subjectOnly, "MINOR: mux: fix the darning", ""},
+		{"body skipped", subjectOnly, "MINOR: mux: fix a crash\n\nThis darn crash.", ""},
+		{"body", withBody, "MINOR: mux: fix a crash\n\nThis darn crash.", "body contains the denylisted 'd***', please " +
+			"reword it"},
+		{"off", denylistT{}, "MINOR: mux: fix this darn crash", ""},
+	}
+
+	for _, tt := range tests {
+		err := tt.rule.Check(commitT{Message: tt.message})
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrDenylisted) || err.Error() != tt.wantErr+": "+ErrDenylisted.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	c.Denylist.File = "missing.txt"
+	if _, err := c.resolveDenylist(repo, "HEAD"); !errors.Is(err, ErrDenylistConfig) {
+		t.Errorf("resolveDenylist() of a missing file error = %v, want %v", err, ErrDenylistConfig)
+	}
+
+	for _, config := range []string{"Denylist:\n  File: /etc/words\n", "Denylist:\n  Body: true\n"} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrDenylistConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrDenylistConfig)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var ErrDeprecatedConfig = errors.New("invalid deprecated tags")
+
+var deprecatedTagRegexp = regexp.MustCompile(`^[^\s/]+(/[^\s/]+)?$`)
+
+// validateDeprecated checks the Deprecated tags, given as TAG or TAG/SEVERITY whatever
+// the TagFormat, and the date they become errors.
+func (c CommitPolicyConfig) validateDeprecated() error {
+	for tag := range c.Deprecated {
+		if !deprecatedTagRegexp.MatchString(tag) {
+			return fmt.Errorf("Deprecated: '%s' is not a tag: %w", tag, ErrDeprecatedConfig)
+		}
+	}
+
+	if c.DeprecatedErrorFrom == "" {
+		return nil
+	}
+
+	if _, err := time.Parse(dateLayout, c.DeprecatedErrorFrom); err != nil {
+		return fmt.Errorf("DeprecatedErrorFrom '%s' is not a YYYY-MM-DD date: %w", c.DeprecatedErrorFrom,
+			ErrDeprecatedConfig)
+	}
+
+	return nil
+}
+
+// deprecationSeverity returns the severity of the deprecated tags at the given time:
+// warnings until DeprecatedErrorFrom, errors from then on.
+func (c CommitPolicyConfig) deprecationSeverity(now time.Time) string {
+	from, err := time.Parse(dateLayout, c.DeprecatedErrorFrom)
+	if err != nil || now.Before(from) {
+		return severityWarning
+	}
+
+	return severityError
+}
+
+var ErrDeprecatedTag = errors.New("deprecated tag")
+
+// checkDeprecatedTags tells the author what to use instead of the deprecated tags of
+// a subject.
+func (c CommitPolicyConfig) checkDeprecatedTags(tags []subjectTagT, now time.Time) error {
+	deprecated := []string{}
+
+	for _, tag := range tags {
+		for _, name := range []string{tag.String(), tag.Tag} {
+			if _, ok := c.Deprecated[name]; ok && !containsString(deprecated, name) {
+				deprecated = append(deprecated, name)
+			}
+		}
+	}
+
+	if len(deprecated) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(deprecated))
+
+	for _, name := range deprecated {
+		message := fmt.Sprintf("tag %s is deprecated", name)
+		if advice := c.Deprecated[name]; advice != "" {
+			message += ", " + advice
+		}
+
+		messages = append(messages, message)
+	}
+
+	message := strings.Join(messages, "; ")
+	if c.deprecationSeverity(now) == severityWarning && c.DeprecatedErrorFrom != "" {
+		message += fmt.Sprintf(" (rejected from %s)", c.DeprecatedErrorFrom)
+	}
+
+	return fmt.Errorf("%s: %w", message, ErrDeprecatedTag)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckDeprecatedTags(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + `
+Deprecated:
+  OPTIM: "use 'MINOR: perf: ' instead"
+  BUG/CRITICAL: "use BUG/MAJOR and notify the security team"
+  LICENSE: ""
+DeprecatedErrorFrom: 2027-01-01
+`)
+	if err != nil {
+		t.Fatalf("parseCommitPolicy() error = %v", err)
+	}
+
+	before := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		subject string
+		now     time.Time
+		want    string
+	}{
+		{"BUG/MINOR: config: fix the parsing", before, ""},
+		{"OPTIM: pattern: speed up the lookups", before,
+			"tag OPTIM is deprecated, use 'MINOR: perf: ' instead (rejected from 2027-01-01): deprecated tag"},
+		{"OPTIM: pattern: speed up the lookups", after, "tag OPTIM is deprecated, use 'MINOR: perf: ' instead: deprecated tag"},
+		{"BUG/CRITICAL: mux-h2: fix a crash", after,
+			"tag BUG/CRITICAL is deprecated, use BUG/MAJOR and notify the security team: deprecated tag"},
+		{"LICENSE: OPTIM: update the headers", after,
+			"tag LICENSE is deprecated; tag OPTIM is deprecated, use 'MINOR: perf: ' instead: deprecated tag"},
+	}
+
+	for _, tt := range tests {
+		err := c.checkDeprecatedTags(c.subjectTags(tt.subject), tt.now)
+
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+
+		if got != tt.want || err != nil && !errors.Is(err, ErrDeprecatedTag) {
+			t.Errorf("checkDeprecatedTags(%s) error = %v, want %q", tt.subject, err, tt.want)
+		}
+	}
+
+	if c.deprecationSeverity(before) != severityWarning || c.deprecationSeverity(after) != severityError {
+		t.Errorf("deprecationSeverity() = %s, %s, want a warning then an error", c.deprecationSeverity(before),
+			c.deprecationSeverity(after))
+	}
+
+	if (CommitPolicyConfig{}).deprecationSeverity(after) != severityWarning {
+		t.Errorf("deprecationSeverity() without date is not a warning")
+	}
+}
+
+func TestValidateDeprecated(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{"Deprecated:\n  OPTIM/: x\n", "Deprecated:\n  OPTIM: x\nDeprecatedErrorFrom: soon\n"} {
+		if _, err := parseCommitPolicy(defaultConf + config); !errors.Is(err, ErrDeprecatedConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrDeprecatedConfig)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrDescriptions = errors.New("invalid descriptions")
+
+// validateDescriptions checks that the Descriptions are those of tags or severities of
+// the policy.
+func (c CommitPolicyConfig) validateDescriptions() error {
+	known := c.knownValues()
+	values := make([]string, 0, len(known))
+
+	for value := range known {
+		values = append(values, value)
+	}
+
+	sort.Strings(values)
+
+	for value, description := range c.Descriptions {
+		if !known[value] {
+			return fmt.Errorf("Descriptions: '%s' is not a tag or severity%s: %w", value, didYouMean(value, values),
+				ErrDescriptions)
+		}
+
+		if strings.TrimSpace(description) == "" {
+			return fmt.Errorf("Descriptions: empty description of '%s': %w", value, ErrDescriptions)
+		}
+	}
+
+	return nil
+}
+
+// describedValues lists the values with their description, if any.
+func (c CommitPolicyConfig) describedValues(values []string) string {
+	described := make([]string, len(values))
+
+	for i, value := range values {
+		described[i] = value
+		if description := c.Descriptions[value]; description != "" {
+			described[i] += " (" + description + ")"
+		}
+	}
+
+	return abbreviateList(described)
+}
+
+// alternativeTags returns the tags the patch types accept, in order.
+func (c CommitPolicyConfig) alternativeTags(patchTypes []string) []string {
+	tags := []string{}
+
+	for _, name := range patchTypes {
+		for _, tag := range c.PatchTypes[name].Values {
+			if !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// tagSeverities returns the severities the patch types accepting the tag allow it.
+func (c CommitPolicyConfig) tagSeverities(tag string, patchTypes []string) []string {
+	severities := []string{}
+
+	for _, name := range patchTypes {
+		if !containsString(c.PatchTypes[name].Values, tag) {
+			continue
+		}
+
+		for _, severity := range c.PatchScopes[c.PatchTypes[name].Scope] {
+			if !containsString(severities, severity) {
+				severities = append(severities, severity)
+			}
+		}
+	}
+
+	return severities
+}
+
+// tagError explains why the patch types of a TagOrder alternative reject the tag and
+// severity, an empty tag meaning that the subject has none, listing the accepted
+// values with their description.
+func (c CommitPolicyConfig) tagError(tag, severity string, patchTypes []string) error {
+	tags := c.alternativeTags(patchTypes)
+	searched := strings.Join(patchTypes, ", ")
+
+	if tag == "" {
+		return fmt.Errorf("no tag found, expected one of %s (searched through [%s]): %w", c.describedValues(tags),
+			searched, ErrTagScope)
+	}
+
+	if !containsString(tags, tag) {
+		message := fmt.Sprintf("invalid tag '%s'", tag)
+		if suggestion := didYouMean(tag, tags); suggestion != "" {
+			message += strings.TrimSuffix(suggestion, "?")
+		}
+
+		return fmt.Errorf("%s, expected one of %s (searched through [%s]): %w", message, c.describedValues(tags),
+			searched, ErrTagScope)
+	}
+
+	severities := c.tagSeverities(tag, patchTypes)
+
+	switch {
+	case severity == "" && c.requiresScope(tag, patchTypes):
+		return fmt.Errorf("tag '%s' requires a severity, one of %s: %w", tag, c.describedValues(severities),
+			ErrTagScope)
+	case len(severities) == 0:
+		return fmt.Errorf("tag '%s' takes no severity, '%s' is not allowed: %w", tag, severity, ErrTagScope)
+	}
+
+	return fmt.Errorf("invalid severity '%s' of tag '%s'%s, expected one of %s: %w", severity, tag,
+		strings.TrimSuffix(didYouMean(severity, severities), "?"), c.describedValues(severities), ErrTagScope)
+}
+
+// repeatedTagError reports the tag or severity of a prefix that repeats one of the tags
+// already read, as in 'BUG/MINOR: BUG: ', else returns nil.
+func repeatedTagError(extra subjectTagT, consumed []subjectTagT) error {
+	for _, tag := range consumed {
+		for _, value := range []string{tag.Tag, tag.Severity} {
+			if value != "" && (value == extra.Tag || value == extra.Severity) {
+				return fmt.Errorf("tag '%s' is repeated, please write it once: %w", value, ErrTagScope)
+			}
+		}
+	}
+
+	return nil
+}
+
+// acceptsTag tells whether one of the patch types accepts the tag and its severity.
+func (c CommitPolicyConfig) acceptsTag(tag subjectTagT) bool {
+	for _, patchType := range c.PatchTypes {
+		if containsString(patchType.Values, tag.Tag) && patchType.Scope != "" &&
+			containsString(c.PatchScopes[patchType.Scope], tag.Severity) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stackedTagError reports the prefix left past the tags of the TagOrder when it repeats
+// them or stacks another known tag, as in 'BUG: MINOR: ' or 'MINOR: BUG: ' written for
+// 'BUG/MINOR: ', else returns nil.
+func (c CommitPolicyConfig) stackedTagError(extra subjectTagT, consumed []subjectTagT) error {
+	extra = subjectTagT{Tag: c.canonicalValue(extra.Tag), Severity: c.canonicalValue(extra.Severity)}
+	if err := repeatedTagError(extra, consumed); err != nil {
+		return err
+	}
+
+	if len(consumed) == 0 || !c.knownValues()[extra.Tag] {
+		return nil
+	}
+
+	last := consumed[len(consumed)-1]
+
+	if c.TagFormat == "" && last.Severity == "" && extra.Severity == "" {
+		for _, merged := range []subjectTagT{
+			{Tag: last.Tag, Severity: extra.Tag}, {Tag: extra.Tag, Severity: last.Tag},
+		} {
+			if c.acceptsTag(merged) {
+				return fmt.Errorf("tags '%s' and '%s' are stacked, please write them as '%s': %w", last, extra, merged,
+					ErrTagScope)
+			}
+		}
+	}
+
+	return fmt.Errorf("tag '%s' is stacked after '%s', expected a single tag prefix: %w", extra, last, ErrTagScope)
+}
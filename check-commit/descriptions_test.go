@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+const descriptionsConf = defaultConf + `
+Descriptions:
+  BUG: fixes a bug
+  DOC: documentation only
+  MINOR: minor impact
+  CRITICAL: needs an immediate release
+`
+
+func TestTagError(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(descriptionsConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{
+			"fix the parsing of timeouts",
+			"no tag found, expected one of BUG (fixes a bug), BUILD, CLEANUP, DOC (documentation only), LICENSE, " +
+				"OPTIM, RELEASE, REORG, TEST, REVERT and 4 more (searched through [HAProxy Standard Patch, " +
+				"HAProxy Standard Feature Commit]): invalid tag and or severity",
+		},
+		{
+			"BUGG/MINOR: fix the parsing of timeouts",
+			"invalid tag 'BUGG', did you mean 'BUG', expected one of BUG (fixes a bug), BUILD, CLEANUP, " +
+				"DOC (documentation only), LICENSE, OPTIM, RELEASE, REORG, TEST, REVERT and 4 more (searched through " +
+				"[HAProxy Standard Patch, HAProxy Standard Feature Commit]): invalid tag and or severity",
+		},
+		{
+			"BUG/MINIMAL: fix the parsing of timeouts",
+			"invalid severity 'MINIMAL' of tag 'BUG', expected one of MINOR (minor impact), MEDIUM, MAJOR, " +
+				"CRITICAL (needs an immediate release): invalid tag and or severity",
+		},
+		{
+			"BUG/MEDUIM: fix the parsing of timeouts",
+			"invalid severity 'MEDUIM' of tag 'BUG', did you mean 'MEDIUM', expected one of MINOR (minor impact), " +
+				"MEDIUM, MAJOR, CRITICAL (needs an immediate release): invalid tag and or severity",
+		},
+		{
+			"MEDIUM/MINOR: config: add an option",
+			"tag 'MEDIUM' takes no severity, 'MINOR' is not allowed: invalid tag and or severity",
+		},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckSubject([]byte(tt.subject)); err == nil || err.Error() != tt.want {
+			t.Errorf("CheckSubject(%s) error = %v, want %s", tt.subject, err, tt.want)
+		}
+	}
+
+	required, err := parseCommitPolicy(`
+PatchScopes:
+  severities: [MINOR, MAJOR]
+PatchTypes:
+  fixes:
+    Values: [BUG]
+    Scope: severities
+    ScopeRequired: true
+TagOrder:
+  - PatchTypes: [fixes]
+Descriptions:
+  MAJOR: breaks a feature
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "tag 'BUG' requires a severity, one of MINOR, MAJOR (breaks a feature): invalid tag and or severity"
+	if err := required.CheckSubject([]byte("BUG: fix the parsing of timeouts")); err == nil || err.Error() != want {
+		t.Errorf("CheckSubject() error = %v, want %s", err, want)
+	}
+}
+
+func TestValidateDescriptions(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{"Descriptions:\n  BUGS: fixes\n", "Descriptions:\n  BUG: ' '\n"} {
+		if _, err := parseCommitPolicy(defaultConf + config); !errors.Is(err, ErrDescriptions) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrDescriptions)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	fileAdded    = "added"
+	fileRemoved  = "removed"
+	fileModified = "modified"
+	fileRenamed  = "renamed"
+)
+
+type fileDiffT struct {
+	Path    string
+	OldPath string
+	Status  string
+	Added   []string
+	Removed []string
+}
+
+// unquotePath decodes a path git C-quoted because of special characters, such as
+// quotes, control or non-ASCII characters.
+func unquotePath(path string) string {
+	if len(path) < 2 || path[0] != '"' || path[len(path)-1] != '"' {
+		return path
+	}
+
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+
+	return path
+}
+
+// cutQuotedPath splits the quoted path starting s from the rest.
+func cutQuotedPath(s string) (string, string) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1], s[i+1:]
+		}
+	}
+
+	return s, ""
+}
+
+// parseDiffHeader returns the paths of a diff --git line. Unquoted paths containing
+// spaces are ambiguous unless both are the same, the --- and +++ lines that follow
+// telling them apart otherwise.
+func parseDiffHeader(paths string) (string, string) {
+	var oldPath, path string
+
+	half := len(paths) / 2
+
+	switch i := strings.Index(paths, ` "`); {
+	case strings.HasPrefix(paths, `"`):
+		oldPath, path = cutQuotedPath(paths)
+	case i >= 0:
+		oldPath, path = paths[:i], paths[i:]
+	case len(paths) > 4 && len(paths)%2 == 1 && paths[half] == ' ' && paths[2:half] == paths[half+3:]:
+		oldPath, path = paths[:half], paths[half:]
+	default:
+		parts := strings.SplitN(paths, " b/", 2)
+		if len(parts) != 2 {
+			return "", ""
+		}
+
+		oldPath, path = parts[0], "b/"+parts[1]
+	}
+
+	return strings.TrimPrefix(unquotePath(oldPath), "a/"),
+		strings.TrimPrefix(unquotePath(strings.TrimPrefix(path, " ")), "b/")
+}
+
+// parseFilePath returns the path of a --- or +++ line without its a/ or b/ prefix, or
+// "" for /dev/null. git ends the paths containing spaces with a tab.
+func parseFilePath(line, prefix string) string {
+	path := strings.TrimSuffix(line[len("--- "):], "\t")
+	if path == "/dev/null" {
+		return ""
+	}
+
+	return strings.TrimPrefix(unquotePath(path), prefix)
+}
+
+// parseUnifiedDiff parses the output of git diff-tree -p, only keeping what the
+// heuristics need: file names, status and changed lines.
+func parseUnifiedDiff(out string) []fileDiffT {
+	files := []fileDiffT{}
+
+	var current *fileDiffT
+
+	inHunk := false
+
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, fileDiffT{Status: fileModified})
+			current = &files[len(files)-1]
+			inHunk = false
+			current.OldPath, current.Path = parseDiffHeader(strings.TrimPrefix(line, "diff --git "))
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			current.Added = append(current.Added, line[1:])
+		case inHunk && strings.HasPrefix(line, "-"):
+			current.Removed = append(current.Removed, line[1:])
+		case inHunk:
+			continue
+		case strings.HasPrefix(line, "--- ") && parseFilePath(line, "a/") != "":
+			current.OldPath = parseFilePath(line, "a/")
+		case strings.HasPrefix(line, "+++ ") && parseFilePath(line, "b/") != "":
+			current.Path = parseFilePath(line, "b/")
+		case strings.HasPrefix(line, "new file mode"):
+			current.Status = fileAdded
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.Status = fileRemoved
+		case strings.HasPrefix(line, "rename from "):
+			current.Status = fileRenamed
+			current.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			current.Path = unquotePath(strings.TrimPrefix(line, "rename to "))
+		}
+	}
+
+	return files
+}
+
+func gitCommitDiff(repoPath, sha string) ([]fileDiffT, error) {
+	out, err := runGit(repoPath, "diff-tree", "-p", "-r", "-M", "--root", "--no-commit-id",
+		"--unified=0", "--no-color", "--no-ext-diff", sha)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseUnifiedDiff(out), nil
+}
+
+func isObjectID(line string) bool {
+	if len(line) != 40 && len(line) != 64 { // SHA-1 or SHA-256
+		return false
+	}
+
+	for _, r := range line {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitDiffBlocks splits the output of git diff-tree --stdin into the diffs of each
+// commit, which are introduced by the bare object id of the commit: no line of a diff
+// can be mistaken for one since they all start with a prefix.
+func splitDiffBlocks(out string, count int) ([]string, error) {
+	blocks := []string{}
+
+	var block strings.Builder
+
+	for _, line := range strings.SplitAfter(out, "\n") {
+		if !isObjectID(strings.TrimSuffix(line, "\n")) {
+			block.WriteString(line)
+
+			continue
+		}
+
+		if len(blocks) > 0 {
+			blocks[len(blocks)-1] = block.String()
+		}
+
+		blocks = append(blocks, "")
+		block.Reset()
+	}
+
+	if len(blocks) > 0 {
+		blocks[len(blocks)-1] = block.String()
+	}
+
+	if len(blocks) != count {
+		return nil, fmt.Errorf("git diff-tree: %d diffs for %d commits: %w", len(blocks), count, ErrGitCommand)
+	}
+
+	return blocks, nil
+}
+
+// gitCommitDiffs reads the diffs of several commits with a single git invocation,
+// returning them in the order of the commits.
+func gitCommitDiffs(repoPath string, shas []string) ([][]fileDiffT, error) {
+	out, err := runGitInput(repoPath, strings.Join(shas, "\n")+"\n", "diff-tree", "--stdin", "--always",
+		"-p", "-r", "-M", "--root", "--unified=0", "--no-color", "--no-ext-diff")
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := splitDiffBlocks(out, len(shas))
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([][]fileDiffT, 0, len(blocks))
+	for _, block := range blocks {
+		diffs = append(diffs, parseUnifiedDiff(block))
+	}
+
+	return diffs, nil
+}
+
+// parseNameStatus parses the output of git diff-tree --name-status --no-renames, whose
+// paths are C-quoted when they contain tabs or other special characters.
+func parseNameStatus(out string) []fileDiffT {
+	statuses := map[string]string{"A": fileAdded, "D": fileRemoved}
+	files := []fileDiffT{}
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		status, ok := statuses[fields[0]]
+		if !ok {
+			status = fileModified
+		}
+
+		path := unquotePath(fields[1])
+		files = append(files, fileDiffT{Path: path, OldPath: path, Status: status})
+	}
+
+	return files
+}
+
+// gitCommitFileNames reads the files changed by several commits without their
+// content, which only needs the trees and thus works in partial clones lacking blobs.
+func gitCommitFileNames(repoPath string, shas []string) ([][]fileDiffT, error) {
+	out, err := runGitInput(repoPath, strings.Join(shas, "\n")+"\n", "diff-tree", "--stdin", "--always",
+		"-r", "--name-status", "--no-renames", "--root")
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := splitDiffBlocks(out, len(shas))
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([][]fileDiffT, 0, len(blocks))
+	for _, block := range blocks {
+		diffs = append(diffs, parseNameStatus(block))
+	}
+
+	return diffs, nil
+}
+
+// gitDiffBlobs lists the blobs the diffs of the commits compare, as found in the
+// trees, without reading them.
+func gitDiffBlobs(repoPath string, shas []string) ([]string, error) {
+	out, err := runGitInput(repoPath, strings.Join(shas, "\n")+"\n", "diff-tree", "--stdin", "-r", "--raw",
+		"--no-renames", "--root", "--no-abbrev")
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := []string{}
+	seen := map[string]bool{}
+
+	for _, line := range strings.Split(out, "\n") {
+		// :<old mode> <new mode> <old oid> <new oid> <status>\t<path>
+		fields := strings.Fields(strings.SplitN(line, "\t", 2)[0])
+		if len(fields) != 5 || !strings.HasPrefix(fields[0], ":") {
+			continue
+		}
+
+		for i, oid := range fields[2:4] {
+			mode := strings.TrimPrefix(fields[i], ":")
+			if mode == "160000" || strings.Trim(oid, "0") == "" || seen[oid] { // submodules and absent sides
+				continue
+			}
+
+			seen[oid] = true
+			blobs = append(blobs, oid)
+		}
+	}
+
+	return blobs, nil
+}
+
+// prefetchDiffBlobs fetches all the blobs the diffs of the commits need in one go when
+// the clone is partial, instead of letting git fetch them one by one.
+func prefetchDiffBlobs(repoPath string, shas []string) error {
+	remote := gitPromisorRemote(repoPath)
+	if remote == "" {
+		return nil
+	}
+
+	blobs, err := gitDiffBlobs(repoPath, shas)
+	if err != nil || len(blobs) == 0 {
+		return err
+	}
+
+	log.Printf("partial clone: fetching the %d blobs of the diffs from %s", len(blobs), remote)
+
+	return gitFetchObjects(repoPath, remote, blobs)
+}
+
+// loadDiffs fetches the diff of the commits that a rule needs it for, all at once
+// unless some commit is missing from the clone.
+func (c CommitPolicyConfig) loadDiffs(repoPath string, commits []commitT) {
+	needed := []int{}
+	shas := []string{}
+
+	for i := range commits {
+		if !commits[i].HasDiff && c.needsDiff(commits[i]) {
+			needed = append(needed, i)
+			shas = append(shas, commits[i].SHA)
+		}
+	}
+
+	if len(needed) == 0 {
+		return
+	}
+
+	if err := prefetchDiffBlobs(repoPath, shas); err != nil {
+		log.Printf("warning: partial clone: unable to fetch the content of the diffs, only checking the changed "+
+			"file names: %s", err)
+		loadFileNames(repoPath, commits, needed, shas)
+
+		return
+	}
+
+	if diffs, err := gitCommitDiffs(repoPath, shas); err == nil {
+		for j, i := range needed {
+			commits[i].Files, commits[i].HasDiff = diffs[j], true
+		}
+
+		return
+	}
+
+	for _, i := range needed {
+		files, err := gitCommitDiff(repoPath, commits[i].SHA)
+		if err != nil {
+			log.Printf("warning: skipping diff checks of commit %s: %s", shortSHA(commits[i].SHA), err)
+
+			continue
+		}
+
+		commits[i].Files, commits[i].HasDiff = files, true
+	}
+}
+
+// loadFileNames is loadDiffs for partial clones whose blobs cannot be fetched: the
+// rules relying on the changed lines skip the commits.
+func loadFileNames(repoPath string, commits []commitT, needed []int, shas []string) {
+	diffs, err := gitCommitFileNames(repoPath, shas)
+	if err != nil {
+		log.Printf("warning: skipping diff checks: %s", err)
+
+		return
+	}
+
+	for j, i := range needed {
+		commits[i].Files, commits[i].HasDiff, commits[i].NamesOnly = diffs[j], true, true
+	}
+}
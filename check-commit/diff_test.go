@@ -0,0 +1,190 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	out := `diff --git a/src/old.c b/src/new.c
+similarity index 90%
+rename from src/old.c
+rename to src/new.c
+index 1111111..2222222 100644
+--- a/src/old.c
++++ b/src/new.c
+@@ -1 +1 @@
+-#include <old.h>
++#include <new.h>
+diff --git a/doc/intro.txt b/doc/intro.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/doc/intro.txt
+@@ -0,0 +1,2 @@
++first line
++--- not a header
+diff --git "a/doc/caf\303\251 \"notes\".txt" "b/doc/caf\303\251 \"notes\".txt"
+new file mode 100644
+index 0000000..4444444
+--- /dev/null
++++ "b/doc/caf\303\251 \"notes\".txt"
+@@ -0,0 +1 @@
++café
+diff --git a/my notes.txt b/your notes.txt
+similarity index 100%
+rename from my notes.txt
+rename to your notes.txt
+diff --git a/old b/notes.txt b/old b/notes.txt
+deleted file mode 100644
+index 5555555..0000000
+--- a/old b/notes.txt` + "\t" + `
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+`
+
+	want := []fileDiffT{
+		{Path: "src/new.c", OldPath: "src/old.c", Status: fileRenamed, Added: []string{"#include <new.h>"}, Removed: []string{"#include <old.h>"}},
+		{Path: "doc/intro.txt", OldPath: "doc/intro.txt", Status: fileAdded, Added: []string{"first line", "--- not a header"}},
+		{Path: `doc/café "notes".txt`, OldPath: `doc/café "notes".txt`, Status: fileAdded, Added: []string{"café"}},
+		{Path: "your notes.txt", OldPath: "my notes.txt", Status: fileRenamed},
+		{Path: "old b/notes.txt", OldPath: "old b/notes.txt", Status: fileRemoved, Removed: []string{"gone"}},
+	}
+
+	if got := parseUnifiedDiff(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseUnifiedDiff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	t.Parallel()
+
+	got := parseNameStatus("A\tdoc/my notes.txt\nD\t\"doc/tab\\there.txt\"\nM\tsrc/main.c\n")
+	want := []fileDiffT{
+		{Path: "doc/my notes.txt", OldPath: "doc/my notes.txt", Status: fileAdded},
+		{Path: "doc/tab\there.txt", OldPath: "doc/tab\there.txt", Status: fileRemoved},
+		{Path: "src/main.c", OldPath: "src/main.c", Status: fileModified},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNameStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGitCommitDiff(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+
+	content := "int main(void)\n{\n\treturn 0;\n}\n"
+	if err := ioutil.WriteFile(filepath.Join(repo, "main.c"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"add", "main.c"},
+		{"commit", "-q", "-m", "MINOR: src: add main"},
+		{"mv", "main.c", "haproxy.c"},
+		{"commit", "-q", "-m", "REORG: src: rename main"},
+	} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := gitCommitDiff(repo, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []fileDiffT{{Path: "haproxy.c", OldPath: "main.c", Status: fileRenamed}}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("gitCommitDiff() = %+v, want %+v", files, want)
+	}
+
+	if _, err := runGit(repo, "commit", "-q", "--allow-empty", "-m", "MINOR: src: nothing"); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := gitLogCommits(repo, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shas := []string{commits[0].SHA, commits[1].SHA, commits[2].SHA}
+
+	diffs, err := gitCommitDiffs(repo, shas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diffs) != 3 || len(diffs[0]) != 0 || !reflect.DeepEqual(diffs[1], want) || diffs[2][0].Status != fileAdded {
+		t.Errorf("gitCommitDiffs() = %+v", diffs)
+	}
+
+	if _, err := gitCommitDiffs(repo, append(shas, "2222222222222222222222222222222222222222")); err == nil {
+		t.Errorf("gitCommitDiffs() with a missing commit succeeded")
+	}
+}
+
+func TestLoadDiffsPartialClone(t *testing.T) {
+	t.Parallel()
+
+	origin := newTestRepo(t)
+
+	if err := ioutil.WriteFile(filepath.Join(origin, "main.c"), []byte("int main(void);\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"config", "uploadpack.allowFilter", "true"},
+		{"config", "uploadpack.allowAnySHA1InWant", "true"},
+		{"add", "main.c"},
+		{"commit", "-q", "-m", "MINOR: src: add main"},
+	} {
+		if _, err := runGit(origin, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policy := CommitPolicyConfig{CommitSize: commitSizeT{MaxLines: 100}}
+
+	for _, reachable := range []bool{true, false} {
+		clone := filepath.Join(t.TempDir(), "clone")
+		if _, err := runGit(".", "clone", "-q", "--no-checkout", "--filter=blob:none", "file://"+origin, clone); err != nil {
+			t.Fatal(err)
+		}
+
+		if remote := gitPromisorRemote(clone); remote != "origin" {
+			t.Fatalf("gitPromisorRemote() = '%s', want origin", remote)
+		}
+
+		if !reachable {
+			if _, err := runGit(clone, "remote", "set-url", "origin", "file://"+filepath.Join(origin, "missing")); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		commits, err := gitLogCommits(clone, "HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		policy.loadDiffs(clone, commits)
+
+		want := fileDiffT{Path: "main.c", OldPath: "main.c", Status: fileAdded}
+		if reachable {
+			want = fileDiffT{Path: "main.c", OldPath: "main.c", Status: fileAdded, Added: []string{"int main(void);"}}
+		}
+
+		if !commits[0].HasDiff || commits[0].NamesOnly == reachable || len(commits[0].Files) != 1 ||
+			!reflect.DeepEqual(commits[0].Files[0], want) {
+			t.Errorf("loadDiffs() with reachable remote %v = %+v", reachable, commits[0])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type documentationT struct {
+	Paths    []string `yaml:"Paths"`
+	Tags     []string `yaml:"Tags"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrDocumentationConfig = errors.New("invalid documentation rule")
+
+func (d documentationT) validate() error {
+	if !validSeverity(d.Severity) {
+		return fmt.Errorf("documentation rule: unknown severity '%s': %w", d.Severity, ErrDocumentationConfig)
+	}
+
+	return nil
+}
+
+func (d documentationT) enabled() bool {
+	return len(d.Paths) > 0
+}
+
+func (d documentationT) tags() []string {
+	if len(d.Tags) == 0 {
+		return []string{"DOC"}
+	}
+
+	return d.Tags
+}
+
+func (d documentationT) severity() string {
+	if d.Severity == "" {
+		return severityError
+	}
+
+	return d.Severity
+}
+
+var ErrDocumentationTag = errors.New("documentation tag does not match the changed files")
+
+// Check enforces both directions: documentation commits only touch documentation
+// paths, and commits only touching documentation paths are documentation commits.
+func (d documentationT) Check(commit commitT) error {
+	if !d.enabled() || !commit.HasDiff || len(commit.Files) == 0 {
+		return nil
+	}
+
+	others := []string{}
+
+	for _, file := range commit.Files {
+		names := []string{file.Path}
+		if file.Status == fileRenamed {
+			names = append(names, file.OldPath)
+		}
+
+		for _, name := range names {
+			if !matchAnyGlob(d.Paths, name) {
+				others = append(others, name)
+			}
+		}
+	}
+
+	tagged := hasAnyValue(commit.Tags(), d.tags())
+
+	switch {
+	case tagged && len(others) > 0:
+		return fmt.Errorf("documentation commits may only touch documentation files, this one also modifies [%s]: %w",
+			strings.Join(others, ", "), ErrDocumentationTag)
+	case !tagged && len(others) == 0:
+		return fmt.Errorf("commit only touches documentation files, please use one of the [%s] tags: %w",
+			strings.Join(d.tags(), ", "), ErrDocumentationTag)
+	}
+
+	return nil
+}
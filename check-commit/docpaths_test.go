@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestDocumentationCheck(t *testing.T) {
+	t.Parallel()
+
+	d := documentationT{Paths: []string{"doc/**", "*.md"}}
+
+	files := func(names ...string) []fileDiffT {
+		result := []fileDiffT{}
+		for _, name := range names {
+			result = append(result, fileDiffT{Path: name, OldPath: name, Status: fileModified})
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		commit  commitT
+		wantErr bool
+	}{
+		{
+			name:    "doc commit touching docs",
+			commit:  commitT{Message: "DOC: config: clarify timeouts", Files: files("doc/configuration.txt", "README.md"), HasDiff: true},
+			wantErr: false,
+		},
+		{
+			name:    "doc commit touching code",
+			commit:  commitT{Message: "DOC: config: clarify timeouts", Files: files("doc/configuration.txt", "src/cfgparse.c"), HasDiff: true},
+			wantErr: true,
+		},
+		{
+			name:    "code commit touching only docs",
+			commit:  commitT{Message: "MINOR: config: clarify timeouts", Files: files("doc/configuration.txt"), HasDiff: true},
+			wantErr: true,
+		},
+		{
+			name:    "code commit touching code and docs",
+			commit:  commitT{Message: "MINOR: config: add keyword", Files: files("doc/configuration.txt", "src/cfgparse.c"), HasDiff: true},
+			wantErr: false,
+		},
+		{
+			name:    "doc commit moving docs out of doc directory",
+			commit:  commitT{Message: "DOC: move intro", Files: []fileDiffT{{Path: "intro.txt", OldPath: "doc/intro.txt", Status: fileRenamed}}, HasDiff: true},
+			wantErr: true,
+		},
+		{
+			name:    "diff not loaded",
+			commit:  commitT{Message: "MINOR: config: clarify timeouts"},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := d.Check(tt.commit); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v35/github"
+)
+
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "FAIL"
+
+	minGitMajor = 2
+)
+
+type diagnosisT struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string // remediation, for warnings and failures
+}
+
+type doctorT struct {
+	opts    optionsT
+	repoEnv string
+	results []diagnosisT
+}
+
+func (d *doctorT) add(name, status, detail, hint string) {
+	d.results = append(d.results, diagnosisT{name, status, detail, hint})
+}
+
+func (d *doctorT) checkGit() bool {
+	out, err := runGit(".", "--version")
+	if err != nil {
+		d.add("git", doctorFail, err.Error(), "install git in the image running check-commit and put it in the PATH")
+
+		return false
+	}
+
+	version := strings.TrimSpace(out)
+
+	major := 0
+	if m := regexp.MustCompile(`git version (\d+)\.`).FindStringSubmatch(version); m != nil {
+		major, _ = strconv.Atoi(m[1])
+	}
+
+	if major < minGitMajor {
+		d.add("git", doctorWarn, version, fmt.Sprintf("git %d.0 or later is needed, upgrade git", minGitMajor))
+	} else {
+		d.add("git", doctorOK, version, "")
+	}
+
+	return true
+}
+
+func (d *doctorT) checkRepository() bool {
+	if _, err := runGit(d.opts.repoPath, "rev-parse", "--git-dir"); err != nil {
+		d.add("repository", doctorFail, err.Error(),
+			"check out the repository first (actions/checkout) or pass its path as argument")
+
+		return false
+	}
+
+	d.add("repository", doctorOK, d.opts.repoPath+" ("+gitRepositoryKind(d.opts.repoPath)+")", "")
+
+	if out, err := runGit(d.opts.repoPath, "rev-parse", "--is-shallow-repository"); err == nil &&
+		strings.TrimSpace(out) == "true" {
+		d.add("clone depth", doctorWarn, "shallow clone",
+			"diff checks, push ranges and the base policy need the history, check out with fetch-depth: 0")
+	} else {
+		d.add("clone depth", doctorOK, "full history", "")
+	}
+
+	return true
+}
+
+func (d *doctorT) checkEnvironment() {
+	if d.opts.revRange != "" {
+		d.repoEnv = LOCAL
+		d.add("environment", doctorOK, "checking the local range "+d.opts.revRange, "")
+
+		return
+	}
+
+	repoEnv, err := readGitEnvironment()
+	if err != nil {
+		d.add("environment", doctorFail, err.Error(),
+			"run in GitHub Actions or GitLab CI, or give a revision range with --range outside of CI")
+
+		return
+	}
+
+	d.repoEnv = repoEnv
+	d.add("environment", doctorOK, repoEnv, "")
+}
+
+// checkRefs verifies that the base and head revisions of the request are in the clone.
+func (d *doctorT) checkRefs() {
+	refs := map[string]string{"base": requestBase(d.repoEnv, d.opts.revRange), "head": requestHeadSHA(d.repoEnv)}
+	if d.repoEnv == LOCAL || refs["head"] == "" {
+		refs["head"] = "HEAD"
+		if i := strings.Index(d.opts.revRange, ".."); i >= 0 && d.opts.revRange[i+2:] != "" {
+			refs["head"] = d.opts.revRange[i+2:]
+		}
+	}
+
+	for _, name := range []string{"base", "head"} {
+		rev := refs[name]
+		if rev == "" {
+			d.add(name+" ref", doctorWarn, "not a pull or merge request, nothing to check", "")
+
+			continue
+		}
+
+		found := false
+
+		for _, candidate := range []string{rev, "origin/" + rev} {
+			if _, err := runGit(d.opts.repoPath, "cat-file", "-e", candidate+"^{commit}"); err == nil {
+				found = true
+
+				break
+			}
+		}
+
+		if found {
+			d.add(name+" ref", doctorOK, rev, "")
+		} else {
+			d.add(name+" ref", doctorFail, rev+" is not available in the clone",
+				"check out with fetch-depth: 0, or fetch the revision before running check-commit")
+		}
+	}
+}
+
+// checkAPI verifies the token can read the repository, and reports its scopes when
+// the platform tells them.
+func (d *doctorT) checkAPI() {
+	if d.repoEnv != GITHUB && d.repoEnv != GITLAB {
+		return
+	}
+
+	if os.Getenv("API_TOKEN") == "" {
+		d.add("token", doctorFail, "API_TOKEN is not set",
+			"pass a token in the environment, e.g. API_TOKEN: ${{ secrets.GITHUB_TOKEN }}")
+
+		return
+	}
+
+	if d.repoEnv == GITHUB {
+		d.checkGithubAPI()
+	} else {
+		d.checkGitlabAPI()
+	}
+}
+
+func (d *doctorT) checkGithubAPI() {
+	repo := os.Getenv("GITHUB_REPOSITORY")
+
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) < 2 {
+		d.add("api", doctorFail, "GITHUB_REPOSITORY is not set", "run in GitHub Actions or set GITHUB_REPOSITORY")
+
+		return
+	}
+
+	ctx := context.Background()
+
+	_, resp, err := newGithubClient(ctx).Repositories.Get(ctx, parts[0], parts[1])
+	if err != nil {
+		d.add("api", doctorFail, err.Error(), githubAPIHint(resp))
+
+		return
+	}
+
+	d.add("api", doctorOK, "repository "+repo+" readable through "+os.Getenv("GITHUB_API_URL"), "")
+
+	scopes := resp.Header.Get("X-OAuth-Scopes")
+
+	switch {
+	case scopes == "":
+		d.add("token scopes", doctorOK, "not reported, permissions come from the workflow or the token settings", "")
+	case !strings.Contains(scopes, "repo"):
+		d.add("token scopes", doctorWarn, "scopes: "+scopes,
+			"the token needs the repo (or public_repo) scope to read pull requests and post comments")
+	default:
+		d.add("token scopes", doctorOK, "scopes: "+scopes, "")
+	}
+}
+
+func githubAPIHint(resp *github.Response) string {
+	if resp == nil {
+		return "check that GITHUB_API_URL is reachable from the runner"
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return "the token is invalid or expired, renew API_TOKEN"
+	case http.StatusForbidden, http.StatusNotFound:
+		return "the token cannot read the repository, grant it contents: read and pull-requests: read"
+	}
+
+	return "check the status of the GitHub API"
+}
+
+func (d *doctorT) checkGitlabAPI() {
+	client, err := newGitlabClient()
+	if err != nil {
+		d.add("api", doctorFail, err.Error(), "check CI_API_V4_URL")
+
+		return
+	}
+
+	project := os.Getenv("CI_PROJECT_PATH")
+
+	_, resp, err := client.Projects.GetProject(project, nil)
+	if err != nil {
+		hint := "check that CI_API_V4_URL is reachable from the runner"
+		if resp != nil {
+			hint = "the token cannot read the project, use a token with the read_api scope"
+		}
+
+		d.add("api", doctorFail, err.Error(), hint)
+
+		return
+	}
+
+	d.add("api", doctorOK, "project "+project+" readable through "+os.Getenv("CI_API_V4_URL"), "")
+}
+
+func (d *doctorT) checkConfig() {
+	read, err := d.opts.locatePolicy(localPolicyReader(d.opts.repoPath))
+	if err != nil {
+		d.add("configuration", doctorFail, err.Error(), "check --config")
+
+		return
+	}
+
+	config, err := readVerifiedPolicy(read, d.opts.policyKey, d.opts.preset, d.opts.profile)
+	if err != nil {
+		d.add("configuration", doctorFail, err.Error(), "fix "+policyFile+" or its signature")
+
+		return
+	}
+
+	commitPolicy, err := parseCommitPolicy(config)
+	if err != nil {
+		d.add("configuration", doctorFail, err.Error(), "fix "+policyFile)
+
+		return
+	}
+
+	if _, err := d.opts.resolvePolicyFiles(commitPolicy, LOCAL); err != nil {
+		d.add("configuration", doctorFail, err.Error(), "commit the files the policy refers to")
+
+		return
+	}
+
+	if warnings := lintPolicy(config, commitPolicy, deprecatedKeys); len(warnings) > 0 {
+		d.add("configuration", doctorWarn, fmt.Sprintf("%d lint warning(s)", len(warnings)),
+			"run check-commit lint for the details")
+
+		return
+	}
+
+	d.add("configuration", doctorOK, "valid", "")
+}
+
+func (d *doctorT) run() {
+	if !d.checkGit() {
+		return
+	}
+
+	if d.checkRepository() {
+		d.checkEnvironment()
+		d.checkRefs()
+	}
+
+	d.checkAPI()
+	d.checkConfig()
+}
+
+func (d *doctorT) print(w io.Writer) {
+	for _, result := range d.results {
+		fmt.Fprintf(w, "%-4s %s: %s\n", result.Status, result.Name, result.Detail)
+
+		if result.Hint != "" && result.Status != doctorOK {
+			fmt.Fprintf(w, "     hint: %s\n", result.Hint)
+		}
+	}
+}
+
+var ErrDoctor = errors.New("environment problems found")
+
+// doctor diagnoses the environment check-commit runs in, printing remediation hints.
+func doctor(opts optionsT, w io.Writer) error {
+	d := doctorT{opts: opts}
+	d.run()
+	d.print(w)
+
+	failures := 0
+
+	for _, result := range d.results {
+		if result.Status == doctorFail {
+			failures++
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d check(s) failed: %w", failures, ErrDoctor)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "MINOR: doctor: first commit", "MINOR: doctor: second commit")
+
+	var out bytes.Buffer
+	if err := doctor(optionsT{repoPath: repo, revRange: "HEAD~1..HEAD"}, &out); err != nil {
+		t.Errorf("doctor() error = %v\n%s", err, out.String())
+	}
+
+	for _, want := range []string{"ok   git: git version", "ok   base ref: HEAD~1", "ok   head ref: HEAD",
+		"ok   configuration: valid"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("doctor() output lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+
+	err := doctor(optionsT{repoPath: repo, revRange: "v1.0..HEAD"}, &out)
+	if !errors.Is(err, ErrDoctor) {
+		t.Errorf("doctor() error = %v, want %v", err, ErrDoctor)
+	}
+
+	if want := "FAIL base ref: v1.0 is not available in the clone\n     hint: "; !strings.Contains(out.String(), want) {
+		t.Errorf("doctor() output lacks %q:\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// duplicateSubjectsT rejects the commits with the same subject as an earlier commit of the
+// range, the sign of a missed squash or of a copy-paste mistake.
+type duplicateSubjectsT struct {
+	Verify   bool   `yaml:"Verify"`
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var ErrDuplicateSubjectsConfig = errors.New("invalid duplicate subjects rule")
+
+func (d duplicateSubjectsT) validate() error {
+	if !validSeverity(d.Severity) {
+		return fmt.Errorf("duplicate subjects rule: unknown severity '%s': %w", d.Severity, ErrDuplicateSubjectsConfig)
+	}
+
+	return nil
+}
+
+func (d duplicateSubjectsT) severity() string {
+	if d.Severity == "" {
+		return severityError
+	}
+
+	return d.Severity
+}
+
+var ErrDuplicateSubject = errors.New("duplicate commit subject")
+
+// checkDuplicateSubjects reports the commits repeating the subject of an earlier one,
+// subjects being compared in their normalized form. The fixup commits, reported on their
+// own, are left out.
+func (c CommitPolicyConfig) checkDuplicateSubjects(commits []commitT, report *reportT) {
+	if !c.DuplicateSubjects.Verify {
+		return
+	}
+
+	first := map[string]commitT{}
+
+	for _, commit := range commits {
+		subject := strings.Trim(commit.Subject(), "'")
+		if autosquashPrefix(subject) != "" {
+			continue
+		}
+
+		normalized, _ := normalizeSubject(subject)
+		normalized = strings.Join(strings.Fields(normalized), " ")
+
+		original, ok := first[normalized]
+		if !ok {
+			first[normalized] = commit
+
+			continue
+		}
+
+		report.AddCommitFinding(ruleDuplicateSubject, c.DuplicateSubjects.severity(), c.DuplicateSubjects.Shadow,
+			commit, fmt.Errorf("same subject as commit %s, squash them or reword one of them: %w",
+				shortSHA(original.SHA), ErrDuplicateSubject))
+	}
+}
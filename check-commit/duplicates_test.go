@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDuplicateSubjects(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + "DuplicateSubjects:\n  Verify: true\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commits := []commitT{
+		{SHA: "0123456789abcdef", Message: "BUG/MINOR: mux: fix a crash on close"},
+		{SHA: "1123456789abcdef", Message: "MINOR: cli: add a command"},
+		{SHA: "2123456789abcdef", Message: "BUG/MINOR: mux: fix a crash on close\n\nfor real"},
+		{SHA: "3123456789abcdef", Message: "fixup! MINOR: cli: add a command"},
+		{SHA: "4123456789abcdef", Message: "fixup! MINOR: cli: add a command"},
+		{SHA: "5123456789abcdef", Message: "BUG/MINOR: mux: fix a crash on close"},
+	}
+
+	report := c.newReport(commits)
+	c.checkDuplicateSubjects(commits, &report)
+
+	want := "same subject as commit 01234567, squash them or reword one of them: " + ErrDuplicateSubject.Error()
+	if len(report.Findings) != 2 || report.Findings[0].SHA != commits[2].SHA || report.Findings[1].SHA != commits[5].SHA ||
+		report.Findings[0].Rule != ruleDuplicateSubject || report.Findings[0].Message != want {
+		t.Errorf("checkDuplicateSubjects() findings = %+v", report.Findings)
+	}
+
+	c.DuplicateSubjects.Verify = false
+	report = c.newReport(commits)
+	c.checkDuplicateSubjects(commits, &report)
+
+	if len(report.Findings) != 0 {
+		t.Errorf("checkDuplicateSubjects() findings = %+v, want none when disabled", report.Findings)
+	}
+
+	if _, err := parseCommitPolicy("DuplicateSubjects:\n  Severity: fatal\n"); !errors.Is(err, ErrDuplicateSubjectsConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrDuplicateSubjectsConfig)
+	}
+}
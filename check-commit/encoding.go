@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+type encodingT struct {
+	Verify   bool   `yaml:"Verify"`
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var ErrEncodingConfig = errors.New("invalid encoding rule")
+
+func (e encodingT) validate() error {
+	if !validSeverity(e.Severity) {
+		return fmt.Errorf("encoding rule: unknown severity '%s': %w", e.Severity, ErrEncodingConfig)
+	}
+
+	return nil
+}
+
+func (e encodingT) severity() string {
+	if e.Severity == "" {
+		return severityError
+	}
+
+	return e.Severity
+}
+
+// parseCommitObject returns the encoding header of a raw commit object and its
+// message exactly as stored: git re-encodes messages when printing them, even with
+// --encoding=none.
+func parseCommitObject(object string) (string, []byte, error) {
+	parts := strings.SplitN(object, "\n\n", 2)
+	if len(parts) != 2 {
+		return "", nil, fmt.Errorf("malformed commit object: %w", ErrGitCommand)
+	}
+
+	encoding := ""
+
+	for _, header := range strings.Split(parts[0], "\n") {
+		if strings.HasPrefix(header, "encoding ") {
+			encoding = strings.TrimPrefix(header, "encoding ")
+		}
+	}
+
+	return encoding, []byte(parts[1]), nil
+}
+
+type rawMessageT struct {
+	Encoding string
+	Message  []byte // nil when the commit is missing from the clone
+}
+
+// gitRawMessages reads the raw objects of several commits with a single git
+// invocation, returning them in the order of the commits.
+func gitRawMessages(repoPath string, shas []string) ([]rawMessageT, error) {
+	out, err := runGitInput(repoPath, strings.Join(shas, "\n")+"\n", "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]rawMessageT, 0, len(shas))
+
+	for _, sha := range shas {
+		// each object is output as "<oid> <type> <size>\n<content>\n"
+		end := strings.IndexByte(out, '\n')
+		if end < 0 {
+			return nil, fmt.Errorf("git cat-file: truncated output: %w", ErrGitCommand)
+		}
+
+		fields := strings.Fields(out[:end])
+		if len(fields) == 2 && fields[1] == "missing" {
+			messages = append(messages, rawMessageT{})
+			out = out[end+1:]
+
+			continue
+		}
+
+		size := 0
+		if len(fields) == 3 && fields[1] == "commit" {
+			size, err = strconv.Atoi(fields[2])
+		}
+
+		if size == 0 || err != nil || end+1+size > len(out) {
+			return nil, fmt.Errorf("git cat-file: %s is not an available commit: %w", sha, ErrGitCommand)
+		}
+
+		encoding, message, err := parseCommitObject(out[end+1 : end+1+size])
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, rawMessageT{encoding, message})
+		out = out[end+1+size+1:]
+	}
+
+	return messages, nil
+}
+
+var ErrCommitEncoding = errors.New("commit message does not decode cleanly")
+
+// decodeMessage decodes the message from its declared encoding, UTF-8 when the
+// commit declares none.
+func decodeMessage(encoding string, message []byte) (string, error) {
+	if encoding == "" || strings.EqualFold(encoding, "utf-8") || strings.EqualFold(encoding, "utf8") {
+		if !utf8.Valid(message) {
+			return "", fmt.Errorf("message is not valid UTF-8: %w", ErrCommitEncoding)
+		}
+
+		return string(message), nil
+	}
+
+	enc, err := ianaindex.IANA.Encoding(encoding)
+	if err != nil || enc == nil {
+		return "", fmt.Errorf("unknown encoding '%s' declared: %w", encoding, ErrCommitEncoding)
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(message)
+	if err != nil {
+		return "", fmt.Errorf("message is not valid %s: %s: %w", encoding, err, ErrCommitEncoding)
+	}
+
+	if strings.ContainsRune(string(decoded), utf8.RuneError) {
+		return "", fmt.Errorf("message has characters that are not valid %s: %w", encoding, ErrCommitEncoding)
+	}
+
+	return string(decoded), nil
+}
+
+// isMojibake tells whether the text looks like UTF-8 that was decoded as Latin-1 (or
+// Windows-1252) and encoded again, as in "cafÃ©": its Latin-1 bytes then form valid
+// multibyte UTF-8 sequences.
+func isMojibake(text string) bool {
+	latin1 := make([]byte, 0, len(text))
+	multibyte := false
+
+	for _, r := range text {
+		if r > 0xFF {
+			r = windows1252Byte(r)
+			if r == 0 {
+				return false
+			}
+		}
+
+		multibyte = multibyte || r >= 0x80
+		latin1 = append(latin1, byte(r))
+	}
+
+	return multibyte && utf8.Valid(latin1)
+}
+
+// windows1252Byte maps the characters Windows-1252 has in place of the C1 controls of
+// Latin-1 back to their byte, or returns 0 for characters of neither.
+func windows1252Byte(r rune) rune {
+	const c1 = "€\x81‚ƒ„…†‡ˆ‰Š‹Œ\x8dŽ\x8f\x90‘’“”•–—˜™š›œ\x9džŸ"
+
+	for i, c := range []rune(c1) {
+		if c == r {
+			return rune(0x80 + i)
+		}
+	}
+
+	return 0
+}
+
+// Check verifies that the message decodes cleanly from the declared encoding and
+// does not look double-encoded.
+func (e encodingT) Check(encoding string, message []byte) error {
+	decoded, err := decodeMessage(encoding, message)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(decoded, "\n") {
+		if isMojibake(line) {
+			return fmt.Errorf("message looks double-encoded (mojibake): '%s': %w", line, ErrCommitEncoding)
+		}
+	}
+
+	return nil
+}
+
+// checkEncodings reads the raw messages from the local clone, as the APIs only provide
+// them already decoded.
+func (c CommitPolicyConfig) checkEncodings(repoPath string, commits []commitT, report *reportT) {
+	if !c.Encoding.Verify || len(commits) == 0 {
+		return
+	}
+
+	shas := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		shas = append(shas, commit.SHA)
+	}
+
+	messages, err := gitRawMessages(repoPath, shas)
+	if err != nil {
+		log.Printf("warning: skipping encoding checks: %s", err)
+
+		return
+	}
+
+	for i, commit := range commits {
+		if messages[i].Message == nil {
+			log.Printf("warning: skipping encoding check of commit %s: not available in the clone", shortSHA(commit.SHA))
+
+			continue
+		}
+
+		report.AddCommitFinding(ruleEncoding, c.Encoding.severity(), c.Encoding.Shadow, commit,
+			c.Encoding.Check(messages[i].Encoding, messages[i].Message))
+	}
+}
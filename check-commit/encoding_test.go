@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncodingCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		encoding string
+		message  string
+		wantErr  bool
+	}{
+		{"ascii", "", "BUG/MINOR: mux: fix a crash on close", false},
+		{"utf-8", "UTF-8", "DOC: fix the name of José in the credits", false},
+		{"undeclared latin-1", "", "DOC: fix the name of Jos\xe9 in the credits", true},
+		{"declared latin-1", "ISO-8859-1", "DOC: fix the name of Jos\xe9 in the credits", false},
+		{"unknown encoding", "klingon", "DOC: fix the credits", true},
+		{"mojibake", "", "DOC: fix the name of JosÃ© in the credits", true},
+		{"mojibake in body", "", "DOC: fix the credits\n\nâ€œquotedâ€\x9d", true},
+		{"cjk", "", "DOC: 修正文档中的错误", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := encodingT{}.Check(tt.encoding, []byte(tt.message))
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrCommitEncoding)) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckEncodings(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "DOC: fix the name of José in the credits")
+
+	_, err := runGit(repo, "-c", "i18n.commitEncoding=ISO-8859-1", "commit", "-q", "--allow-empty",
+		"-m", "DOC: fix the name of Jos\xe9 again")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// git commit re-encodes undeclared Latin-1 messages, write the object directly
+	head, err := runGit(repo, "rev-parse", "HEAD", "HEAD^{tree}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := strings.Fields(head)
+	object := filepath.Join(t.TempDir(), "commit")
+	content := "tree " + refs[1] + "\nparent " + refs[0] + "\nauthor A <a@example.com> 0 +0000\n" +
+		"committer A <a@example.com> 0 +0000\n\nDOC: fix the name of Jos\xe9 once more\n"
+
+	if err := ioutil.WriteFile(object, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sha, err := runGit(repo, "hash-object", "-t", "commit", "-w", object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commits := []commitT{{SHA: strings.TrimSpace(sha)}, {SHA: refs[0]}, {SHA: refs[0] + "~1"},
+		{SHA: "2222222222222222222222222222222222222222"}}
+	report := reportT{Commits: commits}
+	CommitPolicyConfig{Encoding: encodingT{Verify: true}}.checkEncodings(repo, commits, &report)
+
+	if len(report.Findings) != 1 || report.Findings[0].SHA != commits[0].SHA || report.Findings[0].Rule != ruleEncoding {
+		t.Errorf("checkEncodings() = %+v", report.Findings)
+	}
+}
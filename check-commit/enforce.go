@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrEnforceAfter = errors.New("invalid enforcement date")
+
+// validateEnforceAfter checks that EnforceAfter gives YYYY-MM-DD dates to known rules
+// whose severity is not set by Severities as well.
+func (c CommitPolicyConfig) validateEnforceAfter() error {
+	rules := append(append([]string{}, builtinRules...), c.customRuleNames()...)
+
+	for rule, date := range c.EnforceAfter {
+		if !containsString(rules, rule) {
+			return fmt.Errorf("EnforceAfter: unknown rule '%s'%s: %w", rule, didYouMean(rule, rules), ErrEnforceAfter)
+		}
+
+		if _, ok := c.Severities[rule]; ok {
+			return fmt.Errorf("EnforceAfter: the severity of rule '%s' is already set by Severities: %w", rule,
+				ErrEnforceAfter)
+		}
+
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return fmt.Errorf("EnforceAfter: rule '%s': '%s' is not a YYYY-MM-DD date: %w", rule, date, ErrEnforceAfter)
+		}
+	}
+
+	return nil
+}
+
+// ruleSeverities returns the severities of the rules at the given time: those of
+// Severities, and for the rules of EnforceAfter warnings until their date and errors
+// from then on. The dates of the rules still in their grace period are returned along.
+func (c CommitPolicyConfig) ruleSeverities(now time.Time) (map[string]string, map[string]string) {
+	if len(c.EnforceAfter) == 0 {
+		return c.Severities, nil
+	}
+
+	severities := map[string]string{}
+	for rule, severity := range c.Severities {
+		severities[rule] = severity
+	}
+
+	grace := map[string]string{}
+
+	for rule, date := range c.EnforceAfter {
+		from, _ := time.Parse(dateLayout, date) // validated when loading
+
+		severities[rule] = severityError
+		if now.Before(from) {
+			severities[rule] = severityWarning
+			grace[rule] = date
+		}
+	}
+
+	return severities, grace
+}
+
+// newReport returns an empty report of the commits, with the severities of the rules,
+// the message catalog and the tag format of the policy.
+func (c CommitPolicyConfig) newReport(commits []commitT) reportT {
+	severities, grace := c.ruleSeverities(time.Now())
+
+	return reportT{
+		Commits: commits, severities: severities, grace: grace, catalog: c.messageCatalog(), tagFormat: c.tagRegexp(),
+	}
+}
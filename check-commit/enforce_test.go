@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnforceAfter(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + "EnforceAfter:\n  subject-format: 2025-01-01\n  tag: 2025-06-01\n" +
+		"Severities:\n  language: warning\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		now       string
+		wantTag   string
+		wantGrace int
+	}{
+		{"2024-12-31", severityWarning, 2},
+		{"2025-03-01", severityWarning, 1},
+		{"2025-06-01", severityError, 0},
+	}
+
+	for _, tt := range tests {
+		now, _ := time.Parse(dateLayout, tt.now)
+		severities, grace := c.ruleSeverities(now)
+
+		if severities[ruleTag] != tt.wantTag || len(grace) != tt.wantGrace || severities[ruleLanguage] != severityWarning {
+			t.Errorf("ruleSeverities(%s) = %v, %v, want tag %s and %d rule(s) in grace", tt.now, severities, grace,
+				tt.wantTag, tt.wantGrace)
+		}
+	}
+
+	report := reportT{severities: map[string]string{ruleTag: severityWarning}, grace: map[string]string{ruleTag: "2025-06-01"}}
+	c.checkCommits([]commitT{{SHA: "0123456789abcdef", Message: "fix the parser"}}, &report)
+
+	if len(report.Findings) != 1 || report.Findings[0].Severity != severityWarning ||
+		!strings.HasSuffix(report.Findings[0].Message, " (an error from 2025-06-01)") {
+		t.Errorf("checkCommits() findings = %+v", report.Findings)
+	}
+}
+
+func TestValidateEnforceAfter(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{
+		"EnforceAfter:\n  tags: 2025-01-01\n",
+		"EnforceAfter:\n  tag: soon\n",
+		"EnforceAfter:\n  tag: 2025-01-01\nSeverities:\n  tag: warning\n",
+	} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrEnforceAfter) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrEnforceAfter)
+		}
+	}
+}
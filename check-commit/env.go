@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const envOverridePrefix = "CHECK_COMMIT_"
+
+// envExcludedKeys cannot be overridden from the environment: they are resolved before
+// the overrides apply, or would let a workflow lift the restrictions of a central policy.
+// The profile is selected with --profile instead, CHECK_COMMIT_PROFILE by default.
+var envExcludedKeys = map[string]bool{
+	versionKey: true, branchOverridesKey: true, centralOverridesKey: true, profileKey: true, profilesKey: true,
+}
+
+var ErrEnvOverride = errors.New("invalid environment override")
+
+// envName returns the environment variable overriding a top-level key of the policy,
+// e.g. CHECK_COMMIT_SUBJECT_MAX_LEN for SubjectMaxLen.
+func envName(key string) string {
+	runes := []rune(key)
+	name := envOverridePrefix
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (!unicode.IsUpper(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			name += "_"
+		}
+
+		name += string(unicode.ToUpper(r))
+	}
+
+	return name
+}
+
+// envOverrides returns the top-level keys of the policy set by the environment, with
+// their values decoded as YAML.
+func envOverrides(environ []string) (map[interface{}]interface{}, error) {
+	keys := map[string][]string{}
+	structKeys(reflect.TypeOf(CommitPolicyConfig{}), keys)
+
+	names := map[string]string{}
+
+	for _, key := range keys[reflect.TypeOf(CommitPolicyConfig{}).String()] {
+		if !envExcludedKeys[key] {
+			names[envName(key)] = key
+		}
+	}
+
+	overrides := map[interface{}]interface{}{}
+
+	for _, variable := range environ {
+		i := strings.Index(variable, "=")
+		if i < 0 {
+			continue
+		}
+
+		key, ok := names[variable[:i]]
+		if !ok {
+			continue
+		}
+
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(variable[i+1:]), &value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", variable[:i], err, ErrEnvOverride)
+		}
+
+		overrides[key] = value
+	}
+
+	return overrides, nil
+}
+
+// applyEnvOverrides returns the policy with the keys set by the environment merged over
+// it, logging the overridden keys.
+func applyEnvOverrides(config string, environ []string) (string, error) {
+	overrides, err := envOverrides(environ)
+	if err != nil || len(overrides) == 0 {
+		return config, err
+	}
+
+	document := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return config, nil // reported when loading the policy
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, envName(fmt.Sprint(key)))
+	}
+
+	sort.Strings(keys)
+	log.Printf("policy overridden by the environment: %s", strings.Join(keys, ", "))
+
+	data, err := yaml.Marshal(mergePolicies(document, overrides))
+	if err != nil {
+		return "", fmt.Errorf("error applying the environment overrides: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"SubjectMaxLen":          "CHECK_COMMIT_SUBJECT_MAX_LEN",
+		"MaxCommitsExemptLabels": "CHECK_COMMIT_MAX_COMMITS_EXEMPT_LABELS",
+		"Shadow":                 "CHECK_COMMIT_SHADOW",
+		"DiffHeuristics":         "CHECK_COMMIT_DIFF_HEURISTICS",
+	}
+
+	for key, want := range tests {
+		if got := envName(key); got != want {
+			t.Errorf("envName(%s) = %s, want %s", key, got, want)
+		}
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Parallel()
+
+	const config = `
+MaxCommits: 50
+SubjectMaxLen: 100
+Severities:
+  encoding: warning
+`
+
+	environ := []string{
+		"CHECK_COMMIT_SUBJECT_MAX_LEN=72",
+		"CHECK_COMMIT_REQUIRE_ENGLISH=true",
+		"CHECK_COMMIT_SEVERITIES={component: \"off\"}",
+		"CHECK_COMMIT_PRESET=kernel",
+		"CHECK_COMMIT_OVERRIDABLE_KEYS=[MaxCommits]",
+		"HOME=/root",
+	}
+
+	merged, err := applyEnvOverrides(config, environ)
+	if err != nil {
+		t.Fatalf("applyEnvOverrides() error = %v", err)
+	}
+
+	c, err := parseCommitPolicy(merged)
+	if err != nil {
+		t.Fatalf("parseCommitPolicy() error = %v", err)
+	}
+
+	if c.MaxCommits != 50 || c.SubjectMaxLen != 72 || !c.RequireEnglish || len(c.OverridableKeys) != 0 {
+		t.Errorf("applyEnvOverrides() = %+v, want the overridden keys only", c)
+	}
+
+	if c.Severities["encoding"] != severityWarning || c.Severities[ruleComponent] != severityOff {
+		t.Errorf("applyEnvOverrides() severities = %v, want both merged", c.Severities)
+	}
+}
+
+func TestApplyEnvOverridesErrors(t *testing.T) {
+	t.Parallel()
+
+	if got, err := applyEnvOverrides("MaxCommits: 5\n", nil); err != nil || got != "MaxCommits: 5\n" {
+		t.Errorf("applyEnvOverrides() = %q, %v, want the policy unchanged", got, err)
+	}
+
+	_, err := applyEnvOverrides("MaxCommits: 5\n", []string{"CHECK_COMMIT_MAX_COMMITS=[5"})
+	if !errors.Is(err, ErrEnvOverride) {
+		t.Errorf("applyEnvOverrides() error = %v, want %v", err, ErrEnvOverride)
+	}
+
+	merged, err := applyEnvOverrides("MaxCommits: 5\n", []string{"CHECK_COMMIT_MAX_COMMITS=many"})
+	if err != nil {
+		t.Fatalf("applyEnvOverrides() error = %v", err)
+	}
+
+	if _, err := parseCommitPolicy(merged); err == nil {
+		t.Errorf("parseCommitPolicy() accepted a non-numeric MaxCommits")
+	}
+}
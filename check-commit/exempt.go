@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const exceptionAuthor = "author-exemption"
+
+// exemptAuthor returns the entry of ExemptAuthors matching the author of the commit:
+// an email address, or a login otherwise, both compared regardless of case. Logins are
+// only those the forge API reports, the no-reply addresses carrying one being as easy
+// to forge as any other.
+func (c CommitPolicyConfig) exemptAuthor(commit commitT) string {
+	identities := authorIdentities(commit.Author)
+	email := identities[len(identities)-1]
+	login := commit.Login
+
+	for _, exempt := range c.ExemptAuthors {
+		if strings.Contains(exempt, "@") && strings.EqualFold(exempt, email) ||
+			login != "" && strings.EqualFold(exempt, login) {
+			return exempt
+		}
+	}
+
+	return ""
+}
+
+// skipCommits returns the commits to check, leaving out the ignored ones and those of
+// exempt authors, and the exceptions made for them.
+func (c CommitPolicyConfig) skipCommits(commits []commitT) ([]commitT, []exceptionT) {
+	commits, exceptions := c.Ignore.filter(commits)
+	kept := make([]commitT, 0, len(commits))
+
+	for _, commit := range commits {
+		if bot := c.commitBot(commit); bot != "" && c.Bots[bot] == botExempt {
+			exceptions = append(exceptions, exceptionT{
+				Rule:    rulePolicy,
+				Kind:    exceptionBot,
+				SHA:     commit.SHA,
+				Subject: commit.Subject(),
+				Reason:  fmt.Sprintf("commit %s not checked, %s commits are exempt", shortSHA(commit.SHA), bot),
+			})
+
+			continue
+		}
+
+		exempt := c.exemptAuthor(commit)
+		if exempt == "" {
+			kept = append(kept, commit)
+
+			continue
+		}
+
+		exceptions = append(exceptions, exceptionT{
+			Rule:    rulePolicy,
+			Kind:    exceptionAuthor,
+			SHA:     commit.SHA,
+			Subject: commit.Subject(),
+			Reason:  fmt.Sprintf("commit %s not checked, author %s is exempt", shortSHA(commit.SHA), exempt),
+		})
+	}
+
+	return kept, exceptions
+}
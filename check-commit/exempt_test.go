@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestSkipCommits(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{
+		ExemptAuthors: []string{"release-bot@example.com", "renovate[bot]", "octocat"},
+		Ignore:        ignoreT{Subjects: []string{"^Merge "}},
+	}
+
+	commits := []commitT{
+		{SHA: "0123456789abcdef", Author: "Jane Doe <jane@example.com>", Message: "BUG/MINOR: config: fix timeouts"},
+		{SHA: "1123456789abcdef", Author: "Release Bot <Release-Bot@example.com>", Message: "v2.8.1"},
+		{SHA: "2123456789abcdef", Author: "Renovate <bot@renovateapp.com>", Login: "renovate[bot]", Message: "Update"},
+		{SHA: "3123456789abcdef", Author: "The Octocat <583231+octocat@users.noreply.github.com>", Login: "octocat",
+			Message: "wip"},
+		{SHA: "4123456789abcdef", Author: "Jane Doe <jane@example.com>", Message: "Merge branch 'master'"},
+		{SHA: "5123456789abcdef", Author: "Mallory <1+octocat@users.noreply.github.com>", Message: "wip"},
+	}
+
+	kept, exceptions := c.skipCommits(commits)
+	if len(kept) != 2 || kept[0].SHA != commits[0].SHA || kept[1].SHA != commits[5].SHA {
+		t.Errorf("skipCommits() kept %+v", kept)
+	}
+
+	want := []string{
+		"commit 41234567 not checked, subject matches ignored pattern '^Merge '",
+		"commit 11234567 not checked, author release-bot@example.com is exempt",
+		"commit 21234567 not checked, author renovate[bot] is exempt",
+		"commit 31234567 not checked, author octocat is exempt",
+	}
+
+	if len(exceptions) != len(want) {
+		t.Fatalf("skipCommits() exceptions = %+v", exceptions)
+	}
+
+	for i, exception := range exceptions {
+		if exception.Reason != want[i] {
+			t.Errorf("exception %d = %s, want %s", i, exception.Reason, want[i])
+		}
+	}
+}
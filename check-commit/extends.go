@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	extendsKey      = "Extends"
+	maxExtendsDepth = 5
+	extendsTimeout  = 30 * time.Second
+)
+
+var ErrExtends = errors.New("invalid Extends reference")
+
+// extendsRefT designates a parent policy: a URL, or a file of another repository of
+// the forge given as "owner/repo[@ref][:path]".
+type extendsRefT struct {
+	URL  string
+	Repo string
+	Ref  string
+	Path string
+}
+
+func parseExtendsRef(value string) (extendsRefT, error) {
+	switch {
+	case strings.HasPrefix(value, "https://"):
+		return extendsRefT{URL: value}, nil
+	case strings.HasPrefix(value, "http://"):
+		return extendsRefT{}, fmt.Errorf("'%s' must be fetched over https: %w", value, ErrExtends)
+	}
+
+	ref := extendsRefT{Repo: value, Path: policyFile}
+
+	if i := strings.Index(ref.Repo, ":"); i >= 0 {
+		ref.Repo, ref.Path = ref.Repo[:i], ref.Repo[i+1:]
+	}
+
+	if i := strings.LastIndex(ref.Repo, "@"); i >= 0 {
+		ref.Repo, ref.Ref = ref.Repo[:i], ref.Repo[i+1:]
+	}
+
+	slash := strings.Index(ref.Repo, "/")
+	if slash <= 0 || slash == len(ref.Repo)-1 || ref.Path == "" {
+		return extendsRefT{}, fmt.Errorf("'%s' is neither a URL nor in owner/repo[@ref][:path] form: %w", value, ErrExtends)
+	}
+
+	return ref, nil
+}
+
+// forgeEnvironment tells the forge whose API the run can use, from its variables.
+func forgeEnvironment() string {
+	switch {
+	case os.Getenv("GITHUB_API_URL") != "":
+		return GITHUB
+	case os.Getenv("CI_API_V4_URL") != "":
+		return GITLAB
+	}
+
+	return ""
+}
+
+// extendsTransport is the transport the parent policies are fetched with when they are
+// not cached.
+var extendsTransport = http.DefaultTransport
+
+// signatureRef designates the minisign signature of the parent policy, in the .minisig
+// file next to it.
+func (r extendsRefT) signatureRef() extendsRefT {
+	if r.URL != "" {
+		r.URL += ".minisig"
+	} else {
+		r.Path += ".minisig"
+	}
+
+	return r
+}
+
+// fetch reads the parent policy. Responses are cached and revalidated like API
+// responses when CHECK_COMMIT_CACHE_DIR is set.
+func (r extendsRefT) fetch() (string, error) {
+	if r.URL == "" {
+		read, ok := forgeFileReader(forgeEnvironment(), r.Repo, r.Ref)
+		if !ok {
+			return "", fmt.Errorf("policies of other repositories are read through the GitHub or GitLab API: %w",
+				ErrExtends)
+		}
+
+		return read(r.Path)
+	}
+
+	client := cachingHTTPClient()
+	if client == nil {
+		client = &http.Client{Transport: extendsTransport}
+	}
+
+	client.Timeout = extendsTimeout
+
+	resp, err := client.Get(r.URL)
+	if err != nil {
+		return "", fmt.Errorf("error fetching %s: %w", r.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching %s: %s: %w", r.URL, resp.Status, ErrExtends)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error fetching %s: %w", r.URL, err)
+	}
+
+	return string(data), nil
+}
+
+// mergePolicies layers the child policy over its parent: mappings such as PatchTypes
+// are merged key by key, any other value of the child replaces the parent's.
+func mergePolicies(parent, child map[interface{}]interface{}) map[interface{}]interface{} {
+	merged := map[interface{}]interface{}{}
+
+	for key, value := range parent {
+		merged[key] = value
+	}
+
+	for key, value := range child {
+		parentMap, parentOK := merged[key].(map[interface{}]interface{})
+		childMap, childOK := value.(map[interface{}]interface{})
+
+		if parentOK && childOK {
+			merged[key] = mergePolicies(parentMap, childMap)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// resolveExtends returns the policy merged over the chain of policies it extends. When
+// a minisign public key is given, every parent must come with a valid signature in the
+// .minisig file next to it, like the policy.
+func resolveExtends(config, publicKey string) (string, error) {
+	var verify func(ref extendsRefT, content string) error
+
+	if publicKey != "" {
+		key, err := parseMinisignKey(publicKey)
+		if err != nil {
+			return "", err
+		}
+
+		verify = func(ref extendsRefT, content string) error {
+			signature, err := ref.signatureRef().fetch()
+			if err != nil {
+				return fmt.Errorf("parent policy must be signed: %s: %w", err, ErrPolicySignature)
+			}
+
+			return key.verify([]byte(content), signature)
+		}
+	}
+
+	return resolveExtendsChain(config, nil, verify)
+}
+
+func resolveExtendsChain(config string, chain []string, verify func(ref extendsRefT, content string) error) (
+	string, error) {
+	document := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return config, nil // reported when loading the policy
+	}
+
+	value, ok := document[extendsKey]
+	if !ok {
+		return config, nil
+	}
+
+	name, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a single reference: %w", extendsKey, ErrExtends)
+	}
+
+	switch {
+	case containsString(chain, name):
+		return "", fmt.Errorf("%s loops back to %s: %w", strings.Join(chain, " -> "), name, ErrExtends)
+	case len(chain) >= maxExtendsDepth:
+		return "", fmt.Errorf("more than %d policies extended: %w", maxExtendsDepth, ErrExtends)
+	}
+
+	ref, err := parseExtendsRef(name)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("extending the policy %s", name)
+
+	parentConfig, err := ref.fetch()
+	if err == nil && verify != nil {
+		err = verify(ref, parentConfig)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("error extending %s: %w", name, err)
+	}
+
+	if parentConfig, err = decodePolicy(parentConfig); err != nil {
+		return "", fmt.Errorf("error extending %s: %w", name, err)
+	}
+
+	if parentConfig, err = resolveExtendsChain(parentConfig, append(chain, name), verify); err != nil {
+		return "", err
+	}
+
+	parent := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(parentConfig), &parent); err != nil {
+		return "", fmt.Errorf("error extending %s: %w", name, err)
+	}
+
+	delete(document, extendsKey)
+
+	data, err := yaml.Marshal(mergePolicies(parent, document))
+	if err != nil {
+		return "", fmt.Errorf("error extending %s: %w", name, err)
+	}
+
+	return string(data), nil
+}
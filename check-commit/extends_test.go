@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newPolicyServer serves the policies over https and lets the parent policies be
+// fetched from it for the duration of the test.
+func newPolicyServer(t *testing.T, policies map[string]string) string {
+	t.Helper()
+
+	var server *httptest.Server
+
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy, ok := policies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		fmt.Fprint(w, strings.ReplaceAll(policy, "{server}", server.URL))
+	}))
+	t.Cleanup(server.Close)
+
+	transport := extendsTransport
+	extendsTransport = server.Client().Transport
+
+	t.Cleanup(func() { extendsTransport = transport })
+
+	return server.URL
+}
+
+func TestResolveExtends(t *testing.T) {
+	serverURL := newPolicyServer(t, map[string]string{
+		"/base.yml": "MaxCommits: 10\nRequireEnglish: true\nPatchScopes:\n  Scope: [MINOR]\n  Other: [MAJOR]\n",
+		"/org.toml": "Extends = \"{server}/base.yml\"\nMaxCommits = 20\n[PatchScopes]\nScope = [\"MINOR\", \"MEDIUM\"]\n",
+		"/loop.yml": "Extends: {server}/loop.yml\n",
+	})
+
+	config, err := resolveExtends("Extends: "+serverURL+"/org.toml\nMaxCommits: 30\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := parseCommitPolicy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.MaxCommits != 30 || !c.RequireEnglish || len(c.PatchScopes["Scope"]) != 2 || len(c.PatchScopes["Other"]) != 1 {
+		t.Errorf("resolveExtends() = %+v", c)
+	}
+
+	for _, config := range []string{
+		"Extends: " + serverURL + "/loop.yml\n",
+		"Extends: " + serverURL + "/missing.yml\n",
+		"Extends: " + strings.Replace(serverURL, "https://", "http://", 1) + "/base.yml\n",
+		"Extends: [a/b, c/d]\n",
+		"Extends: not-a-repository\n",
+	} {
+		if _, err := resolveExtends(config, ""); !errors.Is(err, ErrExtends) {
+			t.Errorf("resolveExtends(%q) error = %v", config, err)
+		}
+	}
+}
+
+func TestResolveExtendsSigned(t *testing.T) {
+	serverURL := newPolicyServer(t, map[string]string{
+		"/base.yml":           "MaxCommits: 10\n",
+		"/forged.yml":         "MaxCommits: 10\n",
+		"/forged.yml.minisig": "junk",
+	})
+
+	const key = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+
+	for _, name := range []string{"base.yml", "forged.yml"} {
+		config := "Extends: " + serverURL + "/" + name + "\n"
+		if _, err := resolveExtends(config, key); !errors.Is(err, ErrPolicySignature) {
+			t.Errorf("resolveExtends(%q) error = %v", config, err)
+		}
+	}
+}
+
+func TestParseExtendsRef(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  extendsRefT
+	}{
+		{"haproxytech/.commit-policy", extendsRefT{Repo: "haproxytech/.commit-policy", Path: policyFile}},
+		{"haproxytech/policies@v1:go/strict.yml", extendsRefT{Repo: "haproxytech/policies", Ref: "v1", Path: "go/strict.yml"}},
+		{"https://example.com/policy.yml", extendsRefT{URL: "https://example.com/policy.yml"}},
+	}
+
+	for _, tt := range tests {
+		if got, err := parseExtendsRef(tt.value); err != nil || got != tt.want {
+			t.Errorf("parseExtendsRef(%s) = %+v, %v, want %+v", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// forbiddenWordsT rejects the subjects of unfinished work: those containing one of the
+// Words once their tags are removed, as whole words regardless of case, e.g. "wip" or
+// "do not merge", and those starting with one of the Prefixes, such as "DRAFT:".
+type forbiddenWordsT struct {
+	Words    []string `yaml:"Words"`
+	Prefixes []string `yaml:"Prefixes"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrForbiddenWordsConfig = errors.New("invalid forbidden words rule")
+
+func (f forbiddenWordsT) validate() error {
+	for _, word := range append(append([]string{}, f.Words...), f.Prefixes...) {
+		if strings.TrimSpace(word) == "" {
+			return fmt.Errorf("forbidden words rule: empty word or prefix: %w", ErrForbiddenWordsConfig)
+		}
+	}
+
+	if !validSeverity(f.Severity) {
+		return fmt.Errorf("forbidden words rule: unknown severity '%s': %w", f.Severity, ErrForbiddenWordsConfig)
+	}
+
+	return nil
+}
+
+func (f forbiddenWordsT) severity() string {
+	if f.Severity == "" {
+		return severityError
+	}
+
+	return f.Severity
+}
+
+// wordRegexp matches the word, or words, anywhere in a text regardless of case and of
+// the spacing between them.
+func wordRegexp(word string) *regexp.Regexp {
+	parts := strings.Fields(word)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return regexp.MustCompile(`(?i)(^|[^\pL\pN])` + strings.Join(parts, `\s+`) + `($|[^\pL\pN])`)
+}
+
+var ErrForbiddenWord = errors.New("forbidden word in subject")
+
+// Check checks the subject and its text past the tags.
+func (f forbiddenWordsT) Check(subject, text string) error {
+	for _, prefix := range f.Prefixes {
+		for _, s := range []string{subject, text} {
+			if strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix)) {
+				return fmt.Errorf("subject starts with '%s', unfinished work must not be merged: %w", prefix,
+					ErrForbiddenWord)
+			}
+		}
+	}
+
+	for _, word := range f.Words {
+		if wordRegexp(word).MatchString(text) {
+			return fmt.Errorf("subject contains '%s', unfinished work must not be merged: %w", word, ErrForbiddenWord)
+		}
+	}
+
+	return nil
+}
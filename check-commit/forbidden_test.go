@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestForbiddenWordsCheck(t *testing.T) {
+	t.Parallel()
+
+	rule := forbiddenWordsT{Words: []string{"wip", "tmp", "do not merge"}, Prefixes: []string{"fixup!", "squash!"}}
+
+	tests := []struct {
+		subject string
+		wantErr bool
+	}{
+		{"MINOR: config: add a timeout", false},
+		{"MINOR: wip do not merge", true},
+		{"MINOR: config: WIP timeouts", true},
+		{"MINOR: config: Do  not merge, timeouts", true},
+		{"MINOR: config: (tmp) timeouts", true},
+		{"MINOR: config: wipe the temporary timeouts", false},
+		{"fixup! MINOR: config: add a timeout", true},
+		{"MINOR: Fixup! config: add a timeout", true},
+		{"MINOR: config: fixup! the timeouts", false},
+		{"WIP: config: add a timeout", false}, // a tag, rejected as such by the tag rule
+	}
+
+	var c CommitPolicyConfig
+
+	for _, tt := range tests {
+		err := rule.Check(tt.subject, c.stripTagPrefixes(tt.subject))
+		if (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrForbiddenWord) {
+			t.Errorf("Check(%s) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	if err := (forbiddenWordsT{}).Check("MINOR: wip do not merge", "wip do not merge"); err != nil {
+		t.Errorf("Check() without words error = %v", err)
+	}
+}
+
+func TestForbiddenWordsValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, rule := range []forbiddenWordsT{{Words: []string{" "}}, {Prefixes: []string{""}}, {Severity: "fatal"}} {
+		if err := rule.validate(); !errors.Is(err, ErrForbiddenWordsConfig) {
+			t.Errorf("validate(%+v) error = %v, want %v", rule, err, ErrForbiddenWordsConfig)
+		}
+	}
+}
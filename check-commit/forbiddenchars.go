@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// forbiddenCharsT rejects the subjects containing one of Characters, or matching one of
+// Patterns, such as what editors and mail tools leave behind: backticks, smart quotes,
+// tabs and the "[PATCH v2]" markers of git format-patch. The rule is off without either.
+type forbiddenCharsT struct {
+	Characters string   `yaml:"Characters"`
+	Patterns   []string `yaml:"Patterns"`
+	Severity   string   `yaml:"Severity"`
+	Shadow     bool     `yaml:"Shadow"`
+}
+
+var ErrForbiddenCharsConfig = errors.New("invalid forbidden characters rule")
+
+func (f forbiddenCharsT) validate() error {
+	for _, pattern := range f.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("forbidden characters rule: %s: %w", err, ErrForbiddenCharsConfig)
+		}
+	}
+
+	if !validSeverity(f.Severity) {
+		return fmt.Errorf("forbidden characters rule: unknown severity '%s': %w", f.Severity, ErrForbiddenCharsConfig)
+	}
+
+	return nil
+}
+
+func (f forbiddenCharsT) severity() string {
+	if f.Severity == "" {
+		return severityError
+	}
+
+	return f.Severity
+}
+
+var ErrForbiddenChars = errors.New("forbidden characters in subject")
+
+// Check checks the raw subject, before the normalization of its spaces.
+func (f forbiddenCharsT) Check(subject string) error {
+	found := []string{}
+
+	for _, r := range subject {
+		if name := fmt.Sprintf("%q", r); strings.ContainsRune(f.Characters, r) && !containsString(found, name) {
+			found = append(found, name)
+		}
+	}
+
+	if len(found) > 0 {
+		return fmt.Errorf("subject contains the forbidden character(s) %s: %w", strings.Join(found, ", "),
+			ErrForbiddenChars)
+	}
+
+	for _, pattern := range f.Patterns {
+		if match := regexp.MustCompile(pattern).FindString(subject); match != "" { // validated when loading
+			return fmt.Errorf("subject contains the forbidden '%s' (matching '%s'): %w", match, pattern,
+				ErrForbiddenChars)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestForbiddenChars(t *testing.T) {
+	t.Parallel()
+
+	editors := forbiddenCharsT{Characters: "`‘’“”\t", Patterns: []string{`^\[[^\]]*\]`}}
+	custom := forbiddenCharsT{Characters: "#", Patterns: []string{`\bTODO\b`}}
+
+	tests := []struct {
+		name    string
+		rule    forbiddenCharsT
+		subject string
+		wantErr string
+	}{
+		{"off by default", forbiddenCharsT{}, "[PATCH v2] MINOR: cli: add the “show\tfd” `command`", ""},
+		{"plain", editors, "BUG/MINOR: mux: fix a crash on close", ""},
+		{"backticks", editors, "BUG/MINOR: mux: fix `h2_close()`", "subject contains the forbidden character(s) '`'"},
+		{
+			"smart quotes and tab", editors, "MINOR: cli: add the “show\tfd” command",
+			"subject contains the forbidden character(s) '“', '\\t', '”'",
+		},
+		{
+			"patch marker", editors, "[PATCH v2] MINOR: cli: add a command",
+			"subject contains the forbidden '[PATCH v2]' (matching '^\\[[^\\]]*\\]')",
+		},
+		{"custom allows the others", custom, "[RFC] MINOR: cli: add `show fd`", ""},
+		{"custom character", custom, "MINOR: cli: add #fd", "subject contains the forbidden character(s) '#'"},
+		{
+			"custom pattern", custom, "MINOR: cli: add a TODO command",
+			"subject contains the forbidden 'TODO' (matching '\\bTODO\\b')",
+		},
+	}
+
+	for _, tt := range tests {
+		err := tt.rule.Check(tt.subject)
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrForbiddenChars) ||
+			err.Error() != tt.wantErr+": "+ErrForbiddenChars.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := parseCommitPolicy("ForbiddenChars:\n  Patterns: ['[']\n"); !errors.Is(err, ErrForbiddenCharsConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrForbiddenCharsConfig)
+	}
+}
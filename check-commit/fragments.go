@@ -0,0 +1,225 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// policyFragmentsDir is the directory, next to the policy, of the fragments merged over
+// it. Readers are asked for its entries as policyFragmentsDir + "/", one per line.
+const policyFragmentsDir = ".check-commit.d"
+
+var ErrPolicyFragment = errors.New("invalid policy fragment")
+
+// policyFragments returns the files of the fragments directory in a supported format,
+// in lexical order.
+func policyFragments(read policyReaderFunc) ([]string, error) {
+	entries, err := read(policyFragmentsDir + "/")
+	if errors.Is(err, ErrPolicyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	fragments := []string{}
+
+	for _, entry := range strings.Split(entries, "\n") {
+		if entry != "" && containsString(policyExtensions, path.Ext(entry)) {
+			fragments = append(fragments, path.Join(policyFragmentsDir, entry))
+		}
+	}
+
+	sort.Strings(fragments)
+
+	return fragments, nil
+}
+
+// mergeFragment deep-merges the fragment over the policy: mappings are merged key by
+// key, the items of lists are appended unless already present, and any other value of
+// the fragment replaces the policy's.
+func mergeFragment(policy, fragment map[interface{}]interface{}) map[interface{}]interface{} {
+	merged := map[interface{}]interface{}{}
+
+	for key, value := range policy {
+		merged[key] = value
+	}
+
+	for key, value := range fragment {
+		switch child := value.(type) {
+		case map[interface{}]interface{}:
+			if parent, ok := merged[key].(map[interface{}]interface{}); ok {
+				value = mergeFragment(parent, child)
+			}
+		case []interface{}:
+			if parent, ok := merged[key].([]interface{}); ok {
+				value = appendMissing(parent, child)
+			}
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
+func appendMissing(list, items []interface{}) []interface{} {
+	merged := append([]interface{}{}, list...)
+
+	for _, item := range items {
+		found := false
+
+		for _, existing := range merged {
+			found = found || reflect.DeepEqual(existing, item)
+		}
+
+		if !found {
+			merged = append(merged, item)
+		}
+	}
+
+	return merged
+}
+
+// fragmentDigestsKey is the key of the policy listing the fragments with the SHA-256
+// digests of their content, by name in the fragments directory, as the signature of the
+// policy covers the set of fragments.
+const fragmentDigestsKey = "Fragments"
+
+// fragmentVerifier returns the function checking that a fragment has the digest the
+// signed policy lists for it, after checking that the fragments are exactly those it
+// lists, so that none can be removed, added or restored to an older version.
+func fragmentVerifier(listed interface{}, fragments []string) (func(name, content string) error, error) {
+	digests := map[string]string{}
+
+	entries, ok := listed.(map[interface{}]interface{})
+	if listed != nil && !ok {
+		return nil, fmt.Errorf("%s must map the fragments to their digests: %w", fragmentDigestsKey, ErrPolicySignature)
+	}
+
+	for name, digest := range entries {
+		digests[path.Join(policyFragmentsDir, fmt.Sprint(name))] = strings.ToLower(fmt.Sprint(digest))
+	}
+
+	for _, name := range fragments {
+		if _, ok := digests[name]; !ok {
+			return nil, fmt.Errorf("fragment %s is not listed by the signed policy: %w", name, ErrPolicySignature)
+		}
+	}
+
+	for name := range digests {
+		if !containsString(fragments, name) {
+			return nil, fmt.Errorf("fragment %s listed by the signed policy is missing: %w", name, ErrPolicySignature)
+		}
+	}
+
+	return func(name, content string) error {
+		if digest := sha256.Sum256([]byte(content)); hex.EncodeToString(digest[:]) != digests[name] {
+			return fmt.Errorf("fragment %s does not match its digest in the signed policy: %w", name,
+				ErrPolicySignature)
+		}
+
+		return nil
+	}, nil
+}
+
+// readPolicyFiles reads the policy, decoded to YAML, merged with the fragments of the
+// fragments directory in lexical order. Either may be missing, ErrPolicyNotFound being
+// returned when both are. The policy is passed to verify first, if given, and must
+// then list the fragments with their digests.
+func readPolicyFiles(read policyReaderFunc, verify func(name, content string) error) (string, error) {
+	config, err := read(policyFile)
+	if err != nil && !errors.Is(err, ErrPolicyNotFound) {
+		return "", err
+	}
+
+	fragments, fragmentsErr := policyFragments(read)
+
+	switch {
+	case fragmentsErr != nil:
+		return "", fragmentsErr
+	case err != nil && len(fragments) == 0:
+		return "", err
+	case err != nil && verify != nil:
+		return "", fmt.Errorf("fragments require a signed policy listing them: %s: %w", err, ErrPolicySignature)
+	case err == nil && verify != nil:
+		if err := verify(policyFile, config); err != nil {
+			return "", err
+		}
+	}
+
+	if config, err = decodePolicy(config); err != nil {
+		return "", err
+	}
+
+	document := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return "", fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	listed, hasDigests := document[fragmentDigestsKey]
+	if len(fragments) == 0 && !hasDigests {
+		return config, nil
+	}
+
+	delete(document, fragmentDigestsKey)
+
+	var verifyFragment func(name, content string) error
+
+	if verify != nil {
+		if verifyFragment, err = fragmentVerifier(listed, fragments); err != nil {
+			return "", err
+		}
+	}
+
+	for _, name := range fragments {
+		fragment, err := readPolicyFragment(read, verifyFragment, name)
+		if err != nil {
+			return "", err
+		}
+
+		document = mergeFragment(document, fragment)
+	}
+
+	log.Printf("policy merged with %d fragment(s) of %s", len(fragments), policyFragmentsDir)
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("error merging the policy fragments: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func readPolicyFragment(read policyReaderFunc, verify func(name, content string) error,
+	name string) (map[interface{}]interface{}, error) {
+	content, err := read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if verify != nil {
+		if err := verify(name, content); err != nil {
+			return nil, err
+		}
+	}
+
+	if content, err = decodePolicy(content); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", name, err, ErrPolicyFragment)
+	}
+
+	fragment := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(content), &fragment); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", name, err, ErrPolicyFragment)
+	}
+
+	return fragment, nil
+}
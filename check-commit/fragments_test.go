@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeFragment(t *testing.T) {
+	t.Parallel()
+
+	policy := map[interface{}]interface{}{
+		"MaxCommits":  10,
+		"PatchScopes": map[interface{}]interface{}{"severities": []interface{}{"MINOR", "MAJOR"}},
+		"Ignore":      []interface{}{"^Merge "},
+	}
+	fragment := map[interface{}]interface{}{
+		"MaxCommits": 20,
+		"PatchScopes": map[interface{}]interface{}{
+			"severities": []interface{}{"MAJOR", "CRITICAL"},
+			"areas":      []interface{}{"doc"},
+		},
+		"Ignore": "^Revert ",
+	}
+	want := map[interface{}]interface{}{
+		"MaxCommits": 20,
+		"PatchScopes": map[interface{}]interface{}{
+			"severities": []interface{}{"MINOR", "MAJOR", "CRITICAL"},
+			"areas":      []interface{}{"doc"},
+		},
+		"Ignore": "^Revert ",
+	}
+
+	if got := mergeFragment(policy, fragment); !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeFragment() = %v, want %v", got, want)
+	}
+}
+
+func TestReadPolicyFiles(t *testing.T) {
+	t.Parallel()
+
+	reader := func(files map[string]string) policyReaderFunc {
+		return func(name string) (string, error) {
+			if content, ok := files[name]; ok {
+				return content, nil
+			}
+
+			return "", ErrPolicyNotFound
+		}
+	}
+
+	fragments := map[string]string{
+		".check-commit.d/":             "20-local.yml\n10-base.json\nREADME.md",
+		".check-commit.d/10-base.json": `{"MaxCommits": 10, "ProtectedBranches": ["main"]}`,
+		".check-commit.d/20-local.yml": "MaxCommits: 5\nProtectedBranches: [release]\n",
+	}
+
+	c, err := parseCommitPolicy(mustReadPolicyFiles(t, reader(fragments)))
+	if err != nil || c.MaxCommits != 5 || !reflect.DeepEqual(c.ProtectedBranches, []string{"main", "release"}) {
+		t.Errorf("readPolicyFiles() fragments only = %+v, %v", c, err)
+	}
+
+	fragments[policyFile] = "MaxCommits: 1\nSubjectMaxLen: 60\n"
+
+	c, err = parseCommitPolicy(mustReadPolicyFiles(t, reader(fragments)))
+	if err != nil || c.MaxCommits != 5 || c.SubjectMaxLen != 60 {
+		t.Errorf("readPolicyFiles() = %+v, %v", c, err)
+	}
+
+	fragments[".check-commit.d/20-local.yml"] = "MaxCommits: [5\n"
+	if _, err := readPolicyFiles(reader(fragments), nil); !errors.Is(err, ErrPolicyFragment) {
+		t.Errorf("readPolicyFiles() invalid fragment error = %v, want %v", err, ErrPolicyFragment)
+	}
+
+	if _, err := readPolicyFiles(reader(map[string]string{}), nil); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("readPolicyFiles() without files error = %v, want %v", err, ErrPolicyNotFound)
+	}
+
+	signed := map[string]string{".check-commit.d/": "base.yml", ".check-commit.d/base.yml": "MaxCommits: 1\n"}
+	const key = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+
+	if _, err := loadPolicy(reader(signed), key, "", ""); !errors.Is(err, ErrPolicySignature) {
+		t.Errorf("loadPolicy() unsigned fragment error = %v, want %v", err, ErrPolicySignature)
+	}
+}
+
+func TestReadPolicyFilesSigned(t *testing.T) {
+	t.Parallel()
+
+	digest := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+
+		return hex.EncodeToString(sum[:])
+	}
+
+	base, local := "MaxCommits: 10\n", "MaxCommits: 5\n"
+	policy := "SubjectMaxLen: 60\nFragments:\n  10-base.yml: " + digest(base) + "\n  20-local.yml: " + digest(local) + "\n"
+	trusted := func(name, content string) error { return nil }
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr error
+	}{
+		{"listed", map[string]string{}, nil},
+		{"removed", map[string]string{".check-commit.d/": "10-base.yml"}, ErrPolicySignature},
+		{
+			"added", map[string]string{".check-commit.d/": "10-base.yml\n20-local.yml\n30-extra.yml",
+				".check-commit.d/30-extra.yml": base}, ErrPolicySignature,
+		},
+		{"restored", map[string]string{".check-commit.d/20-local.yml": base}, ErrPolicySignature},
+		{"unlisted", map[string]string{policyFile: "SubjectMaxLen: 60\n"}, ErrPolicySignature},
+		{"no policy", map[string]string{policyFile: ""}, ErrPolicySignature},
+	}
+
+	for _, tt := range tests {
+		files := map[string]string{
+			policyFile: policy, ".check-commit.d/": "10-base.yml\n20-local.yml",
+			".check-commit.d/10-base.yml": base, ".check-commit.d/20-local.yml": local,
+		}
+		for name, content := range tt.files {
+			files[name] = content
+		}
+
+		if files[policyFile] == "" {
+			delete(files, policyFile)
+		}
+
+		config, err := readPolicyFiles(func(name string) (string, error) {
+			if content, ok := files[name]; ok {
+				return content, nil
+			}
+
+			return "", ErrPolicyNotFound
+		}, trusted)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: readPolicyFiles() error = %v, want %v", tt.name, err, tt.wantErr)
+
+			continue
+		}
+
+		if err == nil {
+			if c, err := parseCommitPolicy(config); err != nil || c.MaxCommits != 5 || c.SubjectMaxLen != 60 {
+				t.Errorf("%s: readPolicyFiles() = %+v, %v", tt.name, c, err)
+			}
+		}
+	}
+}
+
+func mustReadPolicyFiles(t *testing.T, read policyReaderFunc) string {
+	t.Helper()
+
+	config, err := readPolicyFiles(read, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config
+}
+
+func TestLocalPolicyFragments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fragments := filepath.Join(dir, "ci", policyFragmentsDir)
+
+	if err := os.MkdirAll(filepath.Join(fragments, "nested.yml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{
+		"ci/policy.yml":                       "MaxCommits: 1\n",
+		"ci/" + policyFragmentsDir + "/a.yml": "MaxCommits: 2\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := renamedPolicyReader(localPolicyReader(dir), "ci/policy.yml")
+
+	names, err := policyFragments(read)
+	if err != nil || !reflect.DeepEqual(names, []string{".check-commit.d/a.yml"}) {
+		t.Errorf("policyFragments() = %v, %v", names, err)
+	}
+
+	c, err := loadPolicy(read, "", "", "")
+	if err != nil || c.MaxCommits != 2 {
+		t.Errorf("loadPolicy() = %+v, %v", c, err)
+	}
+}
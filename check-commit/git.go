@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrGitCommand = errors.New("git command failed")
+
+func runGit(repoPath string, args ...string) (string, error) {
+	return runGitInput(repoPath, "", args...)
+}
+
+// runGitInput runs git with the input on its standard input, for the commands
+// processing a batch of objects in a single invocation.
+func runGitInput(repoPath, input string, args ...string) (string, error) {
+	return runGitEnv(repoPath, nil, input, args...)
+}
+
+// runGitEnv runs git with additional environment variables.
+func runGitEnv(repoPath string, env []string, input string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	tracef("running %s", strings.Join(cmd.Args, " "))
+
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+
+		return "", fmt.Errorf("git %s: %s: %w", strings.Join(args, " "), msg, ErrGitCommand)
+	}
+
+	return string(out), nil
+}
+
+// inRepository tells whether the relative path of a file of the policy stays inside the
+// repository.
+func inRepository(name string) bool {
+	clean := path.Clean(name)
+
+	return !path.IsAbs(clean) && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+const (
+	gitFieldSeparator  = "\x1f"
+	gitRecordSeparator = "\x00"
+	gitCommitFormat    = "--format=%H%x1f%an%x1f%ae%x1f%B%x00"
+	gitCommitFields    = 4
+)
+
+func parseGitCommits(out string) []commitT {
+	commits := []commitT{}
+
+	for _, record := range strings.Split(out, gitRecordSeparator) {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), gitFieldSeparator, gitCommitFields)
+		if len(fields) < gitCommitFields {
+			continue
+		}
+
+		commits = append(commits, commitT{
+			SHA:     fields[0],
+			Author:  authorString(fields[1], fields[2]),
+			Message: strings.TrimRight(fields[3], "\n"),
+		})
+	}
+
+	return commits
+}
+
+// gitShowCommit reads a single commit without walking the history.
+func gitShowCommit(repoPath, rev string) (commitT, error) {
+	out, err := runGit(repoPath, "show", "-s", gitCommitFormat, rev)
+	if err != nil {
+		return commitT{}, err
+	}
+
+	commits := parseGitCommits(out)
+	if len(commits) != 1 {
+		return commitT{}, fmt.Errorf("git show %s: unexpected output: %w", rev, ErrGitCommand)
+	}
+
+	return commits[0], nil
+}
+
+func gitLogCommits(repoPath, revRange string) ([]commitT, error) {
+	out, err := runGit(repoPath, "log", gitCommitFormat, revRange)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseGitCommits(out), nil
+}
+
+// gitHasWorkTree tells whether the repository has checked out files: bare repositories
+// and git directories given with --git-dir do not, files are then read from HEAD.
+func gitHasWorkTree(repoPath string) bool {
+	out, err := runGit(repoPath, "rev-parse", "--is-inside-git-dir")
+
+	return err != nil || strings.TrimSpace(out) != "true"
+}
+
+// gitRepositoryKind describes the layout of the repository.
+func gitRepositoryKind(repoPath string) string {
+	if !gitHasWorkTree(repoPath) {
+		return "bare repository"
+	}
+
+	out, err := runGit(repoPath, "rev-parse", "--git-dir", "--git-common-dir")
+	if dirs := strings.Fields(out); err == nil && len(dirs) == 2 && dirs[0] != dirs[1] {
+		return "linked worktree"
+	}
+
+	return "work tree"
+}
+
+// gitRepositoryRoot returns the top-level directory of the work tree containing the
+// path, so that running from a subdirectory (working-directory: in workflows, or
+// monorepos) finds the configuration at the root. Other paths are returned unchanged.
+func gitRepositoryRoot(repoPath string) string {
+	if !gitHasWorkTree(repoPath) {
+		return repoPath
+	}
+
+	out, err := runGit(repoPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return repoPath
+	}
+
+	root := strings.TrimSpace(out)
+	if abs, err := filepath.Abs(repoPath); err == nil && abs != root {
+		log.Printf("using the repository root %s", root)
+	}
+
+	return root
+}
+
+// gitPathPrefix returns the path of the directory relative to the root of its work
+// tree, "" at the root or outside of a work tree.
+func gitPathPrefix(repoPath string) string {
+	if !gitHasWorkTree(repoPath) {
+		return ""
+	}
+
+	out, err := runGit(repoPath, "rev-parse", "--show-prefix")
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(strings.TrimSpace(out), "/")
+}
+
+// gitPromisorRemote returns the remote a partial clone (e.g. --filter=blob:none)
+// lazily fetches its missing objects from, or "" for complete clones.
+func gitPromisorRemote(repoPath string) string {
+	out, err := runGit(repoPath, "config", "--get-regexp", `^remote\..*\.promisor$`)
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == "true" {
+			return strings.TrimSuffix(strings.TrimPrefix(fields[0], "remote."), ".promisor")
+		}
+	}
+
+	return ""
+}
+
+// gitFetchObjects fetches objects missing from a partial clone in a single request,
+// rather than one at a time as git does when it lazily needs them. It never prompts
+// for credentials, so that it fails instead of stalling.
+func gitFetchObjects(repoPath, remote string, oids []string) error {
+	_, err := runGitEnv(repoPath, []string{"GIT_TERMINAL_PROMPT=0"}, strings.Join(oids, "\n")+"\n",
+		"-c", "fetch.negotiationAlgorithm=noop", "fetch", "--quiet", "--no-tags", "--no-write-fetch-head",
+		"--recurse-submodules=no", "--filter=blob:none", "--stdin", remote)
+
+	return err
+}
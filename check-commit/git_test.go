@@ -0,0 +1,164 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestRepo(t *testing.T, messages ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	gitArgs := [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Check Commit"},
+		{"config", "user.email", "check-commit@example.com"},
+	}
+	for _, message := range messages {
+		gitArgs = append(gitArgs, []string{"commit", "-q", "--allow-empty", "-m", message})
+	}
+
+	for _, args := range gitArgs {
+		if _, err := runGit(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// writeTestFiles writes the files, by name relative to dir unless absolute, creating
+// their directories.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		file := name
+		if !filepath.IsAbs(name) {
+			file = filepath.Join(dir, name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGitCommits(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t,
+		"MINOR: git: first commit of the test repository",
+		"BUG/MINOR: git: second commit\n\nwith a body",
+	)
+
+	commits, err := gitLogCommits(repo, "HEAD~1..HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf("gitLogCommits() returned %d commits, want 1", len(commits))
+	}
+
+	if commits[0].Subject() != "BUG/MINOR: git: second commit" || commits[0].Body() != "with a body" {
+		t.Errorf("gitLogCommits() = %+v", commits[0])
+	}
+
+	if want := "Check Commit <check-commit@example.com>"; commits[0].Author != want {
+		t.Errorf("gitLogCommits() author = %v, want %v", commits[0].Author, want)
+	}
+
+	commit, err := gitShowCommit(repo, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "MINOR: git: first commit of the test repository"; commit.Message != want {
+		t.Errorf("gitShowCommit() = %v, want %v", commit.Message, want)
+	}
+}
+
+func TestRepositoryLayouts(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+
+	if err := ioutil.WriteFile(filepath.Join(repo, policyFile), []byte("MaxCommits: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mirror := filepath.Join(t.TempDir(), "mirror.git")
+
+	for _, args := range [][]string{
+		{"add", policyFile},
+		{"commit", "-q", "-m", "MINOR: git: add the policy"},
+		{"worktree", "add", "-q", worktree},
+		{"clone", "-q", "--mirror", repo, mirror},
+	} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		kind string
+	}{
+		{repo, "work tree"},
+		{filepath.Join(repo, ".git"), "bare repository"},
+		{worktree, "linked worktree"},
+		{mirror, "bare repository"},
+	}
+
+	for _, tt := range tests {
+		if kind := gitRepositoryKind(tt.path); kind != tt.kind {
+			t.Errorf("gitRepositoryKind(%s) = %s, want %s", tt.path, kind, tt.kind)
+		}
+
+		if commits, err := gitLogCommits(tt.path, "HEAD"); err != nil || len(commits) != 1 {
+			t.Errorf("gitLogCommits(%s) = %v, %v", tt.path, commits, err)
+		}
+
+		commitPolicy, err := loadPolicy(localPolicyReader(tt.path), "", "", "")
+		if err != nil || commitPolicy.MaxCommits != 3 {
+			t.Errorf("loadPolicy(%s) = %+v, %v", tt.path, commitPolicy, err)
+		}
+	}
+}
+
+func TestGitRepositoryRoot(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "MINOR: git: first commit of the test repository")
+
+	subdir := filepath.Join(repo, "src", "mux")
+	if err := os.MkdirAll(subdir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := filepath.EvalSymlinks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := gitRepositoryRoot(subdir); got != root {
+		t.Errorf("gitRepositoryRoot(%s) = %s, want %s", subdir, got, root)
+	}
+
+	if got := gitRepositoryRoot(filepath.Join(repo, ".git")); got != filepath.Join(repo, ".git") {
+		t.Errorf("gitRepositoryRoot() of the git directory = %s", got)
+	}
+
+	outside := t.TempDir()
+	if got := gitRepositoryRoot(outside); got != outside {
+		t.Errorf("gitRepositoryRoot(%s) = %s, want it unchanged", outside, got)
+	}
+}
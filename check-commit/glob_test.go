@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.md", name: "README.md", want: true},
+		{pattern: "*.md", name: "doc/internals/api.md", want: true},
+		{pattern: "doc/*", name: "doc/intro.txt", want: true},
+		{pattern: "doc/*", name: "doc/internals/api.txt", want: false},
+		{pattern: "doc/**", name: "doc/internals/api.txt", want: true},
+		{pattern: "doc/**", name: "src/doc.c", want: false},
+		{pattern: "**/vendor/**", name: "vendor/lib/a.go", want: true},
+		{pattern: "**/vendor/**", name: "pkg/vendor/lib/a.go", want: true},
+		{pattern: "src/**/*.c", name: "src/mux_h2.c", want: true},
+		{pattern: "src/**/*.c", name: "src/mux/h2.h", want: false},
+		{pattern: "/Makefile", name: "Makefile", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%s, %s) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
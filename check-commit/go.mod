@@ -5,6 +5,8 @@ go 1.15
 require (
 	github.com/google/go-github/v35 v35.0.0
 	github.com/xanzy/go-gitlab v0.48.0
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 	golang.org/x/oauth2 v0.0.0-20210413134643-5e61552d6c78
+	golang.org/x/text v0.3.6
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ruleHelpT is the remediation shown for the findings of a rule: a text/template
+// Text, which can use the fields of helpDataT, and the URL of the documentation of
+// the rule.
+type ruleHelpT struct {
+	Text string `yaml:"Text"`
+	URL  string `yaml:"URL"`
+}
+
+// helpDataT is what the help templates can interpolate.
+type helpDataT struct {
+	Rule     string
+	Subject  string // of the offending commit, empty for findings about the request
+	Message  string // of the finding
+	Expected string // format the rule expects, when it can be described
+	URL      string
+}
+
+var ErrRuleHelp = errors.New("invalid rule help")
+
+func (c CommitPolicyConfig) validateRuleHelp() error {
+	rules := append(append([]string{}, builtinRules...), c.customRuleNames()...)
+
+	for rule, help := range c.RuleHelp {
+		if !containsString(rules, rule) {
+			return fmt.Errorf("RuleHelp: unknown rule '%s'%s: %w", rule, didYouMean(rule, rules), ErrRuleHelp)
+		}
+
+		tmpl, err := template.New(rule).Option("missingkey=error").Parse(help.Text)
+		if err == nil {
+			err = tmpl.Execute(&strings.Builder{}, helpDataT{})
+		}
+
+		if err != nil {
+			return fmt.Errorf("RuleHelp: rule '%s': %s: %w", rule, err, ErrRuleHelp)
+		}
+	}
+
+	return nil
+}
+
+// expectedFormat describes what the rule expects, when the policy tells.
+func (c CommitPolicyConfig) expectedFormat(rule string) string {
+	switch rule {
+	case ruleTag:
+		tags := []string{}
+
+		for _, alternative := range c.TagOrder {
+			for _, name := range alternative.PatchTypes {
+				for _, tag := range c.PatchTypes[name].Values {
+					if !containsString(tags, tag) {
+						tags = append(tags, tag)
+					}
+				}
+			}
+		}
+
+		if len(tags) > 0 {
+			return "a tag among " + abbreviateList(tags)
+		}
+	case ruleSubjectFormat:
+		minLen, maxLen, minWords, maxWords := c.subjectLimits()
+		expected := fmt.Sprintf("%d to %d characters and %d to %d words after the tags", minLen, maxLen, minWords,
+			maxWords)
+
+		values := make([]string, 0, len(c.TagMinWords))
+		for value := range c.TagMinWords {
+			values = append(values, value)
+		}
+
+		sort.Strings(values)
+
+		for i, value := range values {
+			values[i] = fmt.Sprintf("%d for %s", c.TagMinWords[value], value)
+		}
+
+		if len(values) > 0 {
+			expected += ", the fewest words being " + strings.Join(values, ", ")
+		}
+
+		return expected
+	case ruleComponent:
+		if len(c.Components.Values) > 0 {
+			return "a component among " + abbreviateList(c.Components.Values)
+		}
+
+		if c.Components.Regex != "" {
+			return "a component matching " + c.Components.Regex
+		}
+	case ruleForbiddenWords:
+		if words := append(append([]string{}, c.ForbiddenWords.Prefixes...), c.ForbiddenWords.Words...); len(words) > 0 {
+			return "none of " + abbreviateList(words)
+		}
+	}
+
+	for _, custom := range c.CustomRules {
+		if rule == ruleCustomPrefix+custom.Name {
+			return custom.Message
+		}
+	}
+
+	return ""
+}
+
+// ruleHelp returns the remediation of the finding from RuleHelp, or nothing when its
+// rule has none.
+func (c CommitPolicyConfig) ruleHelp(finding findingT) string {
+	help, ok := c.RuleHelp[finding.Rule]
+	if !ok {
+		return ""
+	}
+
+	data := helpDataT{
+		Rule:     finding.Rule,
+		Subject:  finding.Subject,
+		Message:  finding.Message,
+		Expected: c.expectedFormat(finding.Rule),
+		URL:      help.URL,
+	}
+
+	var b strings.Builder
+
+	if tmpl, err := template.New(finding.Rule).Parse(help.Text); err == nil { // validated when loading
+		_ = tmpl.Execute(&b, data)
+	}
+
+	text := strings.TrimSpace(b.String())
+
+	switch {
+	case help.URL == "" || strings.Contains(text, help.URL):
+		return text
+	case text == "":
+		return "see " + help.URL
+	}
+
+	return text + " (see " + help.URL + ")"
+}
+
+// helpLines returns the remediations of the errors of the report, each once, followed
+// by HelpText.
+func (c CommitPolicyConfig) helpLines(report reportT) []string {
+	lines := []string{}
+
+	for _, finding := range report.Findings {
+		if finding.Severity != severityError || finding.Shadow {
+			continue
+		}
+
+		if help := c.ruleHelp(finding); help != "" && !containsString(lines, help) {
+			lines = append(lines, help)
+		}
+	}
+
+	if c.HelpText != "" {
+		lines = append(lines, c.HelpText)
+	}
+
+	return lines
+}
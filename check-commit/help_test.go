@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const helpConf = defaultConf + `
+SubjectMaxLen: 60
+CustomRules:
+  - Name: no-wip
+    Regex: 'WIP'
+    Match: must-not
+    Message: "no work in progress"
+RuleHelp:
+  tag:
+    Text: "'{{.Subject}}' must start with {{.Expected}}, e.g. 'BUG/MINOR: config: fix the parsing'"
+    URL: https://example.com/tags
+  subject-format:
+    Text: "the subject must have {{.Expected}}"
+  custom:no-wip:
+    URL: https://example.com/wip
+`
+
+func TestRuleHelp(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(helpConf)
+	if err != nil {
+		t.Fatalf("parseCommitPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		finding findingT
+		want    string
+	}{
+		{findingT{Rule: ruleTag, Subject: "bug: fix it"}, "'bug: fix it' must start with a tag among BUG, BUILD, CLEANUP, " +
+			"DOC, LICENSE, OPTIM, RELEASE, REORG, TEST, REVERT and 4 more, e.g. 'BUG/MINOR: config: fix the parsing' " +
+			"(see https://example.com/tags)"},
+		{findingT{Rule: ruleSubjectFormat}, "the subject must have 15 to 60 characters and 3 to 15 words after the tags"},
+		{findingT{Rule: ruleCustomPrefix + "no-wip"}, "see https://example.com/wip"},
+		{findingT{Rule: ruleLanguage}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := c.ruleHelp(tt.finding); got != tt.want {
+			t.Errorf("ruleHelp(%s) = %q, want %q", tt.finding.Rule, got, tt.want)
+		}
+	}
+
+	report := reportT{Commits: []commitT{
+		{SHA: "1111111111", Message: "bug: fix it"},
+		{SHA: "2222222222", Message: "MINOR: config: WIP"},
+		{SHA: "3333333333", Message: "MINOR: config: WIP on the parsing"},
+	}}
+	c.checkCommits(report.Commits, &report)
+
+	want := []string{
+		"'bug: fix it' must start with a tag among BUG, BUILD, CLEANUP, DOC, LICENSE, OPTIM, RELEASE, REORG, TEST, " +
+			"REVERT and 4 more, e.g. 'BUG/MINOR: config: fix the parsing' (see https://example.com/tags)",
+		"the subject must have 15 to 60 characters and 3 to 15 words after the tags",
+		"see https://example.com/wip",
+		"Please refer to https://github.com/haproxy/haproxy/blob/master/CONTRIBUTING#L632",
+	}
+
+	if got := c.helpLines(report); !reflect.DeepEqual(got, want) {
+		t.Errorf("helpLines() = %q, want %q", got, want)
+	}
+
+	comment := "  - rule 'no-wip': no work in progress: custom rule violated\n    see https://example.com/wip\n"
+	if body := c.fixInstructions(report, "3333333333", ""); !strings.Contains(body, comment) {
+		t.Errorf("fixInstructions() lacks the help of the rule:\n%s", body)
+	}
+}
+
+func TestValidateRuleHelp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"unknown rule", "RuleHelp:\n  tags:\n    Text: x\n", "unknown rule 'tags', did you mean 'tag'?"},
+		{"invalid template", "RuleHelp:\n  tag:\n    Text: '{{.Subject'\n", "rule 'tag': template: "},
+		{"unknown field", "RuleHelp:\n  tag:\n    Text: '{{.Commit}}'\n", "can't evaluate field Commit"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseCommitPolicy(defaultConf + tt.config)
+			if !errors.Is(err, ErrRuleHelp) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseCommitPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
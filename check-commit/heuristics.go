@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type diffHeuristicT struct {
+	Tags            []string `yaml:"Tags"`
+	MaxChangedLines int      `yaml:"MaxChangedLines"`
+	IgnoreLines     []string `yaml:"IgnoreLines"`
+	CommentPrefixes []string `yaml:"CommentPrefixes"`
+	Severity        string   `yaml:"Severity"`
+	Shadow          bool     `yaml:"Shadow"`
+}
+
+type diffHeuristicsT struct {
+	Reorg   *diffHeuristicT `yaml:"Reorg"`
+	Cleanup *diffHeuristicT `yaml:"Cleanup"`
+}
+
+var ErrDiffHeuristicConfig = errors.New("invalid diff heuristic")
+
+func (h *diffHeuristicT) validate(name string) error {
+	if h == nil {
+		return nil
+	}
+
+	for _, pattern := range h.IgnoreLines {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s heuristic: %s: %w", name, err, ErrDiffHeuristicConfig)
+		}
+	}
+
+	if !validSeverity(h.Severity) {
+		return fmt.Errorf("%s heuristic: unknown severity '%s': %w", name, h.Severity, ErrDiffHeuristicConfig)
+	}
+
+	return nil
+}
+
+func (h diffHeuristicsT) validate() error {
+	if err := h.Reorg.validate("Reorg"); err != nil {
+		return err
+	}
+
+	return h.Cleanup.validate("Cleanup")
+}
+
+// severity defaults to warning: heuristics guess, they should only fail the run on request.
+func (h *diffHeuristicT) severity() string {
+	if h.Severity == "" {
+		return severityWarning
+	}
+
+	return h.Severity
+}
+
+func (h *diffHeuristicT) appliesTo(commit commitT, defaultTags []string) bool {
+	if h == nil {
+		return false
+	}
+
+	tags := h.Tags
+	if len(tags) == 0 {
+		tags = defaultTags
+	}
+
+	return hasAnyValue(commit.Tags(), tags)
+}
+
+// relevantLines drops blank lines and the lines matching IgnoreLines, keeping them
+// trimmed so indentation changes of moved code do not count.
+func (h *diffHeuristicT) relevantLines(lines []string) []string {
+	ignore := make([]*regexp.Regexp, 0, len(h.IgnoreLines))
+	for _, pattern := range h.IgnoreLines {
+		ignore = append(ignore, regexp.MustCompile(pattern)) // validated when loading the configuration
+	}
+
+	relevant := []string{}
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		ignored := false
+
+		for _, r := range ignore {
+			if r.MatchString(line) {
+				ignored = true
+
+				break
+			}
+		}
+
+		if !ignored {
+			relevant = append(relevant, line)
+		}
+	}
+
+	return relevant
+}
+
+// unmovedLines returns the added and removed lines that have no counterpart on the
+// other side anywhere in the commit, i.e. the lines that were not simply moved.
+func unmovedLines(added, removed []string) ([]string, []string) {
+	pending := map[string]int{}
+	for _, line := range removed {
+		pending[line]++
+	}
+
+	unmatchedAdded := []string{}
+
+	for _, line := range added {
+		if pending[line] > 0 {
+			pending[line]--
+
+			continue
+		}
+
+		unmatchedAdded = append(unmatchedAdded, line)
+	}
+
+	unmatchedRemoved := []string{}
+
+	for _, line := range removed {
+		if pending[line] > 0 {
+			pending[line]--
+
+			unmatchedRemoved = append(unmatchedRemoved, line)
+		}
+	}
+
+	return unmatchedAdded, unmatchedRemoved
+}
+
+var ErrReorgNotPure = errors.New("reorganization changes code")
+
+func (h *diffHeuristicT) checkReorg(files []fileDiffT) error {
+	added := []string{}
+	removed := []string{}
+
+	for _, file := range files {
+		added = append(added, h.relevantLines(file.Added)...)
+		removed = append(removed, h.relevantLines(file.Removed)...)
+	}
+
+	unmatchedAdded, unmatchedRemoved := unmovedLines(added, removed)
+	if changed := len(unmatchedAdded) + len(unmatchedRemoved); changed > h.MaxChangedLines {
+		example := append(unmatchedAdded, unmatchedRemoved...)[0]
+
+		return fmt.Errorf("%d added and %d removed line(s) are not moves or renames (e.g. '%s'), "+
+			"functional changes belong to a separate commit: %w",
+			len(unmatchedAdded), len(unmatchedRemoved), example, ErrReorgNotPure)
+	}
+
+	return nil
+}
+
+func (h *diffHeuristicT) isComment(line string) bool {
+	prefixes := h.CommentPrefixes
+	if len(prefixes) == 0 {
+		prefixes = []string{"//", "/*", "*/", "* "}
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(line, prefix) || line == strings.TrimSpace(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func codeKeywords() map[string]bool {
+	keywords := map[string]bool{}
+	for _, keyword := range strings.Fields("if else for while do switch case default break continue goto " +
+		"return func var const struct static int char void unsigned long size_t def class import " +
+		"true false null nil NULL") {
+		keywords[keyword] = true
+	}
+
+	return keywords
+}
+
+// codeShape reduces a line of code to its structure: whitespace is dropped and every
+// identifier except keywords becomes "_", so renaming a variable keeps the shape.
+func codeShape(line string) string {
+	keywords := codeKeywords()
+	r := regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+	shape := r.ReplaceAllStringFunc(line, func(identifier string) string {
+		if keywords[identifier] {
+			return identifier
+		}
+
+		return "_"
+	})
+
+	return strings.Join(strings.Fields(shape), "")
+}
+
+var ErrCleanupChangesLogic = errors.New("cleanup changes logic")
+
+// checkCleanup accepts whitespace, comment, rename and removal-only changes: every
+// added line of code must have the shape of a removed one.
+func (h *diffHeuristicT) checkCleanup(files []fileDiffT) error {
+	pending := map[string]int{}
+	added := []string{}
+
+	for _, file := range files {
+		for _, line := range h.relevantLines(file.Removed) {
+			if !h.isComment(line) {
+				pending[codeShape(line)]++
+			}
+		}
+
+		for _, line := range h.relevantLines(file.Added) {
+			if !h.isComment(line) {
+				added = append(added, line)
+			}
+		}
+	}
+
+	changed := []string{}
+
+	for _, line := range added {
+		shape := codeShape(line)
+		if pending[shape] > 0 {
+			pending[shape]--
+
+			continue
+		}
+
+		changed = append(changed, line)
+	}
+
+	if len(changed) > h.MaxChangedLines {
+		return fmt.Errorf("%d added line(s) look like logic changes (e.g. '%s'), "+
+			"a cleanup should only touch whitespace, comments, names or remove dead code: %w",
+			len(changed), changed[0], ErrCleanupChangesLogic)
+	}
+
+	return nil
+}
+
+// defaultDocumentationPaths are the documentation files of DiffCrossCheck, when the
+// Documentation rule sets no Paths.
+var defaultDocumentationPaths = []string{"doc/**", "docs/**", "man/**", "README*", "*.md", "*.rst", "*.adoc"}
+
+// withDiffCrossCheck returns the policy with the checks of the diffs against the tags
+// that it leaves unconfigured enabled by DiffCrossCheck, with their defaults: the
+// Documentation paths and the Reorg and Cleanup heuristics.
+func (c CommitPolicyConfig) withDiffCrossCheck() CommitPolicyConfig {
+	if !c.DiffCrossCheck {
+		return c
+	}
+
+	if !c.Documentation.enabled() {
+		c.Documentation.Paths = defaultDocumentationPaths
+	}
+
+	if c.DiffHeuristics.Reorg == nil {
+		c.DiffHeuristics.Reorg = &diffHeuristicT{}
+	}
+
+	if c.DiffHeuristics.Cleanup == nil {
+		c.DiffHeuristics.Cleanup = &diffHeuristicT{}
+	}
+
+	return c
+}
+
+func (c CommitPolicyConfig) needsDiff(commit commitT) bool {
+	return c.Documentation.enabled() || c.CommitSize.appliesTo(commit) || len(c.SensitivePaths) > 0 ||
+		len(c.PathRules) > 0 || c.VersionFile.enabled() ||
+		c.DiffHeuristics.Reorg.appliesTo(commit, []string{"REORG"}) ||
+		c.DiffHeuristics.Cleanup.appliesTo(commit, []string{"CLEANUP"})
+}
+
+func (c CommitPolicyConfig) checkDiffHeuristics(commit commitT, report *reportT) {
+	if !commit.HasDiff || commit.NamesOnly {
+		return
+	}
+
+	if h := c.DiffHeuristics.Reorg; h.appliesTo(commit, []string{"REORG"}) {
+		report.AddCommitFinding(ruleReorgPurity, h.severity(), h.Shadow, commit, h.checkReorg(commit.Files))
+	}
+
+	if h := c.DiffHeuristics.Cleanup; h.appliesTo(commit, []string{"CLEANUP"}) {
+		report.AddCommitFinding(ruleCleanupNeutrality, h.severity(), h.Shadow, commit, h.checkCleanup(commit.Files))
+	}
+}
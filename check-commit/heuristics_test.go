@@ -0,0 +1,183 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckReorg(t *testing.T) {
+	t.Parallel()
+
+	h := &diffHeuristicT{IgnoreLines: []string{`^#include `}}
+
+	tests := []struct {
+		name    string
+		files   []fileDiffT
+		wantErr bool
+	}{
+		{
+			name:    "pure rename",
+			files:   []fileDiffT{{Path: "b.c", OldPath: "a.c", Status: fileRenamed}},
+			wantErr: false,
+		},
+		{
+			name: "function moved across files with new includes and indentation",
+			files: []fileDiffT{
+				{Path: "a.c", Removed: []string{"int f(void)", "{", "\treturn 1;", "}"}},
+				{Path: "b.c", Added: []string{"#include <a.h>", "", "int f(void)", "{", "    return 1;", "}"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "moved and modified",
+			files: []fileDiffT{
+				{Path: "a.c", Removed: []string{"int f(void)", "{", "\treturn 1;", "}"}},
+				{Path: "b.c", Added: []string{"int f(void)", "{", "\treturn 2;", "}"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "code removed",
+			files:   []fileDiffT{{Path: "a.c", Removed: []string{"\tcleanup();"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := h.checkReorg(tt.files); (err != nil) != tt.wantErr {
+				t.Errorf("checkReorg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckDiffHeuristics(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{DiffHeuristics: diffHeuristicsT{Reorg: &diffHeuristicT{}}}
+	files := []fileDiffT{{Path: "a.c", Added: []string{"new_call();"}}}
+
+	tests := []struct {
+		name         string
+		commit       commitT
+		wantFindings int
+	}{
+		{name: "reorg", commit: commitT{Message: "REORG: src: move code", Files: files, HasDiff: true}, wantFindings: 1},
+		{name: "other tag", commit: commitT{Message: "MINOR: src: add call", Files: files, HasDiff: true}},
+		{name: "diff unavailable", commit: commitT{Message: "REORG: src: move code"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			report := reportT{}
+			c.checkDiffHeuristics(tt.commit, &report)
+			if len(report.Findings) != tt.wantFindings {
+				t.Fatalf("checkDiffHeuristics() findings = %+v, want %d", report.Findings, tt.wantFindings)
+			}
+			if tt.wantFindings > 0 && report.Findings[0].Severity != severityWarning {
+				t.Errorf("checkDiffHeuristics() severity = %s, want %s", report.Findings[0].Severity, severityWarning)
+			}
+		})
+	}
+}
+
+func TestCheckCleanup(t *testing.T) {
+	t.Parallel()
+
+	h := &diffHeuristicT{}
+
+	tests := []struct {
+		name    string
+		files   []fileDiffT
+		wantErr bool
+	}{
+		{
+			name:    "whitespace only",
+			files:   []fileDiffT{{Path: "a.c", Removed: []string{"if (x)  return 1;"}, Added: []string{"\tif (x) return 1;"}}},
+			wantErr: false,
+		},
+		{
+			name:    "comments",
+			files:   []fileDiffT{{Path: "a.c", Removed: []string{"/* old comment */"}, Added: []string{"// new comment", " * more", " *", " */"}}},
+			wantErr: false,
+		},
+		{
+			name:    "variable rename",
+			files:   []fileDiffT{{Path: "a.c", Removed: []string{"int tmp = get(conn);"}, Added: []string{"int len = get(conn);"}}},
+			wantErr: false,
+		},
+		{
+			name:    "dead code removal",
+			files:   []fileDiffT{{Path: "a.c", Removed: []string{"if (0)", "\tdebug();"}}},
+			wantErr: false,
+		},
+		{
+			name:    "logic change",
+			files:   []fileDiffT{{Path: "a.c", Removed: []string{"if (x)"}, Added: []string{"while (x)"}}},
+			wantErr: true,
+		},
+		{
+			name:    "pointer dereference is not a comment",
+			files:   []fileDiffT{{Path: "a.c", Added: []string{"*p = 0;"}}},
+			wantErr: true,
+		},
+		{
+			name:    "new call",
+			files:   []fileDiffT{{Path: "a.c", Added: []string{"free(p);"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := h.checkCleanup(tt.files); (err != nil) != tt.wantErr {
+				t.Errorf("checkCleanup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiffCrossCheck(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy("DiffCrossCheck: true\nDocumentation:\n  Tags: [DOC]\nDiffHeuristics:\n" +
+		"  Cleanup:\n    Severity: error\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(c.Documentation.Paths, defaultDocumentationPaths) || c.DiffHeuristics.Reorg == nil ||
+		c.DiffHeuristics.Cleanup.severity() != severityError {
+		t.Errorf("withDiffCrossCheck() = %+v, %+v", c.Documentation, c.DiffHeuristics)
+	}
+
+	commits := []commitT{
+		{SHA: "0123456789abcdef", Message: "DOC: config: clarify timeouts", HasDiff: true,
+			Files: []fileDiffT{{Path: "src/cfgparse.c", Added: []string{"a = 1;"}}}},
+		{SHA: "1123456789abcdef", Message: "CLEANUP: config: remove blank lines", HasDiff: true,
+			Files: []fileDiffT{{Path: "src/cfgparse.c", Added: []string{"if (a) b();"}}}},
+	}
+
+	report := c.newReport(commits)
+	c.checkCommits(commits, &report)
+
+	rules := []string{}
+	for _, finding := range report.Findings {
+		rules = append(rules, finding.Rule)
+	}
+
+	if want := []string{ruleDocumentation, ruleCleanupNeutrality}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("checkCommits() rules = %v, want %v", rules, want)
+	}
+
+	if c, _ = parseCommitPolicy("DiffHeuristics: {}\n"); c.Documentation.enabled() || c.DiffHeuristics.Reorg != nil {
+		t.Errorf("parseCommitPolicy() without DiffCrossCheck = %+v, %+v", c.Documentation, c.DiffHeuristics)
+	}
+}
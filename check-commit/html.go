@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>check-commit report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; cursor: pointer; }
+.error { color: #b00; }
+.warning { color: #a60; }
+code { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>check-commit report</h1>
+<p>{{.Commits}} commit(s){{with .Shards}} in shard(s) {{join . ", "}}{{end}}:
+<span class="error">{{.Errors}} error(s)</span>, <span class="warning">{{.Warnings}} warning(s)</span>.</p>
+{{with .RewordScript}}<p>The suggested subjects can be applied at once by running <code>sh {{.}}</code> from the branch.</p>{{end}}
+{{if .Rules}}
+<h2>Rules</h2>
+<table class="sortable">
+<thead><tr><th>Rule</th><th>Errors</th><th>Warnings</th></tr></thead>
+<tbody>
+{{range .Rules}}<tr><td><a href="#rule-{{.Name}}">{{.Name}}</a></td><td>{{.Errors}}</td><td>{{.Warnings}}</td></tr>
+{{end}}</tbody>
+</table>
+{{range .Rules}}
+<h2 id="rule-{{.Name}}">{{.Name}}</h2>
+<table class="sortable">
+<thead><tr><th>Severity</th><th>Commit</th><th>Subject</th><th>Message</th></tr></thead>
+<tbody>
+{{range .Findings}}<tr><td class="{{.Severity}}">{{if .Shadow}}shadow {{end}}{{.Severity}}</td>
+<td>{{if .SHA}}{{if $.CommitURL}}<a href="{{$.CommitURL}}{{.SHA}}"><code>{{short .SHA}}</code></a>{{else}}<code>{{short .SHA}}</code>{{end}}{{end}}</td>
+<td><code>{{.Subject}}</code></td><td>{{.Message}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+{{else}}
+<p>No findings.</p>
+{{end}}
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table"), body = table.tBodies[0], column = th.cellIndex;
+    var asc = th.dataset.order !== "asc";
+    var rows = Array.prototype.slice.call(body.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[column].textContent, y = b.cells[column].textContent;
+      var c = isNaN(x) || isNaN(y) ? x.localeCompare(y) : x - y;
+      return asc ? c : -c;
+    });
+    rows.forEach(function (row) { body.appendChild(row); });
+    th.dataset.order = asc ? "asc" : "desc";
+  });
+});
+</script>
+</body>
+</html>
+`
+
+type htmlRuleT struct {
+	Name     string
+	Errors   int
+	Warnings int
+	Findings []findingT
+}
+
+// groupByRule returns the findings grouped by rule, rules sorted by name.
+func groupByRule(findings []findingT) []htmlRuleT {
+	rules := map[string]*htmlRuleT{}
+	names := []string{}
+
+	for _, finding := range findings {
+		rule, ok := rules[finding.Rule]
+		if !ok {
+			rule = &htmlRuleT{Name: finding.Rule}
+			rules[finding.Rule] = rule
+			names = append(names, finding.Rule)
+		}
+
+		if finding.Severity == severityError {
+			rule.Errors++
+		} else {
+			rule.Warnings++
+		}
+
+		rule.Findings = append(rule.Findings, finding)
+	}
+
+	sort.Strings(names)
+
+	grouped := make([]htmlRuleT, 0, len(names))
+	for _, name := range names {
+		grouped = append(grouped, *rules[name])
+	}
+
+	return grouped
+}
+
+// writeHTMLReport writes a standalone HTML page of the report. Commits link to
+// commitURL followed by their SHA, when commitURL is not empty.
+func writeHTMLReport(w io.Writer, report jsonReportT, commitURL string) error {
+	t := template.Must(template.New("report").Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"short": shortSHA,
+	}).Parse(htmlReportTemplate))
+
+	err := t.Execute(w, struct {
+		jsonReportT
+		Rules     []htmlRuleT
+		CommitURL string
+	}{report, groupByRule(report.Findings), commitURL})
+	if err != nil {
+		return fmt.Errorf("error writing HTML report: %w", err)
+	}
+
+	return nil
+}
+
+// commitURLPrefix returns the URL commits are browsed at on the forge, followed by a
+// SHA. Outside of CI it is derived from the origin remote of the clone.
+func commitURLPrefix(repoEnv, repoPath string) string {
+	switch repoEnv {
+	case GITHUB:
+		if server, repo := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"); server != "" && repo != "" {
+			return server + "/" + repo + "/commit/"
+		}
+	case GITLAB:
+		if project := os.Getenv("CI_PROJECT_URL"); project != "" {
+			return project + "/-/commit/"
+		}
+	}
+
+	remote, err := runGit(repoPath, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+
+	return remoteWebURL(strings.TrimSpace(remote)) + "/commit/"
+}
+
+// remoteWebURL converts the usual git remote forms (scp-like, ssh:// and https) to the
+// web URL of the project.
+func remoteWebURL(remote string) string {
+	remote = strings.TrimSuffix(remote, ".git")
+
+	if m := regexp.MustCompile(`^(?:ssh://)?[^@/]+@([^:/]+)[:/](.+)$`).FindStringSubmatch(remote); m != nil {
+		return "https://" + m[1] + "/" + m[2]
+	}
+
+	return regexp.MustCompile(`^(https?://)[^@/]+@`).ReplaceAllString(remote, "$1")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	t.Parallel()
+
+	report := jsonReportT{
+		Commits: 3,
+		Errors:  2,
+		Findings: []findingT{
+			{Rule: ruleTag, Severity: severityError, SHA: "1111111111", Subject: "bug: <fix>", Message: "unknown tag"},
+			{Rule: ruleMaxCommits, Severity: severityError, Message: "too many commits"},
+			{Rule: ruleTag, Severity: severityWarning, SHA: "2222222222", Subject: "MINOR: x", Message: "tag"},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := writeHTMLReport(&out, report, "https://github.com/haproxy/haproxy/commit/"); err != nil {
+		t.Fatal(err)
+	}
+
+	html := out.String()
+	for _, want := range []string{
+		`<a href="#rule-max-commits">max-commits</a></td><td>1</td><td>0</td>`,
+		`<a href="#rule-tag">tag</a></td><td>1</td><td>1</td>`,
+		`<a href="https://github.com/haproxy/haproxy/commit/1111111111"><code>11111111</code></a>`,
+		`<code>bug: &lt;fix&gt;</code>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("writeHTMLReport() does not contain %s", want)
+		}
+	}
+
+	if strings.Index(html, `id="rule-max-commits"`) > strings.Index(html, `id="rule-tag"`) {
+		t.Errorf("writeHTMLReport() rules are not sorted")
+	}
+}
+
+func TestRemoteWebURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{remote: "git@github.com:haproxy/haproxy.git", want: "https://github.com/haproxy/haproxy"},
+		{remote: "ssh://git@gitlab.com/haproxy/haproxy.git", want: "https://gitlab.com/haproxy/haproxy"},
+		{remote: "https://github.com/haproxy/haproxy.git", want: "https://github.com/haproxy/haproxy"},
+		{remote: "https://token@github.com/haproxy/haproxy", want: "https://github.com/haproxy/haproxy"},
+	}
+
+	for _, tt := range tests {
+		if got := remoteWebURL(tt.remote); got != tt.want {
+			t.Errorf("remoteWebURL(%s) = %s, want %s", tt.remote, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// cachedResponseT is an API response stored in the cache directory.
+type cachedResponseT struct {
+	URL    string
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// cachingTransportT caches the GET responses of the forge APIs on disk. Responses for a
+// commit itself are immutable and served without any request, others are revalidated
+// with their ETag, and 304 replies do not count against the GitHub rate limit.
+type cachingTransportT struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// cacheDir returns the API cache directory, empty when caching is disabled.
+func cacheDir() string {
+	return os.Getenv("CHECK_COMMIT_CACHE_DIR")
+}
+
+// cachingHTTPClient returns a client caching in the cache directory, or nil when
+// caching is disabled so that callers keep their default client.
+func cachingHTTPClient() *http.Client {
+	dir := cacheDir()
+	if dir == "" {
+		return nil
+	}
+
+	const cacheDirMode = 0o755
+	if err := os.MkdirAll(dir, cacheDirMode); err != nil {
+		log.Printf("warning: API responses won't be cached: %s", err)
+
+		return nil
+	}
+
+	return &http.Client{Transport: &cachingTransportT{dir: dir, next: http.DefaultTransport}}
+}
+
+// immutableURLRegexp matches the API paths of a commit itself, unlike those of its
+// statuses, check runs or comments which change over time.
+var immutableURLRegexp = regexp.MustCompile(`/commits/[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+func isImmutableURL(path string) bool {
+	return immutableURLRegexp.MatchString(path)
+}
+
+// cacheKey identifies a response by request and credentials, so that responses are
+// never shared between tokens.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{req.Method, req.URL.String(), req.Header.Get("Authorization"),
+		req.Header.Get("Private-Token"), req.Header.Get("Accept")} {
+		h.Write([]byte(part + "\n"))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (t *cachingTransportT) load(key string) (cachedResponseT, bool) {
+	var cached cachedResponseT
+
+	data, err := ioutil.ReadFile(filepath.Join(t.dir, key+".json"))
+	if err != nil {
+		return cached, false
+	}
+
+	return cached, json.Unmarshal(data, &cached) == nil
+}
+
+func (t *cachingTransportT) store(key string, cached cachedResponseT) {
+	data, err := json.Marshal(cached)
+	if err == nil {
+		const cacheFileMode = 0o600
+		err = ioutil.WriteFile(filepath.Join(t.dir, key+".json"), data, cacheFileMode)
+	}
+
+	if err != nil {
+		log.Printf("warning: unable to cache API response: %s", err)
+	}
+}
+
+func (c cachedResponseT) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(c.Status),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+func (t *cachingTransportT) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	cached, ok := t.load(key)
+
+	if ok && isImmutableURL(req.URL.Path) {
+		return cached.response(req), nil
+	}
+
+	if etag := cached.Header.Get("Etag"); ok && etag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		resp.Body.Close()
+
+		return cached.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if resp.Header.Get("Etag") != "" || isImmutableURL(req.URL.Path) {
+		t.store(key, cachedResponseT{URL: req.URL.String(), Status: resp.StatusCode, Header: resp.Header, Body: body})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCachingTransport(t *testing.T) {
+	t.Parallel()
+
+	requests := map[string]int{}
+	revalidated := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+
+		if r.URL.Path == "/repos/o/p/pulls/1/commits" {
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidated++
+
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+
+			w.Header().Set("Etag", `"v1"`)
+		}
+
+		_, _ = w.Write([]byte("body of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &cachingTransportT{dir: t.TempDir(), next: http.DefaultTransport}}
+	paths := []string{
+		"/repos/o/p/pulls/1/commits",
+		"/repos/o/p/commits/0123456789abcdef0123456789abcdef01234567",
+		"/repos/o/p/pulls/1/reviews",
+	}
+
+	for i := 0; i < 3; i++ {
+		for _, path := range paths {
+			resp, err := client.Get(server.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK || string(body) != "body of "+path {
+				t.Errorf("GET %s = %d '%s'", path, resp.StatusCode, body)
+			}
+		}
+	}
+
+	if requests[paths[0]] != 3 || revalidated != 2 {
+		t.Errorf("ETag response: %d requests, %d revalidated, want 3 and 2", requests[paths[0]], revalidated)
+	}
+
+	if requests[paths[1]] != 1 {
+		t.Errorf("immutable response: %d requests, want 1", requests[paths[1]])
+	}
+
+	if requests[paths[2]] != 3 {
+		t.Errorf("uncacheable response: %d requests, want 3", requests[paths[2]])
+	}
+}
+
+func TestIsImmutableURL(t *testing.T) {
+	t.Parallel()
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	for path, want := range map[string]bool{
+		"/repos/o/p/commits/" + sha:                              true,
+		"/api/v4/projects/1/repository/commits/" + sha:           true,
+		"/repos/o/p/commits/" + sha + "0123456789abcdef01234567": true,
+		"/repos/o/p/commits/" + sha + "/status":                  false,
+		"/repos/o/p/commits/" + sha + "/check-runs":              false,
+		"/repos/o/p/commits/" + sha + "/comments":                false,
+		"/repos/o/p/commits/master":                              false,
+		"/repos/o/p/pulls/1/commits":                             false,
+	} {
+		if got := isImmutableURL(path); got != want {
+			t.Errorf("isImmutableURL(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
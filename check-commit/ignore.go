@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	exceptionIgnored = "ignored-commit"
+	minIgnoredSHALen = 7
+)
+
+// ignoreT designates the commits that are not checked at all, such as merges or those
+// of bots: by subject regexp, by author, given as a name, an email or a shell pattern
+// of either, or by SHA, possibly abbreviated.
+type ignoreT struct {
+	Subjects []string `yaml:"Subjects"`
+	Authors  []string `yaml:"Authors"`
+	SHAs     []string `yaml:"SHAs"`
+}
+
+var shaRegexp = regexp.MustCompile(`^[0-9a-f]+$`)
+
+var ErrIgnoreConfig = errors.New("invalid ignore patterns")
+
+func (i ignoreT) validate() error {
+	for _, pattern := range i.Subjects {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("ignored subject '%s': %s: %w", pattern, err, ErrIgnoreConfig)
+		}
+	}
+
+	for _, pattern := range i.Authors {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("ignored author '%s': %s: %w", pattern, err, ErrIgnoreConfig)
+		}
+	}
+
+	for _, sha := range i.SHAs {
+		if len(sha) < minIgnoredSHALen || !shaRegexp.MatchString(sha) {
+			return fmt.Errorf("ignored SHA '%s' is not a hexadecimal SHA of at least %d digits: %w", sha,
+				minIgnoredSHALen, ErrIgnoreConfig)
+		}
+	}
+
+	return nil
+}
+
+// authorIdentities returns the forms of the author a pattern can match: the whole
+// "Name <email>", the name and the email.
+func authorIdentities(author string) []string {
+	i := strings.LastIndex(author, " <")
+	if i < 0 || !strings.HasSuffix(author, ">") {
+		return []string{author}
+	}
+
+	return []string{author, author[:i], author[i+2 : len(author)-1]}
+}
+
+// reason tells why the commit is ignored, or returns an empty string.
+func (i ignoreT) reason(commit commitT) string {
+	for _, sha := range i.SHAs {
+		if strings.HasPrefix(commit.SHA, sha) {
+			return fmt.Sprintf("SHA %s is ignored", sha)
+		}
+	}
+
+	for _, pattern := range i.Subjects {
+		if regexp.MustCompile(pattern).MatchString(commit.Subject()) { // validated when loading
+			return fmt.Sprintf("subject matches ignored pattern '%s'", pattern)
+		}
+	}
+
+	for _, pattern := range i.Authors {
+		for _, identity := range authorIdentities(commit.Author) {
+			// literal names such as dependabot[bot] are not patterns matching themselves
+			if matched, _ := path.Match(pattern, identity); matched || identity == pattern {
+				return fmt.Sprintf("author matches ignored pattern '%s'", pattern)
+			}
+		}
+	}
+
+	return ""
+}
+
+// filter returns the commits to check, and the exceptions made for the others.
+func (i ignoreT) filter(commits []commitT) ([]commitT, []exceptionT) {
+	kept := make([]commitT, 0, len(commits))
+	exceptions := []exceptionT{}
+
+	for _, commit := range commits {
+		reason := i.reason(commit)
+		if reason == "" {
+			kept = append(kept, commit)
+
+			continue
+		}
+
+		exceptions = append(exceptions, exceptionT{
+			Rule:    rulePolicy,
+			Kind:    exceptionIgnored,
+			SHA:     commit.SHA,
+			Subject: commit.Subject(),
+			Reason:  fmt.Sprintf("commit %s not checked, %s", shortSHA(commit.SHA), reason),
+		})
+	}
+
+	return kept, exceptions
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIgnoreFilter(t *testing.T) {
+	t.Parallel()
+
+	ignore := ignoreT{
+		Subjects: []string{"^Merge ", "^Revert "},
+		Authors:  []string{"dependabot[bot]", "*@bots.example.com"},
+		SHAs:     []string{"deadbeef"},
+	}
+
+	commits := []commitT{
+		{SHA: "0123456789abcdef", Author: "Jane Doe <jane@example.com>", Message: "BUG/MINOR: config: fix timeouts"},
+		{SHA: "1123456789abcdef", Author: "Jane Doe <jane@example.com>", Message: "Merge branch 'master'"},
+		{SHA: "2123456789abcdef", Author: "dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com>",
+			Message: "Bump golang.org/x/text"},
+		{SHA: "3123456789abcdef", Author: "Release <release@bots.example.com>", Message: "v2.8.1"},
+		{SHA: "deadbeef89abcdef", Author: "Jane Doe <jane@example.com>", Message: "oops"},
+	}
+
+	kept, exceptions := ignore.filter(commits)
+	if len(kept) != 1 || kept[0].SHA != commits[0].SHA {
+		t.Errorf("filter() kept %+v", kept)
+	}
+
+	if len(exceptions) != 4 || exceptions[0].Kind != exceptionIgnored ||
+		exceptions[0].Reason != "commit 11234567 not checked, subject matches ignored pattern '^Merge '" {
+		t.Errorf("filter() exceptions = %+v", exceptions)
+	}
+}
+
+func TestIgnoreValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, ignore := range []ignoreT{{Subjects: []string{"("}}, {Authors: []string{"bot["}}, {SHAs: []string{"dead"}},
+		{SHAs: []string{"not-a-sha"}}} {
+		if err := ignore.validate(); !errors.Is(err, ErrIgnoreConfig) {
+			t.Errorf("validate(%+v) error = %v, want ErrIgnoreConfig", ignore, err)
+		}
+	}
+}
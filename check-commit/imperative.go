@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// imperativeMoodT flags the subjects whose first word past the tags and component is
+// the past tense or the gerund of a common verb, "added" or "fixing" instead of "add"
+// or "fix", the Verbs extending the built-in list.
+type imperativeMoodT struct {
+	Verify   bool     `yaml:"Verify"`
+	Verbs    []string `yaml:"Verbs"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+// imperativeVerbs are the verbs subjects commonly start with.
+var imperativeVerbs = []string{
+	"accept", "add", "adjust", "allow", "avoid", "bump", "call", "change", "check", "clean", "convert", "correct",
+	"create", "delete", "detect", "disable", "display", "document", "drop", "enable", "ensure", "export", "extend",
+	"fix", "free", "handle", "implement", "improve", "increase", "initialize", "introduce", "limit", "merge",
+	"move", "optimize", "parse", "pass", "prevent", "print", "reduce", "refactor", "reject", "release", "remove",
+	"rename", "reorganize", "replace", "report", "return", "revert", "rework", "set", "show", "simplify", "skip",
+	"split", "stop", "store", "support", "switch", "update", "upgrade", "use",
+}
+
+// irregularPasts are the irregular past tenses of the verbs subjects start with.
+var irregularPasts = map[string]string{
+	"built": "build", "brought": "bring", "chose": "choose", "found": "find", "got": "get", "kept": "keep",
+	"left": "leave", "made": "make", "ran": "run", "sent": "send", "took": "take", "wrote": "write",
+}
+
+var ErrImperativeMoodConfig = errors.New("invalid imperative mood rule")
+
+func (m imperativeMoodT) validate() error {
+	for _, verb := range m.Verbs {
+		if strings.TrimSpace(verb) == "" || strings.IndexFunc(verb, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("imperative mood rule: '%s' is not a verb: %w", verb, ErrImperativeMoodConfig)
+		}
+	}
+
+	if !validSeverity(m.Severity) {
+		return fmt.Errorf("imperative mood rule: unknown severity '%s': %w", m.Severity, ErrImperativeMoodConfig)
+	}
+
+	return nil
+}
+
+// severity defaults to warning, the mood of a word being guessed.
+func (m imperativeMoodT) severity() string {
+	if m.Severity == "" {
+		return severityWarning
+	}
+
+	return m.Severity
+}
+
+// isVowel tells whether the byte is a lowercase vowel.
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+// verbForms returns the past tense and gerund forms of a regular verb, with its last
+// consonant doubled as well when it may be, e.g. "dropped" and "dropping".
+func verbForms(verb string) []string {
+	n := len(verb)
+
+	switch {
+	case n == 0:
+		return nil
+	case verb[n-1] == 'e':
+		return []string{verb + "d", verb[:n-1] + "ing", verb + "ing"}
+	case n > 1 && verb[n-1] == 'y' && !isVowel(verb[n-2]):
+		return []string{verb[:n-1] + "ied", verb + "ing"}
+	}
+
+	forms := []string{verb + "ed", verb + "ing"}
+	if last := verb[n-1]; n > 2 && !isVowel(last) && strings.IndexByte("wxy", last) < 0 && isVowel(verb[n-2]) &&
+		!isVowel(verb[n-3]) {
+		forms = append(forms, verb+string(last)+"ed", verb+string(last)+"ing")
+	}
+
+	return forms
+}
+
+// imperativeOf returns the verb the word is the past tense or gerund of, if any.
+func (m imperativeMoodT) imperativeOf(word string) (string, bool) {
+	word = strings.ToLower(word)
+	if verb, ok := irregularPasts[word]; ok {
+		return verb, true
+	}
+
+	for _, verbs := range [][]string{imperativeVerbs, m.Verbs} {
+		for _, verb := range verbs {
+			verb = strings.ToLower(verb)
+			if containsString(verbForms(verb), word) {
+				return verb, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+var ErrImperativeMood = errors.New("subject not in the imperative mood")
+
+// Check checks the first word of the text of a subject, past its tags and component.
+func (m imperativeMoodT) Check(text string) error {
+	if !m.Verify {
+		return nil
+	}
+
+	if component, ok := subjectComponent(text); ok {
+		text = strings.TrimPrefix(text, component+": ")
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	word := strings.TrimFunc(fields[0], func(r rune) bool { return !unicode.IsLetter(r) })
+
+	verb, ok := m.imperativeOf(word)
+	if !ok {
+		return nil
+	}
+
+	if first := []rune(word); len(first) > 0 && unicode.IsUpper(first[0]) {
+		verb = strings.ToUpper(verb[:1]) + verb[1:]
+	}
+
+	return fmt.Errorf("subject starts with '%s', use the imperative mood: '%s': %w", word, verb, ErrImperativeMood)
+}
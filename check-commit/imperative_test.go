@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestImperativeMood(t *testing.T) {
+	t.Parallel()
+
+	m := imperativeMoodT{Verify: true, Verbs: []string{"deduplicate"}}
+
+	tests := []struct {
+		text    string
+		wantErr string
+	}{
+		{"mux-h2: add the settings of the streams", ""},
+		{"Added the settings of the streams", "subject starts with 'Added', use the imperative mood: 'Add'"},
+		{"mux-h2: fixing a crash on shutdown", "subject starts with 'fixing', use the imperative mood: 'fix'"},
+		{"dropped the legacy parser", "subject starts with 'dropped', use the imperative mood: 'drop'"},
+		{"config: freeing the timeouts twice", "subject starts with 'freeing', use the imperative mood: 'free'"},
+		{"applied the patch", ""},
+		{"made the timeouts configurable", "subject starts with 'made', use the imperative mood: 'make'"},
+		{"deduplicated the server names", "subject starts with 'deduplicated', use the imperative mood: 'deduplicate'"},
+		{"string: handle empty values", ""},
+		{"embedded lua: fix the stack size", ""},
+	}
+
+	for _, tt := range tests {
+		err := m.Check(tt.text)
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("Check(%q) error = %v", tt.text, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrImperativeMood) || err.Error() != tt.wantErr+": "+ErrImperativeMood.Error()):
+			t.Errorf("Check(%q) error = %v, want %s", tt.text, err, tt.wantErr)
+		}
+	}
+
+	if err := (imperativeMoodT{}).Check("Added the settings"); err != nil {
+		t.Errorf("Check() without Verify error = %v", err)
+	}
+
+	if _, err := parseCommitPolicy("ImperativeMood:\n  Verify: true\n  Verbs: ['clean up']\n"); !errors.Is(err,
+		ErrImperativeMoodConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrImperativeMoodConfig)
+	}
+}
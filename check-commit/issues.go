@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type linkedIssuesT struct {
+	Verify           bool     `yaml:"Verify"`
+	RequireOpen      bool     `yaml:"RequireOpen"`
+	Labels           []string `yaml:"Labels"`
+	RequireMilestone bool     `yaml:"RequireMilestone"`
+	Severity         string   `yaml:"Severity"`
+	Shadow           bool     `yaml:"Shadow"`
+}
+
+var ErrLinkedIssuesConfig = errors.New("invalid linked issues rule")
+
+func (l linkedIssuesT) validate() error {
+	if !validSeverity(l.Severity) {
+		return fmt.Errorf("linked issues rule: unknown severity '%s': %w", l.Severity, ErrLinkedIssuesConfig)
+	}
+
+	return nil
+}
+
+func (l linkedIssuesT) severity() string {
+	if l.Severity == "" {
+		return severityError
+	}
+
+	return l.Severity
+}
+
+// linkedIssues returns the issues of the same project a message claims to fix, such as
+// "Fixes #12" or "closes #7, resolves #9".
+func linkedIssues(message string) []int {
+	r := regexp.MustCompile(`(?i)\b(?:fix(?:e[sd])?|close[sd]?|resolve[sd]?):?\s+((?:#\d+(?:\s*,\s*|\s+and\s+)?)+)`)
+	seen := map[int]bool{}
+	issues := []int{}
+
+	for _, m := range r.FindAllStringSubmatch(message, -1) {
+		for _, ref := range regexp.MustCompile(`#(\d+)`).FindAllStringSubmatch(m[1], -1) {
+			number, err := strconv.Atoi(ref[1])
+			if err == nil && !seen[number] {
+				seen[number] = true
+				issues = append(issues, number)
+			}
+		}
+	}
+
+	return issues
+}
+
+type issueT struct {
+	Exists    bool
+	Open      bool
+	Labels    []string
+	Milestone string
+}
+
+type issueLookupFunc func(number int) (issueT, error)
+
+var ErrLinkedIssue = errors.New("invalid linked issue")
+
+// Check verifies the issues referenced by the commit against the rule.
+func (l linkedIssuesT) Check(commit commitT, lookup issueLookupFunc) error {
+	problems := []string{}
+
+	for _, number := range linkedIssues(commit.Message) {
+		issue, err := lookup(number)
+		if err != nil {
+			return fmt.Errorf("unable to verify issue #%d: %w", number, err)
+		}
+
+		switch {
+		case !issue.Exists:
+			problems = append(problems, fmt.Sprintf("issue #%d does not exist", number))
+		case l.RequireOpen && !issue.Open:
+			problems = append(problems, fmt.Sprintf("issue #%d is already closed", number))
+		case l.RequireMilestone && issue.Milestone == "":
+			problems = append(problems, fmt.Sprintf("issue #%d has no milestone", number))
+		default:
+			if missing := missingLabels(issue.Labels, l.Labels); len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("issue #%d lacks label(s) %s", number, strings.Join(missing, ", ")))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s: %w", strings.Join(problems, "; "), ErrLinkedIssue)
+	}
+
+	return nil
+}
+
+func missingLabels(labels, required []string) []string {
+	missing := []string{}
+
+	for _, r := range required {
+		if len(matchingLabels(labels, []string{r})) == 0 {
+			missing = append(missing, r)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// cachedLookup queries each issue once per run, whatever the number of commits
+// referencing it.
+func cachedLookup(lookup issueLookupFunc) issueLookupFunc {
+	issues := map[int]issueT{}
+
+	return func(number int) (issueT, error) {
+		if issue, ok := issues[number]; ok {
+			return issue, nil
+		}
+
+		issue, err := lookup(number)
+		if err == nil {
+			issues[number] = issue
+		}
+
+		return issue, err
+	}
+}
+
+func githubIssueLookup() (issueLookupFunc, error) {
+	owner, project, _, err := githubPullRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	client := newGithubClient(ctx)
+
+	return func(number int) (issueT, error) {
+		issue, resp, err := client.Issues.Get(ctx, owner, project, number)
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone) {
+			return issueT{}, nil
+		} else if err != nil {
+			return issueT{}, err
+		}
+
+		// pull requests share the numbering of issues but cannot be fixed by a commit
+		if issue.IsPullRequest() {
+			return issueT{}, nil
+		}
+
+		labels := []string{}
+		for _, label := range issue.Labels {
+			labels = append(labels, label.GetName())
+		}
+
+		return issueT{
+			Exists:    true,
+			Open:      issue.GetState() == "open",
+			Labels:    labels,
+			Milestone: issue.GetMilestone().GetTitle(),
+		}, nil
+	}, nil
+}
+
+func gitlabIssueLookup() (issueLookupFunc, error) {
+	client, err := newGitlabClient()
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, _, err := gitlabMergeRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(number int) (issueT, error) {
+		issue, resp, err := client.Issues.GetIssue(projectID, number)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return issueT{}, nil
+		} else if err != nil {
+			return issueT{}, err
+		}
+
+		milestone := ""
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Title
+		}
+
+		return issueT{
+			Exists:    true,
+			Open:      issue.State == "opened",
+			Labels:    issue.Labels,
+			Milestone: milestone,
+		}, nil
+	}, nil
+}
+
+// checkLinkedIssues verifies the issues referenced by the commits of the request.
+func (c CommitPolicyConfig) checkLinkedIssues(repoEnv string, commits []commitT, report *reportT) {
+	if !c.LinkedIssues.Verify {
+		return
+	}
+
+	var lookup issueLookupFunc
+
+	var err error
+
+	switch repoEnv {
+	case GITHUB:
+		lookup, err = githubIssueLookup()
+	case GITLAB:
+		lookup, err = gitlabIssueLookup()
+	default:
+		return
+	}
+
+	if err != nil {
+		report.AddError(ruleLinkedIssues, err)
+
+		return
+	}
+
+	lookup = cachedLookup(lookup)
+
+	for _, commit := range commits {
+		report.AddCommitFinding(ruleLinkedIssues, c.LinkedIssues.severity(), c.LinkedIssues.Shadow, commit,
+			c.LinkedIssues.Check(commit, lookup))
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLinkedIssues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message string
+		want    []int
+	}{
+		{message: "BUG/MINOR: mux: fix the crash\n\nFixes #12", want: []int{12}},
+		{message: "BUG/MINOR: mux: fix the crash\n\nThis closes #7, resolves #9 and #11.", want: []int{7, 9, 11}},
+		{message: "BUG/MINOR: mux: fix the crash\n\nFixes: #3\nSee #4", want: []int{3}},
+		{message: "MINOR: mux: prefixes #1 in the output", want: []int{}},
+		{message: "BUG/MINOR: mux: fix the crash (fix #5, fixed #5)", want: []int{5}},
+	}
+
+	for _, tt := range tests {
+		if got := linkedIssues(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("linkedIssues(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestLinkedIssuesCheck(t *testing.T) {
+	t.Parallel()
+
+	issues := map[int]issueT{
+		1: {Exists: true, Open: true, Labels: []string{"Triaged"}, Milestone: "2.8"},
+		2: {Exists: true, Open: false, Labels: []string{"triaged"}, Milestone: "2.8"},
+		3: {Exists: true, Open: true, Milestone: "2.8"},
+		4: {Exists: true, Open: true, Labels: []string{"triaged"}},
+	}
+	queries := 0
+	lookup := cachedLookup(func(number int) (issueT, error) {
+		queries++
+		if number == 9 {
+			return issueT{}, fmt.Errorf("API unavailable")
+		}
+
+		return issues[number], nil
+	})
+
+	l := linkedIssuesT{Verify: true, RequireOpen: true, Labels: []string{"triaged"}, RequireMilestone: true}
+	tests := []struct {
+		issue   int
+		wantErr error
+	}{
+		{issue: 1},
+		{issue: 1},
+		{issue: 2, wantErr: ErrLinkedIssue},
+		{issue: 3, wantErr: ErrLinkedIssue},
+		{issue: 4, wantErr: ErrLinkedIssue},
+		{issue: 5, wantErr: ErrLinkedIssue},
+	}
+
+	for _, tt := range tests {
+		commit := commitT{Message: fmt.Sprintf("BUG/MINOR: mux: fix the crash\n\nFixes #%d", tt.issue)}
+		if err := l.Check(commit, lookup); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Check(#%d) error = %v, want %v", tt.issue, err, tt.wantErr)
+		}
+	}
+
+	if queries != 5 {
+		t.Errorf("lookups = %d, want 5", queries)
+	}
+
+	if err := l.Check(commitT{Message: "BUG/MINOR: mux: fix\n\nFixes #9"}, lookup); err == nil {
+		t.Errorf("Check() with failing lookup, want error")
+	}
+}
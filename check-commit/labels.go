@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-github/v35/github"
+)
+
+const (
+	requireBoth  = "both"
+	requireLabel = "label"
+	requireTag   = "tag"
+)
+
+// labelRuleT ties request labels to commit tags: commits carrying one of Values
+// require one of Labels on the request, and Labels require such a commit.
+type labelRuleT struct {
+	Values  []string `yaml:"Values"`
+	Labels  []string `yaml:"Labels"`
+	Require string   `yaml:"Require"`
+}
+
+var ErrLabelRuleConfig = errors.New("invalid label rule")
+
+func (r labelRuleT) validate() error {
+	if len(r.Values) == 0 || len(r.Labels) == 0 {
+		return fmt.Errorf("label rule needs both Values and Labels: %w", ErrLabelRuleConfig)
+	}
+
+	switch r.Require {
+	case "", requireBoth, requireLabel, requireTag:
+		return nil
+	}
+
+	return fmt.Errorf("label rule Require must be %s, %s or %s, got '%s': %w",
+		requireBoth, requireLabel, requireTag, r.Require, ErrLabelRuleConfig)
+}
+
+func (r labelRuleT) requires(what string) bool {
+	return r.Require == "" || r.Require == requireBoth || r.Require == what
+}
+
+var ErrLabelMismatch = errors.New("labels inconsistent with patch types")
+
+// CheckLabels verifies the labels of the request against the tags of its commits.
+func (c CommitPolicyConfig) CheckLabels(commits []commitT, labels []string) error {
+	problems := []string{}
+
+	for _, rule := range c.LabelRules {
+		tagged := []string{}
+
+		for _, commit := range commits {
+			if hasAnyValue(c.subjectTags(commit.Subject()), rule.Values) {
+				tagged = append(tagged, shortSHA(commit.SHA))
+			}
+		}
+
+		labelled := matchingLabels(labels, rule.Labels)
+
+		switch {
+		case rule.requires(requireLabel) && len(tagged) > 0 && len(labelled) == 0:
+			problems = append(problems, fmt.Sprintf("commit(s) %s carry %s but the request has no %s label",
+				strings.Join(tagged, ", "), strings.Join(rule.Values, "/"), strings.Join(rule.Labels, "/")))
+		case rule.requires(requireTag) && len(labelled) > 0 && len(tagged) == 0:
+			problems = append(problems, fmt.Sprintf("label %s requires a %s commit",
+				strings.Join(labelled, ", "), strings.Join(rule.Values, "/")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s: %w", strings.Join(problems, "; "), ErrLabelMismatch)
+	}
+
+	return nil
+}
+
+func matchingLabels(labels, wanted []string) []string {
+	found := []string{}
+
+	for _, label := range labels {
+		for _, w := range wanted {
+			if strings.EqualFold(label, w) {
+				found = append(found, label)
+			}
+		}
+	}
+
+	return found
+}
+
+// fetchRequestLabels returns the current labels of the request from the API, as the
+// event payload misses labels changed since the workflow was triggered.
+func fetchRequestLabels(repoEnv string) []string {
+	var labels []string
+
+	var err error
+
+	switch repoEnv {
+	case GITHUB:
+		labels, err = fetchGithubLabels()
+	case GITLAB:
+		labels, err = fetchGitlabLabels()
+	}
+
+	if err != nil || labels == nil {
+		if err != nil {
+			log.Printf("warning: unable to fetch request labels, using the event ones (%s)", err)
+		}
+
+		return getRequestLabels(repoEnv)
+	}
+
+	return labels
+}
+
+func fetchGithubLabels() ([]string, error) {
+	owner, project, prNo, err := githubPullRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	client := newGithubClient(ctx)
+
+	labels := []string{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, resp, err := client.Issues.ListLabelsByIssue(ctx, owner, project, prNo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching labels: %w", err)
+		}
+
+		for _, label := range page {
+			labels = append(labels, label.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return labels, nil
+}
+
+func fetchGitlabLabels() ([]string, error) {
+	client, err := newGitlabClient()
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, mrIID, err := gitlabMergeRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	mr, _, err := client.MergeRequests.GetMergeRequest(projectID, mrIID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching labels: %w", err)
+	}
+
+	return mr.Labels, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckLabels(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{LabelRules: []labelRuleT{
+		{Values: []string{"BUG"}, Labels: []string{"bug"}, Require: requireLabel},
+		{Values: []string{"MAJOR"}, Labels: []string{"breaking"}, Require: requireTag},
+		{Values: []string{"DOC"}, Labels: []string{"documentation"}},
+	}}
+
+	bug := commitT{SHA: "1111111111", Message: "BUG/MINOR: labels: fix the label check"}
+	major := commitT{SHA: "2222222222", Message: "MAJOR: labels: rework the label check"}
+	doc := commitT{SHA: "3333333333", Message: "DOC: labels: document the label check"}
+	minor := commitT{SHA: "4444444444", Message: "MINOR: labels: add the label check"}
+
+	tests := []struct {
+		name    string
+		commits []commitT
+		labels  []string
+		wantErr bool
+	}{
+		{name: "bug with label", commits: []commitT{bug}, labels: []string{"Bug"}},
+		{name: "bug without label", commits: []commitT{bug}, wantErr: true},
+		{name: "bug label without bug", commits: []commitT{minor}, labels: []string{"bug"}},
+		{name: "breaking with major", commits: []commitT{major}, labels: []string{"breaking"}},
+		{name: "breaking without major", commits: []commitT{minor}, labels: []string{"breaking"}, wantErr: true},
+		{name: "major without breaking", commits: []commitT{major}},
+		{name: "doc without label", commits: []commitT{doc}, wantErr: true},
+		{name: "doc label without doc", commits: []commitT{minor}, labels: []string{"documentation"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.CheckLabels(tt.commits, tt.labels)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrLabelMismatch)) {
+				t.Errorf("CheckLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLabelRuleValidate(t *testing.T) {
+	t.Parallel()
+
+	rules := []labelRuleT{
+		{Labels: []string{"bug"}},
+		{Values: []string{"BUG"}},
+		{Values: []string{"BUG"}, Labels: []string{"bug"}, Require: "label-only"},
+	}
+
+	for _, rule := range rules {
+		if err := rule.validate(); !errors.Is(err, ErrLabelRuleConfig) {
+			t.Errorf("validate(%v) error = %v, want ErrLabelRuleConfig", rule, err)
+		}
+	}
+}
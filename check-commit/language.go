@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// function words that are very unlikely to show up in an English subject, keyed by language
+func foreignStopwords() map[string][]string {
+	return map[string][]string{
+		"French": {
+			"le", "la", "les", "des", "du", "une", "et", "est", "pour", "dans", "avec", "sur",
+			"pas", "qui", "que", "ajout", "correction", "ajouter", "corriger", "mise", "jour",
+		},
+		"German": {
+			"der", "das", "und", "ist", "nicht", "mit", "für", "fuer", "auf", "ein", "eine",
+			"beim", "vom", "zum", "hinzufügen", "korrigiert", "behoben", "fehler",
+		},
+		"Spanish": {
+			"el", "los", "las", "del", "y", "es", "para", "con", "por", "una", "se",
+			"agregar", "corregir", "arreglar", "añadir", "cuando",
+		},
+		"Italian": {
+			"il", "lo", "gli", "della", "delle", "di", "e", "è", "con", "una", "non",
+			"aggiunto", "corretto", "aggiungere", "quando",
+		},
+		"Portuguese": {
+			"o", "os", "da", "das", "do", "dos", "e", "é", "para", "com", "uma", "não",
+			"adicionar", "corrigir", "quando",
+		},
+		"Dutch": {
+			"de", "het", "een", "en", "is", "niet", "met", "voor", "van", "toevoegen", "opgelost",
+		},
+	}
+}
+
+func englishStopwords() []string {
+	return []string{
+		"the", "a", "an", "and", "or", "of", "to", "in", "on", "for", "with", "when", "from",
+		"by", "at", "is", "not", "be", "if", "as", "into", "add", "fix", "use", "remove",
+		"make", "do", "don't", "update", "support", "allow",
+	}
+}
+
+func subjectWords(subject string) []string {
+	return strings.FieldsFunc(strings.ToLower(subject), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+}
+
+// detectForeignLanguage is a deliberately small heuristic: it counts function words
+// and only reports a language when it clearly outweighs English. Technical words
+// (identifiers, file names) match neither list and are simply ignored.
+func detectForeignLanguage(subject string) string {
+	const minForeignHits = 2
+
+	words := subjectWords(subject)
+
+	english := 0
+
+	for _, word := range words {
+		for _, stopword := range englishStopwords() {
+			if word == stopword {
+				english++
+
+				break
+			}
+		}
+	}
+
+	detected := ""
+	best := 0
+
+	for language, stopwords := range foreignStopwords() {
+		hits := 0
+
+		for _, word := range words {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					hits++
+
+					break
+				}
+			}
+		}
+
+		if hits > best || (hits == best && language < detected) {
+			best = hits
+			detected = language
+		}
+	}
+
+	if best < minForeignHits || best <= english {
+		return ""
+	}
+
+	return detected
+}
+
+var ErrSubjectLanguage = errors.New("subject does not appear to be written in English")
+
+func checkSubjectLanguage(subject string) error {
+	if language := detectForeignLanguage(subject); language != "" {
+		return fmt.Errorf("'%s' looks like %s: %w", subject, language, ErrSubjectLanguage)
+	}
+
+	return nil
+}
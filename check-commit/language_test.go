@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestDetectForeignLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		subject string
+		want    string
+	}{
+		{
+			name:    "english",
+			subject: "config: add the default location of the configuration file",
+			want:    "",
+		},
+		{
+			name:    "technical words only",
+			subject: "mux-h2: h2c_frt_handle_data la_stats",
+			want:    "",
+		},
+		{
+			name:    "french",
+			subject: "config: ajout de la gestion des erreurs dans le parseur",
+			want:    "French",
+		},
+		{
+			name:    "german",
+			subject: "mux: Fehler mit der Verbindung behoben",
+			want:    "German",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := detectForeignLanguage(tt.subject); got != tt.want {
+				t.Errorf("detectForeignLanguage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// lint warning identifiers, to be listed in LintIgnore to silence a warning deliberately
+const (
+	lintDeprecatedKey          = "deprecated-key"
+	lintUnusedPatchType        = "unused-patch-type"
+	lintUnusedScope            = "unused-scope"
+	lintUnreachableAlternative = "unreachable-alternative"
+	lintOverlappingPatchTypes  = "overlapping-patch-types"
+	lintOverlappingTags        = "overlapping-tags"
+	lintDuplicateValue         = "duplicate-value"
+)
+
+const configLint = "lint"
+
+// deprecatedKeys maps the dotted paths of deprecated configuration keys to the advice
+// given to migrate away from them.
+var deprecatedKeys = map[string]string{}
+
+type lintWarningT struct {
+	ID      string
+	Message string
+}
+
+func (w lintWarningT) String() string {
+	return fmt.Sprintf("[%s] %s", w.ID, w.Message)
+}
+
+// lintPolicy looks for configuration mistakes that do not prevent loading the policy,
+// leaving out the warnings silenced by LintIgnore.
+func lintPolicy(config string, c CommitPolicyConfig, deprecated map[string]string) []lintWarningT {
+	warnings := lintDeprecatedKeys(config, deprecated)
+	warnings = append(warnings, c.lintReferences()...)
+	warnings = append(warnings, c.lintTagOrder()...)
+	warnings = append(warnings, c.lintHeuristicTags()...)
+	warnings = append(warnings, c.lintDuplicates()...)
+
+	active := []lintWarningT{}
+
+	for _, warning := range warnings {
+		if !containsString(c.LintIgnore, warning.ID) {
+			active = append(active, warning)
+		}
+	}
+
+	return active
+}
+
+func lintDeprecatedKeys(config string, deprecated map[string]string) []lintWarningT {
+	var document interface{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return nil // reported when loading the policy
+	}
+
+	paths := map[string]bool{}
+	collectKeyPaths(document, "", paths)
+
+	warnings := []lintWarningT{}
+
+	for _, path := range sortedKeys(paths) {
+		if advice, ok := deprecated[path]; ok {
+			warnings = append(warnings, lintWarningT{lintDeprecatedKey, fmt.Sprintf("%s is deprecated: %s", path, advice)})
+		}
+	}
+
+	return warnings
+}
+
+// collectKeyPaths records the dotted paths of all the keys of a YAML document, list
+// items sharing the path of their list.
+func collectKeyPaths(node interface{}, prefix string, paths map[string]bool) {
+	switch value := node.(type) {
+	case map[interface{}]interface{}:
+		for key, child := range value {
+			path := fmt.Sprint(key)
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+
+			paths[path] = true
+			collectKeyPaths(child, path, paths)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectKeyPaths(child, prefix, paths)
+		}
+	}
+}
+
+// lintReferences checks that the defined patch types and scopes are used; undefined
+// ones are rejected when loading the policy.
+func (c CommitPolicyConfig) lintReferences() []lintWarningT {
+	warnings := []lintWarningT{}
+	usedTypes := map[string]bool{}
+	usedScopes := map[string]bool{}
+
+	for _, alternative := range c.TagOrder {
+		for _, name := range alternative.PatchTypes {
+			usedTypes[name] = true
+		}
+	}
+
+	for _, name := range sortedPatchTypes(c.PatchTypes) {
+		usedScopes[c.PatchTypes[name].Scope] = true
+
+		if !usedTypes[name] {
+			warnings = append(warnings, lintWarningT{lintUnusedPatchType,
+				fmt.Sprintf("patch type '%s' is not part of TagOrder", name)})
+		}
+	}
+
+	for _, scope := range sortedPatchScopes(c.PatchScopes) {
+		if !usedScopes[scope] {
+			warnings = append(warnings, lintWarningT{lintUnusedScope,
+				fmt.Sprintf("scope '%s' is not used by any patch type", scope)})
+		}
+	}
+
+	return warnings
+}
+
+// lintTagOrder looks for patch types of a TagOrder alternative shadowed by the
+// preceding ones: a tag is accepted by the first patch type allowing it, so a patch
+// type whose tags and severities are all allowed earlier never makes a difference.
+func (c CommitPolicyConfig) lintTagOrder() []lintWarningT {
+	warnings := []lintWarningT{}
+
+	for i, alternative := range c.TagOrder {
+		seen := map[string]bool{}
+
+		for j, name := range alternative.PatchTypes {
+			patchType, ok := c.PatchTypes[name]
+			if !ok {
+				continue
+			}
+
+			overlaps := []string{}
+			shadowed := len(patchType.Values) > 0
+			own := map[string]bool{} // values repeated in the patch type are duplicate-value warnings
+
+			for _, tag := range patchType.Values {
+				if own[tag] {
+					continue
+				}
+
+				own[tag] = true
+
+				if !seen[tag] {
+					shadowed = false
+
+					continue
+				}
+
+				overlaps = append(overlaps, tag)
+				shadowed = shadowed && c.coversScope(alternative.PatchTypes[:j], tag, patchType.Scope)
+			}
+
+			for tag := range own {
+				seen[tag] = true
+			}
+
+			switch {
+			case shadowed:
+				warnings = append(warnings, lintWarningT{lintUnreachableAlternative,
+					fmt.Sprintf("patch type '%s' of TagOrder alternative %d is unreachable, its tags are all accepted "+
+						"by the preceding patch types", name, i+1)})
+			case len(overlaps) > 0:
+				warnings = append(warnings, lintWarningT{lintOverlappingPatchTypes,
+					fmt.Sprintf("tags %s of patch type '%s' are also part of a preceding patch type of TagOrder "+
+						"alternative %d", strings.Join(overlaps, ", "), name, i+1)})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// coversScope tells whether one of the patch types accepts the tag with every
+// severity of the scope (or without severity when there is no scope).
+func (c CommitPolicyConfig) coversScope(names []string, tag, scope string) bool {
+	for _, name := range names {
+		patchType := c.PatchTypes[name]
+		if !containsString(patchType.Values, tag) {
+			continue
+		}
+
+		if scope == "" || patchType.Scope == scope {
+			return true
+		}
+
+		covered := true
+
+		for _, severity := range c.PatchScopes[scope] {
+			covered = covered && containsString(c.PatchScopes[patchType.Scope], severity)
+		}
+
+		if covered {
+			return true
+		}
+	}
+
+	return false
+}
+
+type tagClaimT struct {
+	name string
+	tags []string
+}
+
+// lintHeuristicTags reports tags claimed by several of the checks restricting what a
+// commit may change, which then all apply to the same commits.
+func (c CommitPolicyConfig) lintHeuristicTags() []lintWarningT {
+	claims := []tagClaimT{}
+
+	if h := c.DiffHeuristics.Reorg; h != nil {
+		claims = append(claims, tagClaimT{"DiffHeuristics.Reorg", defaultTags(h.Tags, []string{"REORG"})})
+	}
+
+	if h := c.DiffHeuristics.Cleanup; h != nil {
+		claims = append(claims, tagClaimT{"DiffHeuristics.Cleanup", defaultTags(h.Tags, []string{"CLEANUP"})})
+	}
+
+	if c.Documentation.enabled() {
+		claims = append(claims, tagClaimT{"Documentation", c.Documentation.tags()})
+	}
+
+	warnings := []lintWarningT{}
+
+	for i := range claims {
+		for j := i + 1; j < len(claims); j++ {
+			for _, tag := range claims[i].tags {
+				if containsString(claims[j].tags, tag) {
+					warnings = append(warnings, lintWarningT{lintOverlappingTags,
+						fmt.Sprintf("tag %s is claimed by both %s and %s", tag, claims[i].name, claims[j].name)})
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+type valueListT struct {
+	name   string
+	values []string
+}
+
+// lintDuplicates reports the values listed twice in the same list, a sign of a list
+// edited by hand or merged from several sources.
+func (c CommitPolicyConfig) lintDuplicates() []lintWarningT {
+	lists := []valueListT{}
+
+	for _, name := range sortedPatchScopes(c.PatchScopes) {
+		lists = append(lists, valueListT{fmt.Sprintf("PatchScopes.%s", name), c.PatchScopes[name]})
+	}
+
+	for _, name := range sortedPatchTypes(c.PatchTypes) {
+		lists = append(lists, valueListT{fmt.Sprintf("PatchTypes.%s.Values", name), c.PatchTypes[name].Values})
+	}
+
+	lists = append(lists, valueListT{"ProtectedBranches", c.ProtectedBranches},
+		valueListT{"Components.Values", c.Components.Values}, valueListT{"ExemptAuthors", c.ExemptAuthors},
+		valueListT{"LintIgnore", c.LintIgnore})
+
+	warnings := []lintWarningT{}
+
+	for _, list := range lists {
+		seen := map[string]bool{}
+
+		for _, value := range list.values {
+			if seen[value] {
+				warnings = append(warnings, lintWarningT{lintDuplicateValue,
+					fmt.Sprintf("%s lists %s more than once", list.name, value)})
+			}
+
+			seen[value] = true
+		}
+	}
+
+	return warnings
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultTags(tags, defaults []string) []string {
+	if len(tags) == 0 {
+		return defaults
+	}
+
+	return tags
+}
+
+func sortedPatchTypes(patchTypes map[string]patchTypeT) []string {
+	names := make([]string, 0, len(patchTypes))
+	for name := range patchTypes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedPatchScopes(scopes map[string][]string) []string {
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func logLintWarnings(warnings []lintWarningT) {
+	for _, warning := range warnings {
+		log.Printf("configuration warning %s (add %s to LintIgnore to silence it)", warning, warning.ID)
+	}
+}
+
+var ErrLintWarnings = errors.New("configuration has lint warnings")
+
+// lintConfig lints the policy the reader reads, returning the number of unsilenced
+// warnings.
+func lintConfig(read policyReaderFunc, opts optionsT) (int, error) {
+	config, err := readVerifiedPolicy(read, opts.policyKey, opts.preset, opts.profile)
+	if err != nil {
+		return 0, err
+	}
+
+	commitPolicy, err := parseCommitPolicy(config)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(lintPolicy(config, commitPolicy, deprecatedKeys)), nil
+}
+
+// lintCommand lints the policy of the repository, failing on unsilenced warnings.
+func lintCommand(opts optionsT) error {
+	read, err := opts.locatePolicy(localPolicyReader(opts.repoPath))
+	if err != nil {
+		return err
+	}
+
+	warnings, err := lintConfig(read, opts)
+	if err != nil {
+		return err
+	}
+
+	if warnings > 0 {
+		return fmt.Errorf("%d warning(s): %w", warnings, ErrLintWarnings)
+	}
+
+	log.Printf("configuration is clean")
+
+	return nil
+}
+
+var ErrConfigLint = errors.New("configuration lint failed")
+
+// configLintCommand lints the policy files given as arguments, --config or the policy
+// file of the current directory by default, without a repository: they must exist,
+// rather than falling back to a preset.
+func configLintCommand(opts optionsT) error {
+	files := opts.policies
+	if len(files) == 0 {
+		files = []string{opts.policyPath()}
+	}
+
+	failed := 0
+
+	for _, file := range files {
+		read := renamedPolicyReader(localPolicyReader(filepath.Dir(file)), filepath.Base(file))
+
+		_, err := read(policyFile)
+		warnings := 0
+
+		if err == nil {
+			warnings, err = lintConfig(read, opts)
+		}
+
+		switch {
+		case err != nil:
+			log.Printf("%s: %s", file, err)
+		case warnings > 0:
+			log.Printf("%s: %d warning(s)", file, warnings)
+		default:
+			log.Printf("%s: configuration is clean", file)
+
+			continue
+		}
+
+		failed++
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d configuration(s): %w", failed, len(files), ErrConfigLint)
+	}
+
+	return nil
+}
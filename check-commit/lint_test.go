@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const unreachableConf = `
+PatchScopes:
+  All: [MINOR, MAJOR]
+  Some: [MINOR]
+PatchTypes:
+  Broad:
+    Values: [BUG, DOC]
+    Scope: All
+  Narrow:
+    Values: [BUG]
+    Scope: Some
+TagOrder:
+  - PatchTypes: [Broad, Narrow]
+`
+
+const overlappingConf = `
+PatchScopes:
+  All: [MINOR, MAJOR]
+  Some: [MINOR]
+PatchTypes:
+  Narrow:
+    Values: [BUG]
+    Scope: Some
+  Broad:
+    Values: [BUG, DOC]
+    Scope: All
+TagOrder:
+  - PatchTypes: [Narrow, Broad]
+`
+
+func TestLintPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"default", defaultConf, []string{}},
+		{"deprecated key", defaultConf + "Legacy:\n  Old: true\n", []string{lintDeprecatedKey}},
+		{"unused patch type and scope", `
+PatchScopes:
+  Unused: [MINOR]
+PatchTypes:
+  Tags:
+    Values: [BUG]
+  Extra:
+    Values: [DOC]
+TagOrder:
+  - PatchTypes: [Tags]
+`, []string{lintUnusedPatchType, lintUnusedScope}},
+		{"unreachable", unreachableConf, []string{lintUnreachableAlternative}},
+		{"overlapping", overlappingConf, []string{lintOverlappingPatchTypes}},
+		{"overlapping tags", defaultConf + `
+DiffHeuristics:
+  Reorg: {}
+  Cleanup:
+    Tags: [CLEANUP, REORG]
+`, []string{lintOverlappingTags}},
+		{"duplicate values", `
+PatchScopes:
+  Severities: [MINOR, MAJOR, MINOR]
+PatchTypes:
+  Tags:
+    Values: [BUG, DOC, BUG]
+    Scope: Severities
+TagOrder:
+  - PatchTypes: [Tags]
+ExemptAuthors: [renovate, renovate]
+`, []string{lintDuplicateValue, lintDuplicateValue, lintDuplicateValue}},
+		{"ignored", defaultConf + `
+DiffHeuristics:
+  Reorg: {}
+  Cleanup:
+    Tags: [REORG]
+LintIgnore: [overlapping-tags]
+`, []string{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var c CommitPolicyConfig // decoded as is, deprecated keys would be rejected by parseCommitPolicy
+			if err := yaml.Unmarshal([]byte(tt.config), &c); err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, warning := range lintPolicy(tt.config, c, map[string]string{"Legacy.Old": "remove it"}) {
+				got = append(got, warning.ID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigLintCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"clean.yml":    defaultConf,
+		"warnings.yml": defaultConf + "ProtectedBranches: [main, main]\n",
+		"invalid.yml":  "MaxCommit: 3\n",
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		files   []string
+		wantErr bool
+	}{
+		{"clean", []string{"clean.yml"}, false},
+		{"warnings", []string{"clean.yml", "warnings.yml"}, true},
+		{"invalid", []string{"invalid.yml"}, true},
+		{"missing", []string{"missing.yml"}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := optionsT{}
+			for _, file := range tt.files {
+				opts.policies = append(opts.policies, filepath.Join(dir, file))
+			}
+
+			if err := configLintCommand(opts); (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrConfigLint) {
+				t.Errorf("configLintCommand() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const defaultLocale = "en"
+
+// catalogT holds the messages of a locale: the wording of the summary of the errors,
+// what the commits listed under a rule have in common, and text/template Findings
+// rewriting the messages of the findings of a rule, which can use the fields of
+// helpDataT. Missing messages are left in English.
+type catalogT struct {
+	Summary  string            `yaml:"Summary"`  // header of the summary of the errors
+	Commits  string            `yaml:"Commits"`  // counted commits, "commit(s)"
+	Distinct string            `yaml:"Distinct"` // counted offending values
+	Other    string            `yaml:"Other"`    // description of the rules missing from Rules
+	Rules    map[string]string `yaml:"Rules"`
+	Findings map[string]string `yaml:"Findings"`
+
+	expected map[string]string // expected formats of the rules of Findings
+}
+
+// builtinCatalogs translate the summary of the errors, the Messages of the policy
+// being merged over them.
+var builtinCatalogs = map[string]catalogT{
+	"de": {
+		Summary:  "Zusammenfassung der Fehler nach Regel",
+		Commits:  "Commit(s)",
+		Distinct: "verschieden",
+		Other:    "die gegen sie verstoßen",
+		Rules: map[string]string{
+			ruleTag:               "mit ungültigem oder fehlendem Tag",
+			ruleSubjectFormat:     "mit falscher Länge oder Wortzahl im Betreff",
+			ruleLanguage:          "nicht auf Englisch verfasst",
+			ruleRevertOfRevert:    "die einen Revert rückgängig machen",
+			ruleReorgPurity:       "die bei einer Reorganisation das Verhalten ändern",
+			ruleCleanupNeutrality: "die bei einer Bereinigung das Verhalten ändern",
+			ruleDocumentation:     "mit Dokumentations-Tag, die andere Dateien ändern",
+			ruleEncoding:          "mit falsch kodierter Nachricht",
+			ruleCommitSize:        "zu groß",
+			ruleSensitivePaths:    "die sensible Pfade ohne das erforderliche Tag oder den Trailer ändern",
+			rulePathRules:         "ohne das Tag der geänderten Pfade",
+			ruleSignatures:        "ohne verifizierte Signatur",
+			ruleTagConstraints:    "mit unzulässiger Kombination von Tags",
+			ruleComponent:         "ohne bekannte Komponente",
+			ruleForbiddenWords:    "als unfertige Arbeit markiert",
+			ruleDeprecatedTag:     "mit veraltetem Tag",
+			ruleBotFormat:         "von Bots, nicht im Format des Bots",
+			ruleImperativeMood:    "mit einem Betreff nicht im Imperativ",
+			ruleTrailingPunct:     "mit einem Betreff, der mit einem Satzzeichen endet",
+			ruleCapitalization:    "mit falscher Groß- oder Kleinschreibung am Anfang des Betreffs",
+			ruleCharset:           "mit unzulässigen Zeichen im Betreff",
+			ruleWhitespace:        "mit falschen Leerzeichen im Betreff",
+			ruleTicketReference:   "ohne Ticket-Referenz",
+			ruleAutosquash:        "die noch zusammengeführt werden müssen",
+			ruleRevert:            "die einen Commit rückgängig machen, den sie nicht beschreiben",
+			ruleSpelling:          "mit wahrscheinlichen Tippfehlern im Betreff",
+			ruleDuplicateSubject:  "mit dem Betreff eines früheren Commits",
+			ruleSubjectURL:        "mit einer URL im Betreff",
+			ruleVagueSubject:      "mit einem nichtssagenden Betreff",
+			ruleForbiddenChars:    "mit verbotenen Zeichen oder Markierungen im Betreff",
+			ruleDenylist:          "mit einem gesperrten Wort in der Nachricht",
+			ruleTrailingIssue:     "mit fehlender, falsch platzierter oder verbotener Issue-Referenz am Ende des Betreffs",
+		},
+	},
+	"fr": {
+		Summary:  "résumé des erreurs par règle",
+		Commits:  "commit(s)",
+		Distinct: "distinct(s)",
+		Other:    "ne la respectant pas",
+		Rules: map[string]string{
+			ruleTag:               "avec une étiquette invalide ou absente",
+			ruleSubjectFormat:     "dont le sujet a une longueur ou un nombre de mots incorrect",
+			ruleLanguage:          "non rédigés en anglais",
+			ruleRevertOfRevert:    "annulant une annulation",
+			ruleReorgPurity:       "modifiant le comportement dans une réorganisation",
+			ruleCleanupNeutrality: "modifiant le comportement dans un nettoyage",
+			ruleDocumentation:     "avec une étiquette de documentation modifiant d'autres fichiers",
+			ruleEncoding:          "dont le message est mal encodé",
+			ruleCommitSize:        "trop volumineux",
+			ruleSensitivePaths:    "touchant des chemins sensibles sans l'étiquette ou le trailer requis",
+			rulePathRules:         "sans l'étiquette des chemins qu'ils modifient",
+			ruleSignatures:        "sans signature vérifiée",
+			ruleTagConstraints:    "combinant des étiquettes de façon interdite",
+			ruleComponent:         "sans composant connu",
+			ruleForbiddenWords:    "marqués comme travail inachevé",
+			ruleDeprecatedTag:     "avec une étiquette obsolète",
+			ruleBotFormat:         "de robots ne respectant pas le format du robot",
+			ruleImperativeMood:    "dont le sujet n'est pas à l'impératif",
+			ruleTrailingPunct:     "dont le sujet se termine par une ponctuation",
+			ruleCapitalization:    "dont le sujet commence par une mauvaise casse",
+			ruleCharset:           "dont le sujet contient des caractères interdits",
+			ruleWhitespace:        "dont le sujet est mal espacé",
+			ruleTicketReference:   "sans référence de ticket",
+			ruleAutosquash:        "restant à fusionner",
+			ruleRevert:            "annulant un commit qu'ils ne décrivent pas",
+			ruleSpelling:          "dont le sujet contient de probables fautes de frappe",
+			ruleDuplicateSubject:  "reprenant le sujet d'un commit précédent",
+			ruleSubjectURL:        "dont le sujet contient une URL",
+			ruleVagueSubject:      "dont le sujet est trop vague",
+			ruleForbiddenChars:    "dont le sujet contient des caractères ou marqueurs interdits",
+			ruleDenylist:          "dont le message contient un mot proscrit",
+			ruleTrailingIssue:     "dont la référence de ticket en fin de sujet est absente, mal placée ou interdite",
+		},
+	},
+}
+
+var ErrLocale = errors.New("invalid locale")
+
+// locales lists the locales the policy can select, built-in or defined by Messages.
+func (c CommitPolicyConfig) locales() []string {
+	locales := []string{defaultLocale}
+
+	for locale := range builtinCatalogs {
+		locales = append(locales, locale)
+	}
+
+	for locale := range c.Messages {
+		if !containsString(locales, locale) {
+			locales = append(locales, locale)
+		}
+	}
+
+	sort.Strings(locales)
+
+	return locales
+}
+
+// validateLocale checks that the Locale is known and that the Messages are those of
+// rules, their Findings being valid templates.
+func (c CommitPolicyConfig) validateLocale() error {
+	if locales := c.locales(); c.Locale != "" && !containsString(locales, c.Locale) {
+		return fmt.Errorf("Locale: unknown locale '%s'%s, expected one of %s: %w", c.Locale,
+			didYouMean(c.Locale, locales), strings.Join(locales, ", "), ErrLocale)
+	}
+
+	rules := append(append([]string{}, builtinRules...), c.customRuleNames()...)
+
+	for locale, catalog := range c.Messages {
+		for _, messages := range []map[string]string{catalog.Rules, catalog.Findings} {
+			for rule := range messages {
+				if !containsString(rules, rule) {
+					return fmt.Errorf("Messages: locale '%s': unknown rule '%s'%s: %w", locale, rule,
+						didYouMean(rule, rules), ErrLocale)
+				}
+			}
+		}
+
+		for rule, text := range catalog.Findings {
+			tmpl, err := template.New(rule).Option("missingkey=error").Parse(text)
+			if err == nil {
+				err = tmpl.Execute(&strings.Builder{}, helpDataT{})
+			}
+
+			if err != nil {
+				return fmt.Errorf("Messages: locale '%s': rule '%s': %s: %w", locale, rule, err, ErrLocale)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeCatalog returns the catalog with the messages of overrides replacing its own.
+func mergeCatalog(catalog, overrides catalogT) catalogT {
+	merged := catalogT{
+		Summary:  catalog.text(overrides.Summary, catalog.Summary),
+		Commits:  catalog.text(overrides.Commits, catalog.Commits),
+		Distinct: catalog.text(overrides.Distinct, catalog.Distinct),
+		Other:    catalog.text(overrides.Other, catalog.Other),
+		Rules:    map[string]string{},
+		Findings: map[string]string{},
+	}
+
+	for _, messages := range []map[string]string{catalog.Rules, overrides.Rules} {
+		for rule, text := range messages {
+			merged.Rules[rule] = text
+		}
+	}
+
+	for _, messages := range []map[string]string{catalog.Findings, overrides.Findings} {
+		for rule, text := range messages {
+			merged.Findings[rule] = text
+		}
+	}
+
+	return merged
+}
+
+// messageCatalog returns the catalog of the Locale of the policy: the built-in one, if
+// any, with the Messages of the locale merged over it.
+func (c CommitPolicyConfig) messageCatalog() catalogT {
+	locale := c.Locale
+	if locale == "" {
+		locale = defaultLocale
+	}
+
+	catalog := mergeCatalog(builtinCatalogs[locale], c.Messages[locale])
+	catalog.expected = map[string]string{}
+
+	for rule := range catalog.Findings {
+		catalog.expected[rule] = c.expectedFormat(rule)
+	}
+
+	return catalog
+}
+
+// text returns the message, else the fallback.
+func (m catalogT) text(message, fallback string) string {
+	if message == "" {
+		return fallback
+	}
+
+	return message
+}
+
+// description says what the commits listed under the rule have in common.
+func (m catalogT) description(rule string) string {
+	if description, ok := m.Rules[rule]; ok {
+		return description
+	}
+
+	if description, ok := summaryDescriptions[rule]; ok {
+		return description
+	}
+
+	return m.text(m.Other, "violating it")
+}
+
+// findingMessage rewrites the message of the finding with the Findings template of its
+// rule, if any.
+func (m catalogT) findingMessage(finding findingT) string {
+	text, ok := m.Findings[finding.Rule]
+	if !ok {
+		return finding.Message
+	}
+
+	data := helpDataT{
+		Rule:     finding.Rule,
+		Subject:  finding.Subject,
+		Message:  finding.Message,
+		Expected: m.expected[finding.Rule],
+	}
+
+	var b strings.Builder
+
+	tmpl, err := template.New(finding.Rule).Parse(text)
+	if err != nil || tmpl.Execute(&b, data) != nil { // validated when loading
+		return finding.Message
+	}
+
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinCatalogs(t *testing.T) {
+	t.Parallel()
+
+	for locale, catalog := range builtinCatalogs {
+		for rule := range summaryDescriptions {
+			if catalog.Rules[rule] == "" {
+				t.Errorf("catalog %s does not describe the rule %s", locale, rule)
+			}
+		}
+
+		if catalog.Summary == "" || catalog.Commits == "" || catalog.Distinct == "" || catalog.Other == "" {
+			t.Errorf("catalog %s is incomplete: %+v", locale, catalog)
+		}
+	}
+}
+
+func TestMessageCatalog(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + `
+Locale: fr
+Messages:
+  fr:
+    Rules:
+      component: sans composant de la liste
+    Findings:
+      tag: "étiquette invalide, attendu {{.Expected}} ({{.Message}})"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := c.newReport(nil)
+	report.Add(findingT{Rule: ruleTag, Severity: severityError, SHA: "1111111111", Message: "no tag found"})
+	report.Add(findingT{Rule: ruleComponent, Severity: severityError, SHA: "2222222222", Message: "unknown component"})
+	report.Add(findingT{Rule: ruleComponent, Severity: severityError, SHA: "3333333333", Message: "unknown component"})
+
+	want := "étiquette invalide, attendu a tag among BUG, BUILD, CLEANUP, DOC, LICENSE, OPTIM, RELEASE, REORG, TEST, " +
+		"REVERT and 4 more (no tag found)"
+	if report.Findings[0].Message != want || report.Findings[1].Message != "unknown component" {
+		t.Errorf("Add() messages = %q, %q, want %q", report.Findings[0].Message, report.Findings[1].Message, want)
+	}
+
+	wantSummary := []string{
+		"component: 2 commit(s) sans composant de la liste: 22222222, 33333333",
+		"tag: 1 commit(s) avec une étiquette invalide ou absente (1 distinct(s): no tag): 11111111",
+	}
+	if got := report.failureSummary(); !reflect.DeepEqual(got, wantSummary) {
+		t.Errorf("failureSummary() = %q, want %q", got, wantSummary)
+	}
+}
+
+func TestValidateLocale(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{
+		"Locale: fre\n",
+		"Messages:\n  ja:\n    Rules:\n      tags: タグ\n",
+		"Messages:\n  ja:\n    Findings:\n      tag: \"{{.Unknown}}\"\n",
+	} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrLocale) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrLocale)
+		}
+	}
+
+	if _, err := parseCommitPolicy("Locale: ja\nMessages:\n  ja:\n    Summary: ルール別エラー\n"); err != nil {
+		t.Errorf("parseCommitPolicy() with a locale of Messages error = %v", err)
+	}
+}
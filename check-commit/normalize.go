@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const byteOrderMark = '\uFEFF'
+
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u200B', '\u200C', '\u200D', '\u2060':
+		return true
+	}
+
+	return false
+}
+
+// normalizeSubject returns the subject rules are evaluated against: NFC form, no byte
+// order marks or zero-width characters, and every run of exotic whitespace (tabs,
+// non-breaking or typographic spaces) folded into a single plain space. Each
+// transformation that was needed is reported so raw encoding problems stay visible.
+func normalizeSubject(raw string) (string, []string) {
+	issues := []string{}
+
+	if !norm.NFC.IsNormalString(raw) {
+		issues = append(issues, "subject is not in Unicode NFC form")
+		raw = norm.NFC.String(raw)
+	}
+
+	var b strings.Builder
+
+	seen := map[string]bool{}
+	report := func(issue string) {
+		if !seen[issue] {
+			seen[issue] = true
+			issues = append(issues, "subject contains "+issue)
+		}
+	}
+
+	inExoticSpace := false
+
+	for _, r := range raw {
+		switch {
+		case r == byteOrderMark:
+			report("byte order mark")
+		case isZeroWidth(r):
+			report(fmt.Sprintf("zero-width character %U", r))
+		case r != ' ' && unicode.IsSpace(r):
+			report(fmt.Sprintf("whitespace character %U", r))
+
+			if !inExoticSpace {
+				b.WriteRune(' ')
+			}
+
+			inExoticSpace = true
+
+			continue
+		default:
+			b.WriteRune(r)
+		}
+
+		inExoticSpace = false
+	}
+
+	return b.String(), issues
+}
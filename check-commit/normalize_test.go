@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSubject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		raw        string
+		want       string
+		wantIssues []string
+	}{
+		{
+			name:       "clean subject",
+			raw:        "BUG/MINOR: config: fix parsing",
+			want:       "BUG/MINOR: config: fix parsing",
+			wantIssues: []string{},
+		},
+		{
+			name:       "decomposed characters",
+			raw:        "MINOR: doc: cafe\u0301",
+			want:       "MINOR: doc: caf\u00e9",
+			wantIssues: []string{"subject is not in Unicode NFC form"},
+		},
+		{
+			name: "exotic whitespace run and zero-width space",
+			raw:  "MINOR:\u00a0\u2009doc:\u200b fix",
+			want: "MINOR: doc: fix",
+			wantIssues: []string{
+				"subject contains whitespace character U+00A0",
+				"subject contains whitespace character U+2009",
+				"subject contains zero-width character U+200B",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, issues := normalizeSubject(tt.raw)
+			if got != tt.want {
+				t.Errorf("normalizeSubject() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(issues, tt.wantIssues) {
+				t.Errorf("normalizeSubject() issues = %v, want %v", issues, tt.wantIssues)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+)
+
+// orgPolicyRepo is the repository of a GitHub organization holding its defaults, the
+// policy among them.
+const orgPolicyRepo = ".github"
+
+// policyExists reports whether the reader has a policy file or fragments.
+func policyExists(read policyReaderFunc) bool {
+	if _, err := read(policyFile); !errors.Is(err, ErrPolicyNotFound) {
+		return true
+	}
+
+	fragments, err := policyFragments(read)
+
+	return err != nil || len(fragments) > 0
+}
+
+// orgPolicyReader falls back to the policy of the .github repository of the owner of
+// the repository when the repository has none, on GitHub and with an API token. The
+// reader is returned unchanged otherwise.
+func orgPolicyReader(repoEnv string, read policyReaderFunc) policyReaderFunc {
+	owner := strings.SplitN(os.Getenv("GITHUB_REPOSITORY"), "/", 2)[0]
+
+	if repoEnv != GITHUB || owner == "" || policyExists(read) {
+		return read
+	}
+
+	repo := owner + "/" + orgPolicyRepo
+
+	if os.Getenv("API_TOKEN") == "" {
+		log.Printf("no policy file, and no API_TOKEN to read the policy of %s", repo)
+
+		return read
+	}
+
+	org, _ := forgeFileReader(repoEnv, repo, "")
+	if _, err := org(policyFile); err != nil {
+		log.Printf("no policy file, nor one in %s: %s", repo, err)
+
+		return read
+	}
+
+	log.Printf("no policy file, using the policy of %s", repo)
+
+	return org
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestPolicyExists(t *testing.T) {
+	t.Parallel()
+
+	reader := func(files map[string]string) policyReaderFunc {
+		return func(name string) (string, error) {
+			if content, ok := files[name]; ok {
+				return content, nil
+			}
+
+			return "", ErrPolicyNotFound
+		}
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{"none", map[string]string{}, false},
+		{"policy file", map[string]string{policyFile: "MaxCommits: 1\n"}, true},
+		{"fragments", map[string]string{".check-commit.d/": "base.yml", ".check-commit.d/base.yml": "{}"}, true},
+		{"no fragment", map[string]string{".check-commit.d/": "README.md"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := policyExists(reader(tt.files)); got != tt.want {
+			t.Errorf("%s: policyExists() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	// outside of GitHub, the repository policy, or the preset, applies
+	read := orgPolicyReader(LOCAL, reader(map[string]string{}))
+	if _, err := read(policyFile); err == nil {
+		t.Errorf("orgPolicyReader() read a policy outside of GitHub")
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// severityRank is the position of value in its PatchScopes list (1-based), so later
+// entries rank higher; values that are not a scope rank 0.
+func (c CommitPolicyConfig) severityRank(value string) int {
+	rank := 0
+
+	for _, scope := range c.PatchScopes {
+		for i, allowed := range scope {
+			if allowed == value && i+1 > rank {
+				rank = i + 1
+			}
+		}
+	}
+
+	return rank
+}
+
+var breakingFooterRegexp = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// isBreaking tells whether the commit has a breaking change footer, a tag prefix with the
+// breaking change marker of the TagFormat, or one of the BreakingValues.
+func (c CommitPolicyConfig) isBreaking(commit commitT) bool {
+	if breakingFooterRegexp.MatchString(commit.Body()) || hasBreakingMarker(c.tagRegexp(), commit.Subject()) {
+		return true
+	}
+
+	return hasAnyValue(c.subjectTags(commit.Subject()), c.BreakingValues)
+}
+
+func (c CommitPolicyConfig) tagRank(tag subjectTagT) int {
+	rank := c.severityRank(tag.Tag)
+	if severity := c.severityRank(tag.Severity); severity > rank {
+		rank = severity
+	}
+
+	return rank
+}
+
+// dominantTag returns the highest ranked leading tag of the commits, the first one on ties.
+func (c CommitPolicyConfig) dominantTag(commits []commitT) (subjectTagT, bool) {
+	dominant := subjectTagT{}
+	found := false
+
+	for _, commit := range commits {
+		tags := c.subjectTags(commit.Subject())
+		if len(tags) == 0 {
+			continue
+		}
+
+		if !found || c.tagRank(tags[0]) > c.tagRank(dominant) {
+			dominant = tags[0]
+			found = true
+		}
+	}
+
+	return dominant, found
+}
+
+// changelogSection looks up the full classification first (BUG/MAJOR), then the tag
+// alone, and defaults to the tag itself.
+func (c CommitPolicyConfig) changelogSection(tag subjectTagT) string {
+	if section, ok := c.ChangelogSections[tag.String()]; ok {
+		return section
+	}
+
+	if section, ok := c.ChangelogSections[tag.Tag]; ok {
+		return section
+	}
+
+	return tag.Tag
+}
+
+type outputT struct {
+	Name  string
+	Value string
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (c CommitPolicyConfig) outputs(report reportT) []outputT {
+	patchTypes := map[string]bool{}
+	scopes := map[string]bool{}
+	maxSeverity := ""
+	maxRank := 0
+	breaking := []string{}
+
+	for _, commit := range report.Commits {
+		for _, tag := range c.subjectTags(commit.Subject()) {
+			patchTypes[tag.Tag] = true
+
+			if tag.Severity != "" {
+				scopes[tag.Severity] = true
+			}
+
+			for _, value := range []string{tag.Tag, tag.Severity} {
+				if rank := c.severityRank(value); rank > maxRank {
+					maxRank = rank
+					maxSeverity = value
+				}
+			}
+		}
+
+		if c.isBreaking(commit) {
+			breaking = append(breaking, shortSHA(commit.SHA))
+		}
+	}
+
+	outputs := []outputT{
+		{Name: "patch_types", Value: strings.Join(sortedKeys(patchTypes), ",")},
+		{Name: "scopes", Value: strings.Join(sortedKeys(scopes), ",")},
+		{Name: "has_breaking", Value: strconv.FormatBool(len(breaking) > 0)},
+		{Name: "breaking_commits", Value: strings.Join(breaking, ",")},
+		{Name: "max_severity", Value: maxSeverity},
+		{Name: "violations_count", Value: strconv.Itoa(report.Count(severityError))},
+	}
+
+	// the classification is only meaningful once every subject has been validated
+	if report.Count(severityError) == 0 {
+		if dominant, ok := c.dominantTag(report.Commits); ok {
+			outputs = append(outputs,
+				outputT{Name: "classification", Value: dominant.String()},
+				outputT{Name: "changelog_section", Value: c.changelogSection(dominant)},
+			)
+		}
+	}
+
+	return outputs
+}
+
+// writeGithubOutputs sets step outputs through the GITHUB_OUTPUT file, it is a no-op
+// outside of GitHub Actions.
+func writeGithubOutputs(outputs []outputT) error {
+	const outputFileMode = 0o644
+
+	filename := os.Getenv("GITHUB_OUTPUT")
+	if filename == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, outputFileMode)
+	if err != nil {
+		return fmt.Errorf("error opening output file: %w", err)
+	}
+	defer f.Close()
+
+	for _, output := range outputs {
+		if _, err := fmt.Fprintf(f, "%s=%s\n", output.Name, output.Value); err != nil {
+			return fmt.Errorf("error writing output %s: %w", output.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutputs(t *testing.T) {
+	t.Parallel()
+
+	c, _ := LoadCommitPolicy("")
+
+	report := reportT{
+		Commits: []commitT{
+			{Message: "BUG/MEDIUM: config: fix crash on empty section"},
+			{SHA: "0123456789abcdef", Message: "MINOR: config: add new keyword\n\nBREAKING CHANGE: old keyword is gone"},
+			{Message: "DOC: config: document new keyword"},
+		},
+		Findings: []findingT{
+			{Rule: ruleTag, Severity: severityError},
+			{Rule: ruleCustomPrefix + "x", Severity: severityWarning},
+		},
+	}
+
+	want := []outputT{
+		{Name: "patch_types", Value: "BUG,DOC,MINOR"},
+		{Name: "scopes", Value: "MEDIUM"},
+		{Name: "has_breaking", Value: "true"},
+		{Name: "breaking_commits", Value: "01234567"},
+		{Name: "max_severity", Value: "MEDIUM"},
+		{Name: "violations_count", Value: "1"},
+	}
+
+	if got := c.outputs(report); !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs() = %v, want %v", got, want)
+	}
+}
+
+func TestIsBreaking(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(conventionalCommitsConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"feat!: drop the legacy syntax", true},
+		{"feat(parser)!: drop the legacy syntax", true},
+		{"feat(parser): accept tabs", false},
+		{"fix: reject 'a!: b'", false},
+		{"MINOR: config: add new keyword\n\nBREAKING CHANGE: old keyword is gone", true},
+		{"MINOR: config: add new keyword", false},
+	}
+
+	for _, tt := range tests {
+		if got := c.isBreaking(commitT{Message: tt.message}); got != tt.want {
+			t.Errorf("isBreaking(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestOutputsClassification(t *testing.T) {
+	t.Parallel()
+
+	c, _ := LoadCommitPolicy("")
+	c.ChangelogSections = map[string]string{"BUG": "Bug fixes", "BUG/CRITICAL": "Security and critical fixes"}
+
+	tests := []struct {
+		name     string
+		subjects []string
+		want     []outputT
+	}{
+		{
+			name:     "severity wins over feature",
+			subjects: []string{"MINOR: config: add new keyword", "BUG/MEDIUM: config: fix crash on empty section"},
+			want: []outputT{
+				{Name: "classification", Value: "BUG/MEDIUM"},
+				{Name: "changelog_section", Value: "Bug fixes"},
+			},
+		},
+		{
+			name:     "full classification mapping",
+			subjects: []string{"BUG/CRITICAL: config: fix crash on empty section"},
+			want: []outputT{
+				{Name: "classification", Value: "BUG/CRITICAL"},
+				{Name: "changelog_section", Value: "Security and critical fixes"},
+			},
+		},
+		{
+			name:     "unranked tags default to the first one",
+			subjects: []string{"DOC: config: document new keyword", "CLEANUP: config: remove dead code"},
+			want: []outputT{
+				{Name: "classification", Value: "DOC"},
+				{Name: "changelog_section", Value: "DOC"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			report := reportT{}
+			for _, subject := range tt.subjects {
+				report.Commits = append(report.Commits, commitT{Message: subject})
+			}
+			got := c.outputs(report)
+			if !reflect.DeepEqual(got[len(got)-2:], tt.want) {
+				t.Errorf("outputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	report := reportT{
+		Commits:  []commitT{{Message: "BUG/MEDIUM: config: fix crash on empty section"}},
+		Findings: []findingT{{Rule: ruleTag, Severity: severityError}},
+	}
+	if got := c.outputs(report); len(got) != 6 {
+		t.Errorf("outputs() of a failed check = %v, want no classification", got)
+	}
+}
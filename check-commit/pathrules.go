@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// pathRuleT requires the commits only touching files that match Paths, e.g. doc/** or
+// .github/**, to carry one of Tags, e.g. DOC or CI.
+type pathRuleT struct {
+	Paths    []string `yaml:"Paths"`
+	Tags     []string `yaml:"Tags"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrPathRuleConfig = errors.New("invalid path rule")
+
+func (p pathRuleT) validate() error {
+	if len(p.Paths) == 0 || len(p.Tags) == 0 {
+		return fmt.Errorf("path rule for [%s] requires both Paths and Tags: %w", strings.Join(p.Paths, ", "),
+			ErrPathRuleConfig)
+	}
+
+	for _, pattern := range p.Paths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("path rule: pattern '%s': %s: %w", pattern, err, ErrPathRuleConfig)
+		}
+	}
+
+	if !validSeverity(p.Severity) {
+		return fmt.Errorf("path rule: unknown severity '%s': %w", p.Severity, ErrPathRuleConfig)
+	}
+
+	return nil
+}
+
+func (p pathRuleT) severity() string {
+	if p.Severity == "" {
+		return severityError
+	}
+
+	return p.Severity
+}
+
+var ErrPathRule = errors.New("tag does not match the changed paths")
+
+// Check applies to the commits whose changed files, old names of renames included, all
+// match the Paths of the rule.
+func (p pathRuleT) Check(commit commitT) error {
+	if !commit.HasDiff || len(commit.Files) == 0 {
+		return nil
+	}
+
+	for _, file := range commit.Files {
+		for _, name := range []string{file.Path, file.OldPath} {
+			if name != "" && !matchAnyGlob(p.Paths, name) {
+				return nil
+			}
+		}
+	}
+
+	if hasAnyValue(commit.Tags(), p.Tags) {
+		return nil
+	}
+
+	return fmt.Errorf("commit only changes [%s], please use one of the [%s] tags: %w", strings.Join(p.Paths, ", "),
+		strings.Join(p.Tags, ", "), ErrPathRule)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPathRuleCheck(t *testing.T) {
+	t.Parallel()
+
+	p := pathRuleT{Paths: []string{"doc/**", "*.md"}, Tags: []string{"DOC"}}
+
+	files := func(names ...string) []fileDiffT {
+		result := []fileDiffT{}
+		for _, name := range names {
+			result = append(result, fileDiffT{Path: name, OldPath: name, Status: fileModified})
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		commit  commitT
+		wantErr bool
+	}{
+		{"documentation", commitT{Message: "DOC: config: fix a typo", Files: files("doc/configuration.txt"), HasDiff: true}, false},
+		{"untagged", commitT{Message: "MINOR: config: fix a typo", Files: files("doc/intro.txt", "README.md"), HasDiff: true}, true},
+		{"chained tag", commitT{Message: "BUG/MINOR: DOC: fix a typo", Files: files("doc/intro.txt"), HasDiff: true}, false},
+		{"other paths too", commitT{Message: "MINOR: config: add an option",
+			Files: files("doc/configuration.txt", "src/cfgparse.c"), HasDiff: true}, false},
+		{"moved in", commitT{Message: "MINOR: doc: move the notes", HasDiff: true,
+			Files: []fileDiffT{{Path: "doc/notes.txt", OldPath: "src/notes.txt", Status: fileRenamed}}}, false},
+		{"diff not loaded", commitT{Message: "MINOR: config: fix a typo"}, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := p.Check(tt.commit)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrPathRule)) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, config := range []string{
+		"PathRules:\n  - Tags: [DOC]\n",
+		"PathRules:\n  - Paths: [doc/**]\n",
+		"PathRules:\n  - Paths: ['[']\n    Tags: [DOC]\n",
+		"PathRules:\n  - Paths: [doc/**]\n    Tags: [DOC]\n    Severity: fatal\n",
+	} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrPathRuleConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrPathRuleConfig)
+		}
+	}
+}
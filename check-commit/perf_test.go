@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	perfCommits = 10000
+	perfBudget  = 10 * time.Second
+)
+
+// newLargeTestRepo creates a repository with many small commits through fast-import,
+// committing them one by one would take minutes.
+func newLargeTestRepo(tb testing.TB, count int) string {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	if _, err := runGit(dir, "init", "-q"); err != nil {
+		tb.Fatal(err)
+	}
+
+	var stream strings.Builder
+
+	subjects := []string{"BUG/MINOR: mux: fix crash %d on close", "REORG: mux: move helper %d", "CLEANUP: mux: fix typo %d"}
+
+	for i := 1; i <= count; i++ {
+		message := fmt.Sprintf(subjects[i%len(subjects)], i) + "\n\nLonger explanation of the change.\n"
+		content := fmt.Sprintf("int value%d = %d;\n", i, i)
+
+		fmt.Fprintf(&stream, "commit refs/heads/master\nmark :%d\ncommitter A <a@example.com> %d +0000\n", i, 1600000000+i)
+		fmt.Fprintf(&stream, "data %d\n%s\n", len(message), message)
+
+		if i > 1 {
+			fmt.Fprintf(&stream, "from :%d\n", i-1)
+		}
+
+		fmt.Fprintf(&stream, "M 644 inline src/file%d.c\ndata %d\n%s\n", i%50, len(content), content)
+	}
+
+	if _, err := runGitInput(dir, stream.String(), "fast-import", "--quiet"); err != nil {
+		tb.Fatal(err)
+	}
+
+	return dir
+}
+
+func perfPolicy(tb testing.TB) CommitPolicyConfig {
+	tb.Helper()
+
+	c, err := parseCommitPolicy(defaultConf + `
+DiffHeuristics:
+  Reorg: {}
+  Cleanup: {}
+Encoding:
+  Verify: true
+CustomRules:
+  - Name: no-wip
+    Regex: WIP
+    Match: must-not
+`)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return c
+}
+
+func checkRepository(tb testing.TB, c CommitPolicyConfig, repo string) reportT {
+	tb.Helper()
+
+	commits, err := gitLogCommits(repo, "HEAD")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	c.loadDiffs(repo, commits)
+
+	report := reportT{Commits: commits}
+	c.checkCommits(commits, &report)
+	c.checkEncodings(repo, commits, &report)
+
+	return report
+}
+
+// TestPerformanceBudget guards the audit and release branch use cases, where whole
+// histories are checked at once.
+func TestPerformanceBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the performance budget in short mode")
+	}
+
+	t.Parallel()
+
+	repo := newLargeTestRepo(t, perfCommits)
+	c := perfPolicy(t)
+
+	start := time.Now()
+	report := checkRepository(t, c, repo)
+	elapsed := time.Since(start)
+
+	if len(report.Commits) != perfCommits {
+		t.Fatalf("checked %d commits, want %d", len(report.Commits), perfCommits)
+	}
+
+	if elapsed > perfBudget {
+		t.Errorf("checking %d commits took %s, over the budget of %s", perfCommits, elapsed, perfBudget)
+	}
+}
+
+func BenchmarkCheckRepository(b *testing.B) {
+	repo := newLargeTestRepo(b, perfCommits)
+	c := perfPolicy(b)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		checkRepository(b, c, repo)
+	}
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/xanzy/go-gitlab"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const policyFile = ".check-commit.yml"
+
+// policyExtensions are the extensions of the supported policy formats, by preference.
+var policyExtensions = []string{".yml", ".yaml", ".json", ".toml"}
+
+// requestBase returns the revision the checked commits are based on: the base of the
+// pull/merge request, or the start of the checked range.
+func requestBase(repoEnv, revRange string) string {
+	switch repoEnv {
+	case GITHUB:
+		var event github.PullRequestEvent
+		if err := readGithubEvent(&event); err == nil && event.GetPullRequest().GetBase().GetSHA() != "" {
+			return event.GetPullRequest().GetBase().GetSHA()
+		}
+
+		return os.Getenv("GITHUB_BASE_REF")
+	case GITLAB:
+		if sha := os.Getenv("CI_MERGE_REQUEST_DIFF_BASE_SHA"); sha != "" {
+			return sha
+		}
+
+		return os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME")
+	case LOCAL:
+		if i := strings.Index(revRange, ".."); i > 0 {
+			return revRange[:i]
+		}
+	}
+
+	return ""
+}
+
+var ErrPolicyNotFound = errors.New("no policy file")
+
+// gitShowFile returns the content of a file at a revision of the clone, or the entries
+// of a directory given with a trailing slash. It fails with ErrGitCommand when the
+// revision is not available locally, and with ErrPolicyNotFound when the file does not
+// exist at that revision.
+func gitShowFile(repoPath, rev, name string) (string, error) {
+	for _, candidate := range []string{rev, "origin/" + rev} {
+		if _, err := runGit(repoPath, "cat-file", "-e", candidate+"^{commit}"); err != nil {
+			continue
+		}
+
+		object := candidate + ":" + strings.TrimSuffix(name, "/")
+		if _, err := runGit(repoPath, "cat-file", "-e", object); err != nil {
+			return "", fmt.Errorf("%s at %s: %w", name, rev, ErrPolicyNotFound)
+		}
+
+		if strings.HasSuffix(name, "/") {
+			out, err := runGit(repoPath, "ls-tree", "--name-only", object)
+
+			return strings.TrimSpace(out), err
+		}
+
+		return runGit(repoPath, "show", object)
+	}
+
+	return "", fmt.Errorf("revision %s is not available locally: %w", rev, ErrGitCommand)
+}
+
+func fetchGithubFile(rev, name string) (string, error) {
+	owner, project, _, err := githubPullRequest()
+	if err != nil {
+		return "", err
+	}
+
+	return fetchGithubRepoFile(owner, project, rev, name)
+}
+
+func fetchGithubRepoFile(owner, project, rev, name string) (string, error) {
+	ctx := context.Background()
+	client := newGithubClient(ctx)
+
+	file, dir, resp, err := client.Repositories.GetContents(ctx, owner, project, strings.TrimSuffix(name, "/"),
+		&github.RepositoryContentGetOptions{Ref: rev})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s at %s: %w", name, rev, ErrPolicyNotFound)
+	} else if err != nil {
+		return "", fmt.Errorf("error fetching %s at %s: %w", name, rev, err)
+	}
+
+	if strings.HasSuffix(name, "/") {
+		names := []string{}
+
+		for _, entry := range dir {
+			if entry.GetType() == "file" {
+				names = append(names, entry.GetName())
+			}
+		}
+
+		return strings.Join(names, "\n"), nil
+	}
+
+	return file.GetContent()
+}
+
+func fetchGitlabFile(rev, name string) (string, error) {
+	projectID, _, err := gitlabMergeRequest()
+	if err != nil {
+		return "", err
+	}
+
+	return fetchGitlabProjectFile(projectID, rev, name)
+}
+
+func fetchGitlabProjectFile(project interface{}, rev, name string) (string, error) {
+	client, err := newGitlabClient()
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasSuffix(name, "/") {
+		return fetchGitlabProjectDir(client, project, rev, name)
+	}
+
+	data, resp, err := client.RepositoryFiles.GetRawFile(project, name, &gitlab.GetRawFileOptions{Ref: &rev})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s at %s: %w", name, rev, ErrPolicyNotFound)
+	} else if err != nil {
+		return "", fmt.Errorf("error fetching %s at %s: %w", name, rev, err)
+	}
+
+	return string(data), nil
+}
+
+func fetchGitlabProjectDir(client *gitlab.Client, project interface{}, rev, name string) (string, error) {
+	dir := strings.TrimSuffix(name, "/")
+
+	nodes, resp, err := client.Repositories.ListTree(project, &gitlab.ListTreeOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100}, Path: &dir, Ref: &rev,
+	})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s at %s: %w", name, rev, ErrPolicyNotFound)
+	} else if err != nil {
+		return "", fmt.Errorf("error fetching %s at %s: %w", name, rev, err)
+	}
+
+	names := []string{}
+
+	for _, node := range nodes {
+		if node.Type == "blob" {
+			names = append(names, node.Name)
+		}
+	}
+
+	return strings.Join(names, "\n"), nil
+}
+
+// readBaseFile reads a file at the base revision, from the clone when it has the
+// revision and through the API otherwise.
+func readBaseFile(repoEnv, repoPath, base, name string) (string, error) {
+	content, err := gitShowFile(repoPath, base, name)
+	if err == nil || !errors.Is(err, ErrGitCommand) {
+		return content, err
+	}
+
+	switch repoEnv {
+	case GITHUB:
+		return fetchGithubFile(base, name)
+	case GITLAB:
+		return fetchGitlabFile(base, name)
+	}
+
+	return "", err
+}
+
+// policyReaderFunc reads a file of the policy, failing with ErrPolicyNotFound when it
+// does not exist.
+type policyReaderFunc func(name string) (string, error)
+
+func localPolicyReader(repoPath string) policyReaderFunc {
+	if !gitHasWorkTree(repoPath) {
+		return func(name string) (string, error) {
+			return gitShowFile(repoPath, "HEAD", name)
+		}
+	}
+
+	return func(name string) (string, error) {
+		if strings.HasSuffix(name, "/") {
+			return readDirEntries(path.Join(repoPath, name))
+		}
+
+		data, err := ioutil.ReadFile(path.Join(repoPath, name))
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s: %w", err, ErrPolicyNotFound)
+		} else if err != nil {
+			return "", fmt.Errorf("error reading %s: %w", name, err)
+		}
+
+		return string(data), nil
+	}
+}
+
+// readDirEntries returns the names of the files of the directory, one per line.
+func readDirEntries(dir string) (string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%s: %w", err, ErrPolicyNotFound)
+	} else if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", dir, err)
+	}
+
+	names := []string{}
+
+	for _, info := range infos {
+		if info.Mode().IsRegular() {
+			names = append(names, info.Name())
+		}
+	}
+
+	return strings.Join(names, "\n"), nil
+}
+
+// renamedPolicyReader reads the policy, and its signature, from another file, and its
+// fragments from the directory of that file.
+func renamedPolicyReader(read policyReaderFunc, name string) policyReaderFunc {
+	return func(file string) (string, error) {
+		switch {
+		case strings.HasPrefix(file, policyFile):
+			file = name + strings.TrimPrefix(file, policyFile)
+		case strings.HasPrefix(file, policyFragmentsDir+"/") && path.Dir(name) != ".":
+			file = path.Dir(name) + "/" + file
+		}
+
+		return read(file)
+	}
+}
+
+// policyCandidates lists where the policy is looked for: in the directory of the
+// repository path and each of its parents up to the root, then in .github.
+func policyCandidates(dir string) []string {
+	candidates := []string{}
+
+	for dir != "" && dir != "." {
+		candidates = append(candidates, path.Join(dir, policyFile))
+		dir = path.Dir(dir)
+	}
+
+	candidates = append(candidates, policyFile, path.Join(".github", policyFile), ".github/check-commit.yml")
+
+	// each location also accepts the file in the other formats
+	all := []string{}
+
+	for _, candidate := range candidates {
+		base := strings.TrimSuffix(candidate, ".yml")
+		for _, ext := range policyExtensions {
+			all = append(all, base+ext)
+		}
+	}
+
+	return all
+}
+
+// discoverPolicy returns the first of the candidates that exists, or the default
+// location when none does.
+func discoverPolicy(read policyReaderFunc, candidates []string) string {
+	for _, name := range candidates {
+		if _, err := read(name); !errors.Is(err, ErrPolicyNotFound) {
+			return name // found, or present but unreadable, which loading will report
+		}
+	}
+
+	return policyFile
+}
+
+// locatePolicy points the reader at the policy given by --config, or discovered.
+// Absolute paths outside of the repository are read from the file system.
+func (opts optionsT) locatePolicy(read policyReaderFunc) (policyReaderFunc, error) {
+	name := filepath.ToSlash(opts.config)
+
+	if filepath.IsAbs(opts.config) {
+		root, err := filepath.Abs(opts.repoPath)
+		if err == nil {
+			name, err = filepath.Rel(root, opts.config)
+		}
+
+		if err != nil || name == ".." || strings.HasPrefix(name, "../") {
+			if opts.policyFromBase {
+				return nil, fmt.Errorf("policy %s is not part of the repository: %w", opts.config, ErrPolicyBase)
+			}
+
+			return renamedPolicyReader(localPolicyReader(filepath.Dir(opts.config)), filepath.Base(opts.config)), nil
+		}
+
+		name = filepath.ToSlash(name)
+	}
+
+	if name == "" {
+		name = discoverPolicy(read, policyCandidates(opts.policyDir))
+	}
+
+	if name == policyFile {
+		return read, nil
+	}
+
+	log.Printf("using the policy %s", name)
+
+	return renamedPolicyReader(read, name), nil
+}
+
+var ErrPolicyBase = errors.New("unable to read the policy of the base revision")
+
+func basePolicyReader(repoEnv, repoPath, revRange string) (policyReaderFunc, error) {
+	base := requestBase(repoEnv, revRange)
+	if base == "" {
+		return nil, fmt.Errorf("no base revision found: %w", ErrPolicyBase)
+	}
+
+	log.Printf("using the policy of the base revision %s", base)
+
+	return func(name string) (string, error) {
+		content, err := readBaseFile(repoEnv, repoPath, base, name)
+		if err != nil && !errors.Is(err, ErrPolicyNotFound) {
+			return "", fmt.Errorf("%s: %w", err, ErrPolicyBase)
+		}
+
+		return content, err
+	}, nil
+}
+
+// localBaseRevision returns the base revision as the clone knows it, for the files the
+// policy of the base revision refers to be read from the same tree.
+func localBaseRevision(repoEnv, repoPath, revRange string) (string, error) {
+	base := requestBase(repoEnv, revRange)
+	if base == "" {
+		return "", fmt.Errorf("no base revision found: %w", ErrPolicyBase)
+	}
+
+	for _, candidate := range []string{base, "origin/" + base} {
+		if _, err := runGit(repoPath, "cat-file", "-e", candidate+"^{commit}"); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("revision %s is not available locally: %w", base, ErrPolicyBase)
+}
+
+// readVerifiedPolicy reads the policy and its fragments. When a minisign public key is
+// given, the policy must come with a valid signature in the .minisig file next to it
+// and list the digests of the fragments; otherwise the preset, HAProxy's by default,
+// applies when there is no policy file. A policy that names no preset of its own
+// builds on the given one, and the profile, if any, is applied last.
+func readVerifiedPolicy(read policyReaderFunc, publicKey, preset, profile string) (string, error) {
+	var verify func(name, content string) error
+
+	if publicKey != "" {
+		key, err := parseMinisignKey(publicKey)
+		if err != nil {
+			return "", err
+		}
+
+		verify = func(name, content string) error {
+			signature, err := read(name + ".minisig")
+			if err != nil {
+				return fmt.Errorf("policy must be signed: %s: %w", err, ErrPolicySignature)
+			}
+
+			if err := key.verify([]byte(content), signature); err != nil {
+				return err
+			}
+
+			log.Printf("policy signature of %s verified", name)
+
+			return nil
+		}
+	}
+
+	config, err := readPolicyFiles(read, verify)
+
+	switch {
+	case errors.Is(err, ErrPolicyNotFound) && publicKey == "":
+		if preset == "" {
+			preset = defaultPreset
+		}
+
+		log.Printf("warning: using built-in fallback configuration with the %s preset (%s)", preset, err)
+
+		return presetConfig(preset)
+	case errors.Is(err, ErrPolicyNotFound):
+		return "", fmt.Errorf("a signed policy is required: %s: %w", err, ErrPolicySignature)
+	case err != nil:
+		return "", err
+	}
+
+	if config, err = resolveExtends(config, publicKey); err != nil {
+		return "", err
+	}
+
+	if config, err = applyPreset(config, preset); err != nil {
+		return "", err
+	}
+
+	if config, err = applyBranchOverrides(config, targetBranch()); err != nil {
+		return "", err
+	}
+
+	return applyProfile(config, profile)
+}
+
+var tomlStartRegexp = regexp.MustCompile(`^(\[|[A-Za-z0-9_"'-][A-Za-z0-9_."' -]*=)`)
+
+// policyToYAML converts policies written in JSON or TOML to YAML, the format the rest
+// of the configuration handling works with. The format is told from the first line
+// that is neither blank nor a comment: JSON objects start with '{', TOML documents
+// with a table header or a key = value pair.
+func policyToYAML(config string) (string, error) {
+	first := ""
+
+	for _, line := range strings.Split(config, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			first = line
+
+			break
+		}
+	}
+
+	var document interface{}
+
+	switch {
+	case strings.HasPrefix(first, "{"):
+		if err := json.Unmarshal([]byte(config), &document); err != nil {
+			return "", fmt.Errorf("error loading commit policy: invalid JSON: %w", err)
+		}
+	case tomlStartRegexp.MatchString(first):
+		table, err := parseTOML(config)
+		if err != nil {
+			return "", fmt.Errorf("error loading commit policy: %w", err)
+		}
+
+		document = table
+	default:
+		return config, nil
+	}
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func loadPolicy(read policyReaderFunc, publicKey, preset, profile string) (CommitPolicyConfig, error) {
+	config, err := readVerifiedPolicy(read, publicKey, preset, profile)
+	if err != nil {
+		return CommitPolicyConfig{}, err
+	}
+
+	if publicKey == "" {
+		if config, err = applyEnvOverrides(config, os.Environ()); err != nil {
+			return CommitPolicyConfig{}, err
+		}
+	} else if overrides, _ := envOverrides(os.Environ()); len(overrides) > 0 {
+		log.Printf("warning: the policy is signed, the environment overrides are ignored")
+	}
+
+	return parseCommitPolicy(config)
+}
+
+// loadEffectivePolicy loads the policy the options designate along with the files of
+// the repository it refers to.
+func loadEffectivePolicy(opts optionsT, repoEnv string) (CommitPolicyConfig, error) {
+	c, err := loadDesignatedPolicy(opts, repoEnv)
+	if err != nil {
+		return c, err
+	}
+
+	return opts.resolvePolicyFiles(c, repoEnv)
+}
+
+// loadDesignatedPolicy loads the policy the options designate: the local one or the
+// one of the base revision, possibly as overrides of a central policy, else the one of
+// the organization.
+func loadDesignatedPolicy(opts optionsT, repoEnv string) (CommitPolicyConfig, error) {
+	read := localPolicyReader(opts.repoPath)
+
+	if opts.policyFromBase {
+		var err error
+		if read, err = basePolicyReader(repoEnv, opts.repoPath, opts.revRange); err != nil {
+			return CommitPolicyConfig{}, err
+		}
+	}
+
+	read, err := opts.locatePolicy(read)
+	if err != nil {
+		return CommitPolicyConfig{}, err
+	}
+
+	if opts.centralPolicy == "" {
+		if opts.orgPolicy && opts.config == "" {
+			read = orgPolicyReader(repoEnv, read)
+		}
+
+		return loadPolicy(read, opts.policyKey, opts.preset, opts.profile)
+	}
+
+	central, err := centralPolicyReader(repoEnv, opts.centralPolicy)
+	if err != nil {
+		return CommitPolicyConfig{}, err
+	}
+
+	return loadCentralPolicy(central, read, opts.policyKey, opts.preset, opts.profile)
+}
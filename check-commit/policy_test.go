@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBasePolicyReader(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "MINOR: policy: first commit of the test repository")
+
+	commitPolicyFile := func(content, message string) {
+		if err := ioutil.WriteFile(filepath.Join(repo, policyFile), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, args := range [][]string{{"add", policyFile}, {"commit", "-q", "-m", message}} {
+			if _, err := runGit(repo, args...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	commitPolicyFile("MaxCommits: 1\n", "MINOR: policy: limit the number of commits")
+	commitPolicyFile("MaxCommits: 100\n", "MINOR: policy: relax the number of commits")
+
+	tests := []struct {
+		revRange       string
+		wantMaxCommits int
+		wantDefault    bool
+		wantErr        error
+	}{
+		{revRange: "HEAD~1..HEAD", wantMaxCommits: 1},
+		{revRange: "HEAD~2..HEAD", wantDefault: true},
+		{revRange: "unknown..HEAD", wantErr: ErrPolicyBase},
+		{revRange: "HEAD", wantErr: ErrPolicyBase},
+	}
+
+	for _, tt := range tests {
+		var c CommitPolicyConfig
+
+		read, err := basePolicyReader(LOCAL, repo, tt.revRange)
+		if err == nil {
+			c, err = loadPolicy(read, "", "", "")
+		}
+
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("loadPolicy(%s) error = %v, want %v", tt.revRange, err, tt.wantErr)
+
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if c.MaxCommits != tt.wantMaxCommits || (len(c.PatchTypes) > 0) != tt.wantDefault {
+			t.Errorf("loadPolicy(%s) = %+v", tt.revRange, c)
+		}
+	}
+}
+
+func TestLocalBaseRevision(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+
+	for _, words := range []string{"darn\n", "heck\n"} {
+		if err := ioutil.WriteFile(filepath.Join(repo, "denylist.txt"), []byte(words), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "MINOR: denylist: update the denylist"}} {
+			if _, err := runGit(repo, args...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	c, err := parseCommitPolicy("Denylist:\n  File: denylist.txt\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rev, err := localBaseRevision(LOCAL, repo, "HEAD~1..HEAD")
+	if err == nil {
+		c, err = c.resolveRepositoryFiles(repo, rev)
+	}
+
+	if err != nil || len(c.Denylist.words) != 1 || c.Denylist.words[0] != "darn" {
+		t.Errorf("resolveRepositoryFiles(%s) = %v, %v, want [darn]", rev, c.Denylist.words, err)
+	}
+
+	for _, revRange := range []string{"unknown..HEAD", "HEAD"} {
+		if _, err := localBaseRevision(LOCAL, repo, revRange); !errors.Is(err, ErrPolicyBase) {
+			t.Errorf("localBaseRevision(%s) error = %v, want %v", revRange, err, ErrPolicyBase)
+		}
+	}
+}
+
+func TestLoadPolicySigned(t *testing.T) {
+	t.Parallel()
+
+	reader := func(files map[string]string) policyReaderFunc {
+		return func(name string) (string, error) {
+			if content, ok := files[name]; ok {
+				return content, nil
+			}
+
+			return "", ErrPolicyNotFound
+		}
+	}
+
+	const key = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "no policy", files: map[string]string{}},
+		{name: "unsigned policy", files: map[string]string{policyFile: "MaxCommits: 1\n"}},
+		{name: "bad signature", files: map[string]string{policyFile: "MaxCommits: 1\n", policyFile + ".minisig": "junk"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := loadPolicy(reader(tt.files), key, "", ""); !errors.Is(err, ErrPolicySignature) {
+			t.Errorf("%s: loadPolicy() error = %v, want ErrPolicySignature", tt.name, err)
+		}
+	}
+
+	if c, err := loadPolicy(reader(map[string]string{policyFile: "MaxCommits: 1\n"}), "", "", ""); err != nil || c.MaxCommits != 1 {
+		t.Errorf("loadPolicy() unsigned = %+v, %v", c, err)
+	}
+}
+
+func TestLocatePolicy(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+	outside := filepath.Join(t.TempDir(), "policy.yml")
+
+	writeTestFiles(t, repo, map[string]string{
+		".github/check-commit.yml": "MaxCommits: 1\n",
+		"pkg/.check-commit.yml":    "MaxCommits: 2\n",
+		"ci/policy.yml":            "MaxCommits: 3\n",
+		outside:                    "MaxCommits: 4\n",
+		"tools/.check-commit.json": `{"MaxCommits": 5}`,
+		"tools/.check-commit.toml": "MaxCommits = 6\n",
+	})
+
+	if err := os.MkdirAll(filepath.Join(repo, "pkg", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		opts           optionsT
+		wantMaxCommits int
+	}{
+		{"root", optionsT{}, 1},
+		{"subdirectory", optionsT{policyDir: "pkg/api"}, 2},
+		{"relative config", optionsT{config: "ci/policy.yml", policyDir: "pkg"}, 3},
+		{"absolute config", optionsT{config: filepath.Join(repo, "ci", "policy.yml")}, 3},
+		{"config outside", optionsT{config: outside}, 4},
+		{"json", optionsT{policyDir: "tools"}, 5},
+		{"toml", optionsT{config: "tools/.check-commit.toml"}, 6},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.opts.repoPath = repo
+
+			read, err := tt.opts.locatePolicy(localPolicyReader(repo))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := loadPolicy(read, "", "", "")
+			if err != nil || c.MaxCommits != tt.wantMaxCommits {
+				t.Errorf("loadPolicy() = %d, %v, want %d", c.MaxCommits, err, tt.wantMaxCommits)
+			}
+		})
+	}
+
+	opts := optionsT{repoPath: repo, config: outside, policyFromBase: true}
+	if _, err := opts.locatePolicy(localPolicyReader(repo)); !errors.Is(err, ErrPolicyBase) {
+		t.Errorf("locatePolicy() outside of the repository from base = %v", err)
+	}
+
+	if prefix := gitPathPrefix(filepath.Join(repo, "pkg", "api")); prefix != "pkg/api" {
+		t.Errorf("gitPathPrefix() = '%s', want pkg/api", prefix)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	presetKey     = "Preset"
+	defaultPreset = "haproxy"
+
+	kernelConf = `
+---
+HelpText: "Please refer to https://www.kernel.org/doc/html/latest/process/submitting-patches.html"
+CustomRules:
+  - Name: subsystem-prefix
+    Regex: '^[A-Za-z0-9_.,/-]+: \S'
+    Message: "the subject must start with the subsystem it changes, e.g. 'net: ipv4: '"
+  - Name: no-trailing-period
+    Regex: '\.$'
+    Match: must-not
+    Message: "the subject must not end with a period"
+  - Name: signed-off-by
+    Target: trailer
+    Regex: '^Signed-off-by: .+ <[^>]+@[^>]+>$'
+    Message: "the commit must be signed off to certify its origin, see git commit --signoff"
+`
+)
+
+// conventionalTypes are the types of the Conventional Commits preset.
+var conventionalTypes = []string{
+	"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+}
+
+// conventionalTypeRule returns the custom rule accepting the subjects of the given
+// Conventional Commits types, as a YAML list of custom rules.
+func conventionalTypeRule(types []string) string {
+	return fmt.Sprintf(`CustomRules:
+  - Name: conventional-type
+    Regex: '^(%s)(\([a-z0-9][a-z0-9 ._/-]*\))?!?: \S'
+    Message: "the subject must start with 'type: ' or 'type(scope): ', type being one of %s or %s"
+`, strings.Join(types, "|"), strings.Join(types[:len(types)-1], ", "), types[len(types)-1])
+}
+
+var conventionalCommitsConf = `
+---
+HelpText: "Please refer to https://www.conventionalcommits.org/en/v1.0.0/"
+TagFormat: '^(?P<tag>[a-z]+)(\((?P<severity>[a-z0-9][a-z0-9 ._/-]*)\))?(?P<breaking>!)?: '
+` + conventionalTypeRule(conventionalTypes)
+
+// presets are the built-in configurations a policy can build on.
+var presets = map[string]string{
+	"conventional-commits": conventionalCommitsConf,
+	"haproxy":              defaultConf,
+	"kernel":               kernelConf,
+}
+
+var ErrPreset = errors.New("unknown preset")
+
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func presetConfig(name string) (string, error) {
+	config, ok := presets[name]
+	if !ok {
+		return "", fmt.Errorf("'%s', expected one of %s: %w", name, strings.Join(presetNames(), ", "), ErrPreset)
+	}
+
+	return config, nil
+}
+
+// applyPreset returns the policy merged over the preset it names with Preset, or over
+// the preset given as fallback when it names none.
+func applyPreset(config, fallback string) (string, error) {
+	document := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return config, nil // reported when loading the policy
+	}
+
+	name := fallback
+
+	if value, ok := document[presetKey]; ok {
+		if name, ok = value.(string); !ok {
+			return "", fmt.Errorf("%s must be the name of a preset: %w", presetKey, ErrPreset)
+		}
+	}
+
+	if name == "" {
+		return config, nil
+	}
+
+	parentConfig, err := presetConfig(name)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("using the %s preset", name)
+
+	parent := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(parentConfig), &parent); err != nil {
+		return "", fmt.Errorf("error loading the %s preset: %w", name, err)
+	}
+
+	delete(document, presetKey)
+
+	data, err := yaml.Marshal(mergePolicies(parent, document))
+	if err != nil {
+		return "", fmt.Errorf("error loading the %s preset: %w", name, err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		preset  string
+		message string
+		wantErr bool
+	}{
+		{"haproxy", "BUG/MINOR: config: fix the parsing of timeouts", false},
+		{"haproxy", "config: fix the parsing of timeouts", true},
+		{"conventional-commits", "feat(config): parse the timeouts in minutes", false},
+		{"conventional-commits", "fix!: drop the support of legacy timeouts", false},
+		{"conventional-commits", "feat(config)!: parse the timeouts in minutes", false},
+		{"conventional-commits", "feat!(config): parse the timeouts in minutes", true},
+		{"conventional-commits", "feat(config) !: parse the timeouts in minutes", true},
+		{"conventional-commits", "Fix the parsing of timeouts", true},
+		{"conventional-commits", "feature: parse the timeouts in minutes", true},
+		{"kernel", "ACPI: processor: fix the parsing of idle states\n\nSigned-off-by: Jane Doe <jane@example.com>", false},
+		{"kernel", "ACPI: processor: fix the parsing of idle states.\n\nSigned-off-by: Jane Doe <jane@example.com>", true},
+		{"kernel", "fix the parsing of idle states in the processor driver\n\nSigned-off-by: Jane Doe <jane@example.com>", true},
+		{"kernel", "ACPI: processor: fix the parsing of idle states", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.preset+" "+tt.message, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := readVerifiedPolicy(func(string) (string, error) { return "", ErrPolicyNotFound }, "", tt.preset, "")
+			if err != nil {
+				t.Fatalf("readVerifiedPolicy() error = %v", err)
+			}
+
+			c, err := parseCommitPolicy(config)
+			if err != nil {
+				t.Fatalf("parseCommitPolicy() error = %v", err)
+			}
+
+			report := reportT{}
+			c.checkCommits([]commitT{{SHA: "0123456789abcdef", Message: tt.message}}, &report)
+
+			if gotErr := report.Count(severityError) > 0; gotErr != tt.wantErr {
+				t.Errorf("findings = %+v, want errors %t", report.Findings, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	t.Parallel()
+
+	config, err := applyPreset("Preset: kernel\nMaxCommits: 3\n", "haproxy")
+	if err != nil {
+		t.Fatalf("applyPreset() error = %v", err)
+	}
+
+	c, err := parseCommitPolicy(config)
+	if err != nil || c.MaxCommits != 3 || len(c.CustomRules) != 3 || len(c.TagOrder) != 0 {
+		t.Errorf("applyPreset() = %+v, %v, want the kernel preset with MaxCommits 3", c, err)
+	}
+
+	if config, err = applyPreset("MaxCommits: 3\n", ""); err != nil || config != "MaxCommits: 3\n" {
+		t.Errorf("applyPreset() without preset = %q, %v", config, err)
+	}
+
+	if config, err = applyPreset("MaxCommits: 3\n", "haproxy"); err != nil {
+		t.Fatalf("applyPreset() with fallback error = %v", err)
+	} else if c, _ := parseCommitPolicy(config); len(c.TagOrder) == 0 || c.MaxCommits != 3 {
+		t.Errorf("applyPreset() with fallback = %+v", c)
+	}
+
+	if _, err := applyPreset("Preset: gnu\n", ""); !errors.Is(err, ErrPreset) {
+		t.Errorf("applyPreset() unknown preset error = %v, want ErrPreset", err)
+	}
+}
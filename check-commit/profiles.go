@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	profileKey  = "Profile"
+	profilesKey = "Profiles"
+)
+
+var ErrProfile = errors.New("invalid profile")
+
+func (c CommitPolicyConfig) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// applyProfile returns the policy with the keys of one of its Profiles merged over it:
+// the given profile, else the one the Profile key of the policy names, if any. All the
+// profiles are validated, selected or not.
+func applyProfile(config, profile string) (string, error) {
+	document := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return config, nil // reported when loading the policy
+	}
+
+	if _, ok := document[profilesKey]; !ok && profile == "" {
+		return config, nil
+	}
+
+	var commitPolicy CommitPolicyConfig
+	if err := unmarshalPolicy(config, &commitPolicy); err != nil {
+		return "", fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	for _, name := range commitPolicy.profileNames() {
+		p := commitPolicy.Profiles[name]
+		if len(p.Profiles) > 0 || p.Profile != "" || len(p.BranchOverrides) > 0 {
+			return "", fmt.Errorf("profile '%s' cannot select profiles or set %s: %w", name, branchOverridesKey,
+				ErrProfile)
+		}
+	}
+
+	if profile == "" {
+		profile = commitPolicy.Profile
+	}
+
+	if profile == "" {
+		return config, nil
+	}
+
+	if _, ok := commitPolicy.Profiles[profile]; !ok {
+		names := commitPolicy.profileNames()
+		if len(names) == 0 {
+			return "", fmt.Errorf("unknown profile '%s', the policy defines none: %w", profile, ErrProfile)
+		}
+
+		return "", fmt.Errorf("unknown profile '%s'%s, expected one of %s: %w", profile,
+			strings.TrimSuffix(didYouMean(profile, names), "?"), strings.Join(names, ", "), ErrProfile)
+	}
+
+	log.Printf("applying the profile %s", profile)
+
+	profiles, _ := document[profilesKey].(map[interface{}]interface{})
+	if overrides, ok := profiles[profile].(map[interface{}]interface{}); ok {
+		document = mergePolicies(document, overrides)
+	}
+
+	document[profileKey] = profile
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("error loading commit policy: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+const profilesConf = `
+MaxCommits: 20
+Severities:
+  subject-format: error
+  language: error
+Profile: standard
+Profiles:
+  strict:
+    MaxCommits: 5
+  standard: {}
+  relaxed:
+    MaxCommits: 0
+    Severities:
+      subject-format: warning
+`
+
+func TestApplyProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		profile        string
+		wantMaxCommits int
+		wantSeverity   string
+	}{
+		{"", 20, severityError},
+		{"strict", 5, severityError},
+		{"relaxed", 0, severityWarning},
+	}
+
+	for _, tt := range tests {
+		merged, err := applyProfile(profilesConf, tt.profile)
+		if err != nil {
+			t.Fatalf("applyProfile(%s) error = %v", tt.profile, err)
+		}
+
+		c, err := parseCommitPolicy(merged)
+		if err != nil || c.MaxCommits != tt.wantMaxCommits || c.Severities[ruleSubjectFormat] != tt.wantSeverity ||
+			c.Severities[ruleLanguage] != severityError {
+			t.Errorf("applyProfile(%s) = %d, %v, %v, want %d, %s", tt.profile, c.MaxCommits, c.Severities, err,
+				tt.wantMaxCommits, tt.wantSeverity)
+		}
+	}
+
+	if merged, err := applyProfile("MaxCommits: 1\n", ""); err != nil || merged != "MaxCommits: 1\n" {
+		t.Errorf("applyProfile() without profiles = %q, %v", merged, err)
+	}
+}
+
+func TestApplyProfileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  string
+		profile string
+	}{
+		{"unknown profile", profilesConf, "strcit"},
+		{"no profiles", "MaxCommits: 1\n", "strict"},
+		{"unknown default profile", "Profile: strict\nProfiles:\n  relaxed: {}\n", ""},
+		{"nested profiles", "Profiles:\n  strict:\n    Profile: relaxed\n", "strict"},
+		{"branch overrides", "Profiles:\n  strict:\n    BranchOverrides:\n      - Branches: [main]\n", ""},
+	}
+
+	for _, tt := range tests {
+		if _, err := applyProfile(tt.config, tt.profile); !errors.Is(err, ErrProfile) {
+			t.Errorf("%s: applyProfile() error = %v, want %v", tt.name, err, ErrProfile)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// trailingPunctuationT rejects the subjects ending with one of Characters, such as a
+// period.
+type trailingPunctuationT struct {
+	Characters string `yaml:"Characters"`
+	Severity   string `yaml:"Severity"`
+	Shadow     bool   `yaml:"Shadow"`
+}
+
+var ErrTrailingPunctuationConfig = errors.New("invalid trailing punctuation rule")
+
+func (p trailingPunctuationT) validate() error {
+	if strings.IndexFunc(p.Characters, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("trailing punctuation rule: Characters cannot contain spaces: %w",
+			ErrTrailingPunctuationConfig)
+	}
+
+	if !validSeverity(p.Severity) {
+		return fmt.Errorf("trailing punctuation rule: unknown severity '%s': %w", p.Severity,
+			ErrTrailingPunctuationConfig)
+	}
+
+	return nil
+}
+
+func (p trailingPunctuationT) severity() string {
+	if p.Severity == "" {
+		return severityError
+	}
+
+	return p.Severity
+}
+
+var ErrTrailingPunctuation = errors.New("trailing punctuation in subject")
+
+// trim returns the subject without its trailing punctuation.
+func (p trailingPunctuationT) trim(subject string) string {
+	return strings.TrimSpace(strings.TrimRightFunc(strings.TrimSpace(subject), func(r rune) bool {
+		return strings.ContainsRune(p.Characters, r)
+	}))
+}
+
+// Check checks the last character of the subject.
+func (p trailingPunctuationT) Check(subject string) error {
+	runes := []rune(strings.TrimSpace(subject))
+	if len(runes) == 0 || !strings.ContainsRune(p.Characters, runes[len(runes)-1]) {
+		return nil
+	}
+
+	last := string(runes[len(runes)-1])
+
+	return fmt.Errorf("subject ends with '%s', remove it: %w", last, ErrTrailingPunctuation)
+}
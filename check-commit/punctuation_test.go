@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTrailingPunctuation(t *testing.T) {
+	t.Parallel()
+
+	p := trailingPunctuationT{Characters: ".;:"}
+
+	tests := []struct {
+		subject string
+		wantErr bool
+	}{
+		{"BUG/MINOR: config: fix the parsing of the timeouts", false},
+		{"BUG/MINOR: config: fix the parsing of the timeouts.", true},
+		{"MINOR: cli: add a command to dump the sessions;", true},
+		{"MINOR: cli: add the 'show sess' command!", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if err := p.Check(tt.subject); (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrTrailingPunctuation) {
+			t.Errorf("Check(%q) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	if err := (trailingPunctuationT{}).Check("MINOR: cli: add a command."); err != nil {
+		t.Errorf("Check() without Characters error = %v", err)
+	}
+
+	if _, err := parseCommitPolicy("TrailingPunctuation:\n  Characters: '. '\n"); !errors.Is(err,
+		ErrTrailingPunctuationConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrTrailingPunctuationConfig)
+	}
+}
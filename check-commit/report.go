@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ruleTag               = "tag"
+	ruleSubjectFormat     = "subject-format"
+	ruleLanguage          = "language"
+	ruleProtectedBranch   = "protected-branch"
+	ruleMaxCommits        = "max-commits"
+	rulePolicy            = "policy"
+	ruleLabels            = "labels"
+	ruleLinkedIssues      = "linked-issues"
+	ruleApprovals         = "approvals"
+	ruleRevertOfRevert    = "revert-of-revert"
+	ruleReorgPurity       = "reorg-purity"
+	ruleCleanupNeutrality = "cleanup-neutrality"
+	ruleDocumentation     = "documentation"
+	ruleEncoding          = "encoding"
+	ruleCommitSize        = "commit-size"
+	ruleSensitivePaths    = "sensitive-paths"
+	rulePathRules         = "path-rules"
+	ruleVersionFile       = "version-file"
+	ruleSignatures        = "signatures"
+	ruleTagConstraints    = "tag-constraints"
+	ruleComponent         = "component"
+	ruleForbiddenWords    = "forbidden-words"
+	ruleDeprecatedTag     = "deprecated-tag"
+	ruleBotFormat         = "bot-format"
+	ruleImperativeMood    = "imperative-mood"
+	ruleTrailingPunct     = "trailing-punctuation"
+	ruleCapitalization    = "capitalization"
+	ruleCharset           = "charset"
+	ruleWhitespace        = "whitespace"
+	ruleTicketReference   = "ticket-reference"
+	ruleAutosquash        = "autosquash"
+	ruleRevert            = "revert"
+	ruleSpelling          = "spelling"
+	ruleDuplicateSubject  = "duplicate-subject"
+	ruleSubjectURL        = "subject-url"
+	ruleVagueSubject      = "vague-subject"
+	ruleForbiddenChars    = "forbidden-chars"
+	ruleDenylist          = "denylist"
+	ruleTrailingIssue     = "trailing-issue"
+	ruleCustomPrefix      = "custom:"
+)
+
+// validSeverity accepts the severities a rule can be configured with, empty meaning
+// the rule's default.
+func validSeverity(severity string) bool {
+	switch severity {
+	case "", severityError, severityWarning:
+		return true
+	}
+
+	return false
+}
+
+type findingT struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	SHA      string `json:"sha,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	Message  string `json:"message"`
+	Shadow   bool   `json:"shadow,omitempty"`
+}
+
+type reportT struct {
+	Commits    []commitT
+	Findings   []findingT
+	Exceptions []exceptionT
+
+	shadow     bool              // findings are all shadow findings
+	severities map[string]string // configured severities of the rules, overriding those of the findings
+	grace      map[string]string // dates the rules in their grace period are enforced from
+	catalog    catalogT          // messages of the locale of the policy
+	tagFormat  *regexp.Regexp    // TagFormat of the policy, reading the tags of the findings
+	timings    []timingT         // durations of the phases of the run
+}
+
+func (r *reportT) Add(finding findingT) {
+	finding.Shadow = finding.Shadow || r.shadow
+
+	if severity, ok := r.severities[finding.Rule]; ok {
+		if severity == severityOff {
+			return
+		}
+
+		finding.Severity = severity
+	}
+
+	finding.Message = r.catalog.findingMessage(finding)
+
+	if date, ok := r.grace[finding.Rule]; ok && !finding.Shadow {
+		finding.Message += fmt.Sprintf(" (an error from %s)", date)
+	}
+
+	prefix := ""
+	if finding.Severity == severityWarning {
+		prefix = "warning: "
+	}
+
+	if finding.Shadow {
+		prefix = "shadow " + finding.Severity + ": "
+	}
+
+	switch {
+	case finding.SHA != "":
+		log.Printf("%s%s, commit %s '%s'", prefix, finding.Message, shortSHA(finding.SHA), finding.Subject)
+	case finding.Subject != "":
+		log.Printf("%s%s, original subject message '%s'", prefix, finding.Message, finding.Subject)
+	default:
+		log.Printf("%s%s", prefix, finding.Message)
+	}
+
+	r.Findings = append(r.Findings, finding)
+}
+
+func (r *reportT) AddException(exception exceptionT) {
+	log.Printf("exception to %s: %s", exception.Rule, exception.Reason)
+
+	r.Exceptions = append(r.Exceptions, exception)
+}
+
+// AddCommitError records err, if any, as a finding of rule against commit.
+func (r *reportT) AddCommitError(rule, severity string, commit commitT, err error) {
+	r.AddCommitFinding(rule, severity, false, commit, err)
+}
+
+// AddCommitFinding is AddCommitError for rules that can be in shadow mode, whose
+// findings are reported but never fail the check.
+func (r *reportT) AddCommitFinding(rule, severity string, shadow bool, commit commitT, err error) {
+	if err == nil {
+		return
+	}
+
+	r.Add(findingT{
+		Rule:     rule,
+		Severity: severity,
+		SHA:      commit.SHA,
+		Subject:  strings.Trim(commit.Subject(), "'"),
+		Message:  err.Error(),
+		Shadow:   shadow,
+	})
+}
+
+// AddError records err, if any, as a finding not tied to a single commit.
+func (r *reportT) AddError(rule string, err error) {
+	r.AddFinding(rule, severityError, false, err)
+}
+
+// AddFinding records a finding about the request as a whole rather than a commit.
+func (r *reportT) AddFinding(rule, severity string, shadow bool, err error) {
+	if err == nil {
+		return
+	}
+
+	r.Add(findingT{Rule: rule, Severity: severity, Message: err.Error(), Shadow: shadow})
+}
+
+// Count returns the number of findings of severity, shadow findings aside.
+func (r reportT) Count(severity string) int {
+	count := 0
+
+	for _, finding := range r.Findings {
+		if finding.Severity == severity && !finding.Shadow {
+			count++
+		}
+	}
+
+	return count
+}
+
+// CountShadow returns the number of shadow findings of severity.
+func (r reportT) CountShadow(severity string) int {
+	count := 0
+
+	for _, finding := range r.Findings {
+		if finding.Severity == severity && finding.Shadow {
+			count++
+		}
+	}
+
+	return count
+}
+
+// subjectRule maps an error returned by CheckSubject to the rule that raised it.
+func subjectRule(err error) string {
+	switch {
+	case errors.Is(err, ErrSubjectLanguage):
+		return ruleLanguage
+	case errors.Is(err, ErrSubjectMessageFormat):
+		return ruleSubjectFormat
+	default:
+		return ruleTag
+	}
+}
+
+// jsonReportT is the machine readable form of a report, also used to merge the
+// reports of sharded runs.
+type jsonReportT struct {
+	Shards       []string   `json:"shards,omitempty"`
+	Commits      int        `json:"commits"`
+	Errors       int        `json:"errors"`
+	Warnings     int        `json:"warnings"`
+	Findings     []findingT `json:"findings"`
+	Stats        statsT     `json:"stats"`
+	RewordScript string     `json:"reword_script,omitempty"`
+}
+
+func (r reportT) JSON(shard shardT) jsonReportT {
+	report := jsonReportT{
+		Commits:  len(r.Commits),
+		Errors:   r.Count(severityError),
+		Warnings: r.Count(severityWarning),
+		Findings: r.Findings,
+		Stats:    r.stats(),
+	}
+
+	if report.Findings == nil {
+		report.Findings = []findingT{}
+	}
+
+	if shard.Count > 0 {
+		report.Shards = []string{shard.String()}
+	}
+
+	return report
+}
+
+func writeJSONReport(filename string, report jsonReportT) error {
+	const reportFileMode = 0o644
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding report: %w", err)
+	}
+
+	if err := ioutil.WriteFile(filename, append(data, '\n'), reportFileMode); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+
+	return nil
+}
+
+func readJSONReport(filename string) (jsonReportT, error) {
+	var report jsonReportT
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return report, fmt.Errorf("error reading report: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("error decoding report %s: %w", filename, err)
+	}
+
+	return report, nil
+}
+
+var ErrIncompleteShards = errors.New("incomplete set of shards")
+
+// mergeJSONReports concatenates reports in the given order. It returns an error along
+// with the merged report when the shards found do not form a complete i/n set.
+func mergeJSONReports(reports []jsonReportT) (jsonReportT, error) {
+	merged := jsonReportT{Findings: []findingT{}}
+
+	for _, report := range reports {
+		merged.Shards = append(merged.Shards, report.Shards...)
+		merged.Commits += report.Commits
+		merged.Errors += report.Errors
+		merged.Warnings += report.Warnings
+		merged.Findings = append(merged.Findings, report.Findings...)
+		merged.Stats.add(report.Stats)
+	}
+
+	if len(merged.Shards) == 0 {
+		return merged, nil
+	}
+
+	sort.Strings(merged.Shards)
+
+	seen := map[string]bool{}
+	count := 0
+
+	for _, s := range merged.Shards {
+		shard, err := parseShard(s)
+		if err != nil {
+			return merged, err
+		}
+
+		if count != 0 && shard.Count != count {
+			return merged, fmt.Errorf("shards of different splits [%s]: %w", strings.Join(merged.Shards, ", "), ErrIncompleteShards)
+		}
+
+		if seen[s] {
+			return merged, fmt.Errorf("shard %s merged twice: %w", s, ErrIncompleteShards)
+		}
+
+		count = shard.Count
+		seen[s] = true
+	}
+
+	if len(seen) != count {
+		return merged, fmt.Errorf("got %d of %d shards [%s]: %w",
+			len(seen), count, strings.Join(merged.Shards, ", "), ErrIncompleteShards)
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// revertsT verifies the `Revert "..."` and `Reapply "..."` commits with Verify: their
+// body must name the reverted commit, reachable from the revert, and their subject quote
+// its subject. With SkipTags, their subjects are exempt from the tag rules.
+type revertsT struct {
+	Verify   bool   `yaml:"Verify"`
+	SkipTags bool   `yaml:"SkipTags"`
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var ErrRevertsConfig = errors.New("invalid reverts rule")
+
+func (r revertsT) validate() error {
+	if !validSeverity(r.Severity) {
+		return fmt.Errorf("reverts rule: unknown severity '%s': %w", r.Severity, ErrRevertsConfig)
+	}
+
+	return nil
+}
+
+func (r revertsT) severity() string {
+	if r.Severity == "" {
+		return severityError
+	}
+
+	return r.Severity
+}
+
+// unwrapRevert returns the subject quoted by a `Revert "..."` or `Reapply "..."` subject.
+func unwrapRevert(subject string) (string, bool) {
+	for _, prefix := range []string{`Revert "`, `Reapply "`} {
+		if strings.HasPrefix(subject, prefix) && strings.HasSuffix(subject, `"`) && len(subject) > len(prefix) {
+			return subject[len(prefix) : len(subject)-1], true
+		}
+	}
+
+	return subject, false
+}
+
+// originalOfRevertChain returns the subject a chain of nested reverts amounts to, or
+// false when the subject is not such a chain.
+func originalOfRevertChain(subject string) (string, bool) {
+	subject = regexp.MustCompile(`^(?:[A-Z]+(?:/[A-Z]+)?: )*`).ReplaceAllString(subject, "")
+
+	depth := 0
+
+	for {
+		inner, ok := unwrapRevert(subject)
+		if !ok {
+			break
+		}
+
+		subject = inner
+		depth++
+	}
+
+	if depth < 2 { // a single revert is fine
+		return "", false
+	}
+
+	if depth%2 == 1 {
+		subject = `Revert "` + subject + `"`
+	}
+
+	return subject, true
+}
+
+var ErrRevertOfRevert = errors.New("revert of a revert")
+
+// checkRevertOfRevert rejects nested reverts: reverting a revert re-applies the original
+// change, which should be described by the original subject rather than by a growing
+// chain of quotes.
+func checkRevertOfRevert(subject string) error {
+	original, ok := originalOfRevertChain(subject)
+	if !ok {
+		return nil
+	}
+
+	return fmt.Errorf("nested revert detected, please reword the commit with the subject "+
+		"'%s' and mention the reverted revert in the body: %w", original, ErrRevertOfRevert)
+}
+
+// revertedCommitRegexp matches the line git revert adds to the body.
+var revertedCommitRegexp = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})\b`)
+
+var ErrRevert = errors.New("invalid revert commit")
+
+// Check checks a revert commit available in the clone against the commit it reverts.
+func (r revertsT) Check(repoPath string, commit commitT) error {
+	subject := strings.Trim(commit.Subject(), "'")
+	quoted, _ := unwrapRevert(subject)
+
+	m := revertedCommitRegexp.FindStringSubmatch(commit.Body())
+	if m == nil {
+		return fmt.Errorf("revert without a 'This reverts commit <sha>.' line in its body: %w", ErrRevert)
+	}
+
+	reverted := m[1]
+	if _, err := runGit(repoPath, "cat-file", "-e", reverted+"^{commit}"); err != nil {
+		return fmt.Errorf("reverted commit %s is not in the repository: %w", reverted, ErrRevert)
+	}
+
+	if _, err := runGit(repoPath, "merge-base", "--is-ancestor", reverted, commit.SHA); err != nil {
+		return fmt.Errorf("reverted commit %s is not reachable from the revert: %w", reverted, ErrRevert)
+	}
+
+	original, err := runGit(repoPath, "log", "-1", "--format=%s", reverted)
+	if err != nil {
+		return fmt.Errorf("error reading the reverted commit %s: %s: %w", reverted, err, ErrRevert)
+	}
+
+	// a reapply reverts a revert, quoting the subject the revert quotes
+	expected := strings.TrimSpace(original)
+	if strings.HasPrefix(subject, `Reapply "`) {
+		expected, _ = unwrapRevert(expected)
+	}
+
+	if quoted != expected {
+		return fmt.Errorf("revert quotes '%s', but the reverted commit %s is '%s': %w", quoted, shortSHA(reverted),
+			strings.TrimSpace(original), ErrRevert)
+	}
+
+	return nil
+}
+
+// checkReverts verifies the reverts of the commits in the local clone.
+func (c CommitPolicyConfig) checkReverts(repoPath string, commits []commitT, report *reportT) {
+	if !c.Reverts.Verify {
+		return
+	}
+
+	for _, commit := range commits {
+		if _, ok := unwrapRevert(strings.Trim(commit.Subject(), "'")); !ok {
+			continue
+		}
+
+		if _, err := runGit(repoPath, "cat-file", "-e", commit.SHA+"^{commit}"); err != nil {
+			log.Printf("warning: skipping revert check of commit %s: not available in the clone", shortSHA(commit.SHA))
+
+			continue
+		}
+
+		report.AddCommitFinding(ruleRevert, c.Reverts.severity(), c.Reverts.Shadow, commit,
+			c.Reverts.Check(repoPath, commit))
+	}
+}
+
+// checkCommitSubject checks the subject of a commit, reverts skipping the tag rules with
+// Reverts.SkipTags.
+func (c CommitPolicyConfig) checkCommitSubject(subject string) error {
+	if _, ok := unwrapRevert(subject); ok && c.Reverts.SkipTags {
+		return nil
+	}
+
+	return c.CheckSubject([]byte(subject))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckRevertOfRevert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		subject string
+		wantErr bool
+	}{
+		{subject: `Revert "MINOR: config: add keyword"`, wantErr: false},
+		{subject: `Revert "Revert "MINOR: config: add keyword""`, wantErr: true},
+		{subject: `Revert "Reapply "MINOR: config: add keyword""`, wantErr: true},
+		{subject: `REVERT: Revert "Revert "MINOR: config: add keyword""`, wantErr: true},
+		{subject: `MINOR: config: Revert "Revert" keyword handling`, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		if err := checkRevertOfRevert(tt.subject); (err != nil) != tt.wantErr {
+			t.Errorf("checkRevertOfRevert(%s) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	err := checkRevertOfRevert(`Revert "Revert "Revert "MINOR: config: add keyword"""`)
+	if err == nil || !strings.Contains(err.Error(), "'Revert \"MINOR: config: add keyword\"'") {
+		t.Errorf("checkRevertOfRevert() error = %v, want the inner subject", err)
+	}
+}
+
+func TestReverts(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "MINOR: config: add keyword")
+	commit := func(message string) string {
+		if _, err := runGit(repo, "commit", "-q", "--allow-empty", "-m", message); err != nil {
+			t.Fatal(err)
+		}
+
+		sha, err := runGit(repo, "rev-parse", "HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return strings.TrimSpace(sha)
+	}
+
+	original := commit("MINOR: config: add option")
+	revert := commit("Revert \"MINOR: config: add option\"\n\nThis reverts commit " + original + ".")
+
+	for _, args := range [][]string{{"checkout", "-q", "-b", "side"}, {"commit", "-q", "--allow-empty", "-m", "x"}} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	side, _ := runGit(repo, "rev-parse", "HEAD")
+	if _, err := runGit(repo, "checkout", "-q", "-"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		wantErr string
+	}{
+		{"Reapply \"MINOR: config: add option\"\n\nThis reverts commit " + revert + ".", ""},
+		{"Revert \"MINOR: config: add option\"", "revert without a 'This reverts commit <sha>.' line in its body"},
+		{"Revert \"MINOR: config: add options\"\n\nThis reverts commit " + original + ".",
+			"revert quotes 'MINOR: config: add options', but the reverted commit " + shortSHA(original) +
+				" is 'MINOR: config: add option'"},
+		{"Revert \"x\"\n\nThis reverts commit 1234567.", "reverted commit 1234567 is not in the repository"},
+		{"Revert \"x\"\n\nThis reverts commit " + strings.TrimSpace(side) + ".",
+			"reverted commit " + strings.TrimSpace(side) + " is not reachable from the revert"},
+	}
+
+	for _, tt := range tests {
+		sha := commit(tt.message)
+		err := revertsT{Verify: true}.Check(repo, commitT{SHA: sha, Message: tt.message})
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("Check(%q) error = %v", tt.message, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrRevert) || err.Error() != tt.wantErr+": "+ErrRevert.Error()):
+			t.Errorf("Check(%q) error = %v, want %s", tt.message, err, tt.wantErr)
+		}
+	}
+
+	c, err := parseCommitPolicy(defaultConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, skipTags := range []bool{false, true} {
+		c.Reverts.SkipTags = skipTags
+		if err := c.checkCommitSubject(`Revert "config: add option"`); (err == nil) != skipTags {
+			t.Errorf("SkipTags %v: checkCommitSubject() error = %v", skipTags, err)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type reviewItemT struct {
+	Commit     commitT
+	Findings   []findingT
+	Suggestion string
+}
+
+// reviewT is the state of the interactive review of the failing commits of a report.
+type reviewT struct {
+	items  []reviewItemT
+	total  int
+	cursor int
+	detail bool
+	status string
+}
+
+type reviewActionT int
+
+const (
+	reviewNone reviewActionT = iota
+	reviewQuit
+	reviewCopy
+	reviewReword
+)
+
+func (c CommitPolicyConfig) newReview(report reportT) *reviewT {
+	review := &reviewT{total: len(report.Commits)}
+
+	for _, commit := range report.Commits {
+		item := reviewItemT{Commit: commit}
+
+		for _, finding := range report.Findings {
+			if finding.SHA == commit.SHA {
+				item.Findings = append(item.Findings, finding)
+			}
+		}
+
+		if len(item.Findings) == 0 {
+			continue
+		}
+
+		item.Suggestion, _ = c.suggestSubject(commit.Subject())
+		review.items = append(review.items, item)
+	}
+
+	return review
+}
+
+// handleKey updates the state for a key press (or line, when the terminal could not
+// be switched to raw mode) and returns the action the caller has to perform.
+func (r *reviewT) handleKey(key string) reviewActionT {
+	r.status = ""
+
+	switch key {
+	case "q", "\x03":
+		return reviewQuit
+	case "j", "\x1b[B":
+		if r.cursor < len(r.items)-1 {
+			r.cursor++
+		}
+	case "k", "\x1b[A":
+		if r.cursor > 0 {
+			r.cursor--
+		}
+	case "\r", "\n", "l", "\x1b[C":
+		r.detail = len(r.items) > 0
+	case "\x1b", "h", "\x1b[D":
+		r.detail = false
+	case "c":
+		return reviewCopy
+	case "r":
+		return reviewReword
+	}
+
+	return reviewNone
+}
+
+func (r *reviewT) current() (reviewItemT, bool) {
+	if len(r.items) == 0 {
+		return reviewItemT{}, false
+	}
+
+	return r.items[r.cursor], true
+}
+
+// render draws the whole screen; lines end with \r\n as the terminal is in raw mode.
+func (r *reviewT) render(w io.Writer) {
+	lines := []string{}
+
+	if item, ok := r.current(); ok && r.detail {
+		lines = append(lines, fmt.Sprintf("commit %s  %s", shortSHA(item.Commit.SHA), item.Commit.Author), "",
+			"    "+item.Commit.Subject(), "")
+
+		for _, finding := range item.Findings {
+			lines = append(lines, fmt.Sprintf("- [%s] %s: %s", finding.Severity, finding.Rule, finding.Message))
+		}
+
+		if item.Suggestion != "" {
+			lines = append(lines, "", "suggested subject: "+item.Suggestion)
+		} else {
+			lines = append(lines, "", "no suggested subject, the commit needs to be reworded manually")
+		}
+
+		lines = append(lines, "", "esc/h: back  c: copy suggested subject  r: open reword script  q: quit")
+	} else {
+		lines = append(lines, fmt.Sprintf("check-commit review: %d failing commit(s) out of %d", len(r.items), r.total), "")
+
+		for i, item := range r.items {
+			marker := "  "
+			if i == r.cursor {
+				marker = "> "
+			}
+
+			lines = append(lines, fmt.Sprintf("%s%s %s  [%d finding(s)]",
+				marker, shortSHA(item.Commit.SHA), item.Commit.Subject(), len(item.Findings)))
+		}
+
+		lines = append(lines, "", "j/k: move  enter/l: details  c: copy suggested subject  r: open reword script  q: quit")
+	}
+
+	if r.status != "" {
+		lines = append(lines, "", r.status)
+	}
+
+	fmt.Fprint(w, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n")+"\r\n")
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+
+	out, err := cmd.Output()
+
+	return strings.TrimSpace(string(out)), err
+}
+
+// rewordScriptPath returns where the reword script of the review is written.
+func rewordScriptPath(repoPath string) (string, error) {
+	gitDir, err := runGit(repoPath, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(strings.TrimSpace(gitDir), "check-commit-reword.sh"), nil
+}
+
+func (c CommitPolicyConfig) openRewordScript(repoPath string, commits []commitT) (string, error) {
+	filename, err := rewordScriptPath(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	if written, err := c.writeRewordScript(filename, commits); err != nil {
+		return "", err
+	} else if !written {
+		return "no commit has a suggested subject", nil
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, editor, filename)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error running editor: %w", err)
+	}
+
+	return fmt.Sprintf("reword script written, apply it from the repository with: sh %s", filename), nil
+}
+
+// review runs the interactive review of the failing commits until the user quits.
+func (c CommitPolicyConfig) review(repoPath string, report reportT) error {
+	r := c.newReview(report)
+
+	// without a terminal (or stty) keys are read line by line
+	cooked, err := stty("-g")
+	raw := func() {}
+	restore := func() {}
+
+	if err == nil {
+		raw = func() { _, _ = stty("raw", "-echo") }
+		restore = func() { _, _ = stty(cooked) }
+	}
+
+	raw()
+	defer restore()
+
+	const maxKeyLen = 16
+
+	buf := make([]byte, maxKeyLen)
+
+	for {
+		r.render(os.Stdout)
+
+		n, err := os.Stdin.Read(buf)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error reading keys: %w", err)
+		}
+
+		key := strings.TrimRight(string(buf[:n]), "\r\n")
+		if key == "" {
+			key = "\r"
+		}
+
+		switch r.handleKey(key) {
+		case reviewQuit:
+			return nil
+		case reviewCopy:
+			r.status = r.copySuggestion(os.Stdout)
+		case reviewReword:
+			restore()
+
+			if r.status, err = c.openRewordScript(repoPath, report.Commits); err != nil {
+				r.status = err.Error()
+			}
+
+			raw()
+		case reviewNone:
+		}
+	}
+}
+
+// copySuggestion copies the suggested subject through the OSC 52 terminal sequence,
+// which works over SSH and in most terminal emulators.
+func (r *reviewT) copySuggestion(w io.Writer) string {
+	item, ok := r.current()
+	if !ok || item.Suggestion == "" {
+		return "no suggested subject to copy"
+	}
+
+	fmt.Fprintf(w, "\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(item.Suggestion)))
+
+	return "copied: " + item.Suggestion
+}
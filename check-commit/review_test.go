@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReview(t *testing.T) {
+	t.Parallel()
+
+	c, err := LoadCommitPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := reportT{Commits: []commitT{
+		{SHA: "1111111111", Message: "bug/minor: review: first failing commit"},
+		{SHA: "2222222222", Message: "MINOR: review: compliant commit of the series"},
+		{SHA: "3333333333", Message: "wip"},
+	}}
+	c.checkCommits(report.Commits, &report)
+
+	r := c.newReview(report)
+	if len(r.items) != 2 {
+		t.Fatalf("newReview() = %d items, want 2", len(r.items))
+	}
+
+	var out bytes.Buffer
+
+	r.render(&out)
+
+	if !strings.Contains(out.String(), "2 failing commit(s) out of 3") || !strings.Contains(out.String(), "> 11111111 ") {
+		t.Errorf("render() = %q", out.String())
+	}
+
+	for _, key := range []string{"j", "j", "k", "\r"} {
+		if action := r.handleKey(key); action != reviewNone {
+			t.Fatalf("handleKey(%q) = %v", key, action)
+		}
+	}
+
+	out.Reset()
+	r.render(&out)
+
+	if !strings.Contains(out.String(), "suggested subject: BUG/MINOR: review: first failing commit") {
+		t.Errorf("render() details = %q", out.String())
+	}
+
+	if status := r.copySuggestion(&out); !strings.HasPrefix(status, "copied") {
+		t.Errorf("copySuggestion() = %s", status)
+	}
+
+	r.handleKey("\x1b")
+	r.handleKey("j")
+
+	if item, _ := r.current(); r.detail || item.Suggestion != "" {
+		t.Errorf("after esc and j: detail %v, suggestion '%s'", r.detail, item.Suggestion)
+	}
+
+	if action := r.handleKey("q"); action != reviewQuit {
+		t.Errorf("handleKey(q) = %v, want quit", action)
+	}
+}
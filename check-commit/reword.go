@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+type rewordT struct {
+	SHA        string
+	Subject    string
+	Suggestion string
+}
+
+func (c CommitPolicyConfig) rewords(commits []commitT) []rewordT {
+	rewords := []rewordT{}
+
+	for _, commit := range commits {
+		if suggestion, ok := c.suggestSubject(commit.Subject()); ok {
+			rewords = append(rewords, rewordT{SHA: commit.SHA, Subject: commit.Subject(), Suggestion: suggestion})
+		}
+	}
+
+	return rewords
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// rewordScript returns a shell script which rebases the current branch from the oldest
+// commit to reword and replaces each subject by its suggestion, keeping the bodies.
+func rewordScript(rewords []rewordT) string {
+	var b strings.Builder
+
+	shas := make([]string, 0, len(rewords))
+	for _, r := range rewords {
+		shas = append(shas, r.SHA)
+	}
+
+	b.WriteString(`#!/bin/sh
+# Rewords the commits reported by check-commit with the suggested subjects, keeping
+# their bodies. Review the subjects below, then run this script from the repository.
+set -e
+
+if [ "$1" != --step ]; then
+	script=$(cd "$(dirname "$0")" && pwd)/$(basename "$0")
+`)
+	fmt.Fprintf(&b, "\toldest=$(git merge-base --octopus %s)\n", strings.Join(shas, " "))
+	b.WriteString(`	if git rev-parse -q --verify "$oldest^" >/dev/null; then
+		set -- "$oldest^"
+	else
+		set -- --root
+	fi
+	exec git rebase -q --exec "sh '$script' --step" "$@"
+fi
+
+case "$(git log -1 --format=%s)" in
+`)
+
+	for _, r := range rewords {
+		fmt.Fprintf(&b, "%s) subject=%s ;; # %s\n", shellQuote(r.Subject), shellQuote(r.Suggestion), shortSHA(r.SHA))
+	}
+
+	b.WriteString(`*) exit 0 ;;
+esac
+
+{ printf '%s\n\n' "$subject"; git log -1 --format=%b; } | git commit -q --amend --allow-empty --cleanup=strip -F -
+`)
+
+	return b.String()
+}
+
+// writeRewordScript writes the reword script of the commits having a suggested subject,
+// if any, and returns whether it was written.
+func (c CommitPolicyConfig) writeRewordScript(filename string, commits []commitT) (bool, error) {
+	rewords := c.rewords(commits)
+	if len(rewords) == 0 {
+		return false, nil
+	}
+
+	const scriptFileMode = 0o755
+	if err := ioutil.WriteFile(filename, []byte(rewordScript(rewords)), scriptFileMode); err != nil {
+		return false, fmt.Errorf("error writing reword script: %w", err)
+	}
+
+	return true, nil
+}
+
+// runURL returns the page of the CI run, where its artifacts can be downloaded.
+func runURL(repoEnv string) string {
+	switch repoEnv {
+	case GITHUB:
+		server, repo, id := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+		if server != "" && repo != "" && id != "" {
+			return server + "/" + repo + "/actions/runs/" + id
+		}
+	case GITLAB:
+		return os.Getenv("CI_JOB_URL")
+	}
+
+	return ""
+}
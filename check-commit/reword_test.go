@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewordScript(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t,
+		"MINOR: reword: first commit of the test repository",
+		"bug/minor: reword: it's the second commit\n\nwith a body",
+		"BUG/MINOR: reword: third commit of the repository",
+	)
+
+	commits, err := gitLogCommits(repo, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadCommitPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rewords := c.rewords(commits)
+	if len(rewords) != 1 {
+		t.Fatalf("rewords() = %v, want one reword", rewords)
+	}
+
+	script := filepath.Join(t.TempDir(), "reword.sh")
+	if err := ioutil.WriteFile(script, []byte(rewordScript(rewords)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("sh", script)
+	cmd.Dir = repo
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("reword script failed: %v\n%s", err, out)
+	}
+
+	commits, err = gitLogCommits(repo, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"BUG/MINOR: reword: third commit of the repository",
+		"BUG/MINOR: reword: it's the second commit\n\nwith a body",
+		"MINOR: reword: first commit of the test repository",
+	}
+
+	for i, commit := range commits {
+		if commit.Message != want[i] {
+			t.Errorf("commit %d message = '%s', want '%s'", i, commit.Message, want[i])
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	targetSubject = "subject"
+	targetBody    = "body"
+	targetTrailer = "trailer"
+	targetAuthor  = "author"
+
+	matchMust    = "must"
+	matchMustNot = "must-not"
+
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+type customRuleT struct {
+	Name     string   `yaml:"Name"`
+	Tags     []string `yaml:"Tags"`
+	Target   string   `yaml:"Target"`
+	Regex    string   `yaml:"Regex"`
+	Match    string   `yaml:"Match"`
+	Severity string   `yaml:"Severity"`
+	Message  string   `yaml:"Message"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrCustomRuleConfig = errors.New("invalid custom rule")
+
+func (r customRuleT) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("custom rule with regex '%s' has no name: %w", r.Regex, ErrCustomRuleConfig)
+	}
+
+	if _, err := regexp.Compile(r.Regex); err != nil {
+		return fmt.Errorf("custom rule '%s': %s: %w", r.Name, err, ErrCustomRuleConfig)
+	}
+
+	switch r.Target {
+	case "", targetSubject, targetBody, targetTrailer, targetAuthor:
+	default:
+		return fmt.Errorf("custom rule '%s': unknown target '%s': %w", r.Name, r.Target, ErrCustomRuleConfig)
+	}
+
+	switch r.Match {
+	case "", matchMust, matchMustNot:
+	default:
+		return fmt.Errorf("custom rule '%s': unknown match '%s': %w", r.Name, r.Match, ErrCustomRuleConfig)
+	}
+
+	if !validSeverity(r.Severity) {
+		return fmt.Errorf("custom rule '%s': unknown severity '%s': %w", r.Name, r.Severity, ErrCustomRuleConfig)
+	}
+
+	return nil
+}
+
+// appliesTo restricts the rule to commits carrying one of its Tags (tag or severity),
+// rules without Tags apply to every commit.
+func (r customRuleT) appliesTo(commit commitT) bool {
+	return len(r.Tags) == 0 || hasAnyValue(commit.Tags(), r.Tags)
+}
+
+func (r customRuleT) IsWarning() bool {
+	return r.Severity == severityWarning
+}
+
+// targetTexts returns the pieces of the commit the rule is evaluated against; trailers
+// are matched one by one, everything else as a single text.
+func (r customRuleT) targetTexts(commit commitT) []string {
+	switch r.Target {
+	case targetBody:
+		return []string{commit.Body()}
+	case targetTrailer:
+		return commit.Trailers()
+	case targetAuthor:
+		return []string{commit.Author}
+	default:
+		return []string{commit.Subject()}
+	}
+}
+
+var ErrCustomRule = errors.New("custom rule violated")
+
+func (r customRuleT) Check(commit commitT) error {
+	re := regexp.MustCompile(r.Regex) // validated when loading the configuration
+
+	matched := false
+
+	for _, text := range r.targetTexts(commit) {
+		match := re.FindStringIndex(text)
+		tracef("rule '%s': regexp %s on %s '%s': match %v", r.Name, r.Regex, r.targetName(), text, match)
+
+		if match != nil {
+			matched = true
+
+			break
+		}
+	}
+
+	if matched == (r.Match == matchMustNot) {
+		message := r.Message
+		if message == "" {
+			message = fmt.Sprintf("%s %s match '%s'", r.targetName(), strings.ReplaceAll(r.matchName(), "-", " "), r.Regex)
+		}
+
+		return fmt.Errorf("rule '%s': %s: %w", r.Name, message, ErrCustomRule)
+	}
+
+	return nil
+}
+
+func (r customRuleT) targetName() string {
+	if r.Target == "" {
+		return targetSubject
+	}
+
+	return r.Target
+}
+
+func (r customRuleT) matchName() string {
+	if r.Match == "" {
+		return matchMust
+	}
+
+	return r.Match
+}
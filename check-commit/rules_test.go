@@ -0,0 +1,127 @@
+package main
+
+import "testing"
+
+func TestCustomRuleCheck(t *testing.T) {
+	t.Parallel()
+
+	commit := commitT{
+		SHA:    "0123456789abcdef",
+		Author: "Jane Doe <jane@example.com>",
+		Message: "MINOR: config: add [h2] tuning knobs\n\n" +
+			"Some explanation of the change.\n\n" +
+			"Signed-off-by: Jane Doe <jane@example.com>",
+	}
+
+	tests := []struct {
+		name    string
+		rule    customRuleT
+		wantErr bool
+	}{
+		{
+			name:    "subject must match",
+			rule:    customRuleT{Name: "component", Regex: `\[[a-z0-9]+\]`},
+			wantErr: false,
+		},
+		{
+			name:    "subject must not match",
+			rule:    customRuleT{Name: "no-wip", Regex: `(?i)\bwip\b`, Match: matchMustNot},
+			wantErr: false,
+		},
+		{
+			name:    "body must match",
+			rule:    customRuleT{Name: "benchmark", Target: targetBody, Regex: `\d+%`},
+			wantErr: true,
+		},
+		{
+			name:    "trailer must match",
+			rule:    customRuleT{Name: "sign-off", Target: targetTrailer, Regex: `^Signed-off-by: `},
+			wantErr: false,
+		},
+		{
+			name:    "trailer missing",
+			rule:    customRuleT{Name: "ack", Target: targetTrailer, Regex: `^Acked-by: `},
+			wantErr: true,
+		},
+		{
+			name:    "author must not match",
+			rule:    customRuleT{Name: "corp-mail", Target: targetAuthor, Regex: `@example\.com>$`, Match: matchMustNot},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.rule.validate(); err != nil {
+				t.Fatalf("validate() error = %v", err)
+			}
+			if err := tt.rule.Check(commit); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomRuleValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rule customRuleT
+	}{
+		{name: "missing name", rule: customRuleT{Regex: "x"}},
+		{name: "invalid regex", rule: customRuleT{Name: "r", Regex: "("}},
+		{name: "unknown target", rule: customRuleT{Name: "r", Regex: "x", Target: "committer"}},
+		{name: "unknown match", rule: customRuleT{Name: "r", Regex: "x", Match: "should"}},
+		{name: "unknown severity", rule: customRuleT{Name: "r", Regex: "x", Severity: "fatal"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.rule.validate(); err == nil {
+				t.Errorf("validate() expected an error for %+v", tt.rule)
+			}
+		})
+	}
+}
+
+func TestCustomRuleTags(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{CustomRules: []customRuleT{
+		{Name: "optim-benchmark", Tags: []string{"OPTIM"}, Target: targetBody, Regex: `\d+(\.\d+)?\s*(%|req/s)`},
+		{Name: "build-platform", Tags: []string{"BUILD"}, Target: targetBody, Regex: `(?i)\b(linux|freebsd|macos|windows)\b`},
+	}}
+
+	tests := []struct {
+		name    string
+		message string
+		wantErr bool
+	}{
+		{name: "untagged rules skipped", message: "MINOR: config: add keyword", wantErr: false},
+		{name: "optim without numbers", message: "OPTIM: pool: cache objects\n\nThis is faster.", wantErr: true},
+		{name: "optim with numbers", message: "OPTIM: pool: cache objects\n\nGoes from 100000 to 120000 req/s.", wantErr: false},
+		{name: "build with severity", message: "BUILD/MINOR: makefile: fix flags\n\nBreaks on FreeBSD.", wantErr: false},
+		{name: "build without platform", message: "BUILD/MINOR: makefile: fix flags", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			report := reportT{}
+			for _, rule := range c.CustomRules {
+				if rule.appliesTo(commitT{Message: tt.message}) {
+					report.AddCommitError(rule.Name, severityError, commitT{Message: tt.message}, rule.Check(commitT{Message: tt.message}))
+				}
+			}
+			if (len(report.Findings) > 0) != tt.wantErr {
+				t.Errorf("custom rules findings = %+v, wantErr %v", report.Findings, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	configInit = "init"
+
+	// inferredCommits is the number of commits of the history tags are inferred from.
+	inferredCommits = 1000
+	// minInferredCount is how many commits must use a tag for it to be inferred.
+	minInferredCount = 3
+	maxInferredTags  = 20
+)
+
+var ErrConfigInit = errors.New("unable to create the policy")
+
+// scaffoldT describes how a policy built on a preset accepts the tags of a history:
+// Prefix captures the tag of a subject, Known returns the tags the preset already
+// accepts, and Section the YAML accepting the others.
+type scaffoldT struct {
+	Prefix  *regexp.Regexp
+	Known   func() []string
+	Section func(tags []string) string
+}
+
+// haproxyPatchValues returns the tags of the HAProxy Standard Patch of the preset.
+func haproxyPatchValues() []string {
+	c, _ := parseCommitPolicy(defaultConf)
+
+	return c.PatchTypes["HAProxy Standard Patch"].Values
+}
+
+var scaffolds = map[string]scaffoldT{
+	"haproxy": {
+		Prefix: regexp.MustCompile(`^([A-Z]+)(?:/[A-Z]+)?: `),
+		Known: func() []string {
+			c, _ := parseCommitPolicy(defaultConf)
+
+			return append(haproxyPatchValues(), c.PatchTypes["HAProxy Standard Feature Commit"].Values...)
+		},
+		Section: func(tags []string) string {
+			return "PatchTypes:\n  HAProxy Standard Patch:\n    Values:\n      - " +
+				strings.Join(append(haproxyPatchValues(), tags...), "\n      - ") + "\n"
+		},
+	},
+	"conventional-commits": {
+		Prefix: regexp.MustCompile(`^([a-z]+)(?:\([^)]*\))?!?: `),
+		Known:  func() []string { return conventionalTypes },
+		Section: func(tags []string) string {
+			return conventionalTypeRule(append(append([]string{}, conventionalTypes...), tags...))
+		},
+	},
+	"kernel": {
+		Prefix: regexp.MustCompile(`^([A-Za-z0-9_.,/-]+): `),
+		Known:  func() []string { return nil },
+		Section: func(tags []string) string {
+			return "Components:\n  Severity: warning\n  Values:\n    - " + strings.Join(tags, "\n    - ") + "\n"
+		},
+	},
+}
+
+type tagCountT struct {
+	Tag   string
+	Count int
+}
+
+// inferTags returns the most common tags of the subjects the preset does not accept,
+// most common first.
+func (s scaffoldT) inferTags(subjects []string) []tagCountT {
+	known := s.Known()
+	counts := map[string]int{}
+
+	for _, subject := range subjects {
+		if m := s.Prefix.FindStringSubmatch(subject); m != nil && !containsString(known, m[1]) {
+			counts[m[1]]++
+		}
+	}
+
+	tags := []tagCountT{}
+
+	for tag, count := range counts {
+		if count >= minInferredCount {
+			tags = append(tags, tagCountT{Tag: tag, Count: count})
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	if len(tags) > maxInferredTags {
+		tags = tags[:maxInferredTags]
+	}
+
+	return tags
+}
+
+// scaffoldPolicy returns a commented policy built on the preset, accepting the given
+// tags besides those of the preset.
+func scaffoldPolicy(preset string, tags []tagCountT) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `# check-commit policy, built on the %s preset: the keys below are merged over
+# those of the preset. Uncomment and adapt the ones the project needs.
+Version: %d
+Preset: %s
+
+# Bounds of the subjects, once their tags are removed.
+# SubjectMinLen: %d
+# SubjectMaxLen: %d
+# MinWords: %d
+# MaxWords: %d
+
+# Maximum number of commits of a request, 0 for no limit.
+# MaxCommits: 20
+
+# Severity of the rules: error, warning or "off".
+# Severities:
+#   subject-format: warning
+
+# Authors whose commits are not checked, by login or email.
+# ExemptAuthors:
+#   - dependabot[bot]
+`, preset, policyVersion, preset, MINSUBJECTLEN, MAXSUBJECTLEN, MINSUBJECTPARTS, MAXSUBJECTPARTS)
+
+	if len(tags) == 0 {
+		return b.String()
+	}
+
+	names := make([]string, len(tags))
+	counts := make([]string, len(tags))
+
+	for i, tag := range tags {
+		names[i] = tag.Tag
+		counts[i] = fmt.Sprintf("%s (%d)", tag.Tag, tag.Count)
+	}
+
+	fmt.Fprintf(&b, "\n# Tags of the history the preset does not accept: %s.\n", strings.Join(counts, ", "))
+
+	b.WriteString(scaffolds[preset].Section(names))
+
+	return b.String()
+}
+
+// historySubjects returns the subjects of the last commits of the revision range.
+func historySubjects(repoPath, revRange string) ([]string, error) {
+	if revRange == "" {
+		revRange = "HEAD"
+	}
+
+	out, err := runGit(repoPath, "log", "--format=%s", fmt.Sprintf("--max-count=%d", inferredCommits), revRange)
+	if err != nil {
+		return nil, err
+	}
+
+	if out = strings.TrimSpace(out); out == "" {
+		return nil, nil
+	}
+
+	return strings.Split(out, "\n"), nil
+}
+
+// policyPath returns the path of the policy the options designate, the policy file of
+// the repository root by default.
+func (opts optionsT) policyPath() string {
+	switch {
+	case opts.config == "":
+		return filepath.Join(opts.repoPath, policyFile)
+	case filepath.IsAbs(opts.config):
+		return opts.config
+	}
+
+	return filepath.Join(opts.repoPath, opts.config)
+}
+
+// initCommand writes a commented policy built on the preset of the options, accepting
+// the most common tags of the history with --from-history.
+func initCommand(opts optionsT) error {
+	preset := opts.preset
+	if preset == "" {
+		preset = defaultPreset
+	}
+
+	if _, err := presetConfig(preset); err != nil {
+		return err
+	}
+
+	filename := opts.policyPath()
+	if _, err := os.Stat(filename); err == nil && !opts.force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it: %w", filename, ErrConfigInit)
+	}
+
+	tags := []tagCountT{}
+
+	if opts.fromHistory {
+		subjects, err := historySubjects(opts.repoPath, opts.revRange)
+		if err != nil {
+			return fmt.Errorf("error reading the history: %s: %w", err, ErrConfigInit)
+		}
+
+		tags = scaffolds[preset].inferTags(subjects)
+		log.Printf("%d tag(s) of the last %d commit(s) added to the %s preset", len(tags), len(subjects), preset)
+	}
+
+	config := scaffoldPolicy(preset, tags)
+
+	merged, err := applyPreset(config, "")
+	if err == nil {
+		_, err = parseCommitPolicy(merged)
+	}
+
+	if err != nil {
+		return fmt.Errorf("generated policy is invalid: %s: %w", err, ErrConfigInit)
+	}
+
+	const policyFileMode = 0o644
+
+	if err := ioutil.WriteFile(filename, []byte(config), policyFileMode); err != nil {
+		return fmt.Errorf("error writing %s: %w", filename, err)
+	}
+
+	log.Printf("policy written to %s", filename)
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferTags(t *testing.T) {
+	t.Parallel()
+
+	subjects := []string{
+		"BUG/MINOR: mux-h2: fix the window update", "BUG/MEDIUM: config: fix the parsing",
+		"CONTRIB: add a tool", "CONTRIB: fix the tool", "CONTRIB/MINOR: update the tool",
+		"SCRIPTS: add a script", "SCRIPTS: fix the script", "SCRIPTS: update the script", "SCRIPTS: drop it",
+		"WIP: rare", "WIP: rare", "feat(parser): accept tabs", "merge branch 'main'",
+	}
+
+	tests := []struct {
+		preset string
+		want   []tagCountT
+	}{
+		{"haproxy", []tagCountT{{"SCRIPTS", 4}, {"CONTRIB", 3}}},
+		{"conventional-commits", []tagCountT{}},
+		{"kernel", []tagCountT{{"SCRIPTS", 4}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.preset, func(t *testing.T) {
+			t.Parallel()
+
+			if got := scaffolds[tt.preset].inferTags(subjects); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inferTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaffoldPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		preset  string
+		tags    []tagCountT
+		message string
+	}{
+		{"haproxy", nil, "BUG/MINOR: config: fix the parsing of timeouts"},
+		{"haproxy", []tagCountT{{"CONTRIB", 3}}, "CONTRIB/MINOR: halog: fix the parsing of dates"},
+		{"conventional-commits", []tagCountT{{"deps", 5}}, "deps(go): update the yaml module"},
+		{"kernel", []tagCountT{{"net", 5}}, "net: ipv4: fix the route cache\n\nSigned-off-by: Jane Doe <jane@example.com>"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.preset+" "+tt.message, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := applyPreset(scaffoldPolicy(tt.preset, tt.tags), "")
+			if err != nil {
+				t.Fatalf("applyPreset() error = %v", err)
+			}
+
+			c, err := parseCommitPolicy(config)
+			if err != nil {
+				t.Fatalf("parseCommitPolicy() error = %v", err)
+			}
+
+			report := reportT{}
+			c.checkCommits([]commitT{{SHA: "0123456789abcdef", Message: tt.message}}, &report)
+
+			if len(report.Findings) > 0 {
+				t.Errorf("findings = %+v, want none", report.Findings)
+			}
+		})
+	}
+}
+
+func TestInitCommand(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "CONTRIB: halog: first commit", "CONTRIB: halog: second commit",
+		"CONTRIB: halog: third commit", "BUG/MINOR: config: fourth commit")
+	opts := optionsT{repoPath: repo, fromHistory: true}
+
+	if err := initCommand(opts); err != nil {
+		t.Fatalf("initCommand() error = %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(repo, policyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "# Tags of the history the preset does not accept: CONTRIB (3).\n"; !strings.Contains(string(data), want) {
+		t.Errorf("initCommand() policy lacks %q:\n%s", want, data)
+	}
+
+	if err := initCommand(opts); !errors.Is(err, ErrConfigInit) {
+		t.Errorf("initCommand() over an existing policy error = %v, want %v", err, ErrConfigInit)
+	}
+
+	opts.force, opts.preset = true, "gnu"
+	if err := initCommand(opts); !errors.Is(err, ErrPreset) {
+		t.Errorf("initCommand() error = %v, want %v", err, ErrPreset)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var ErrPolicySchema = errors.New("invalid policy")
+
+var unknownFieldRegexp = regexp.MustCompile(`^line (\d+): field (.+) not found in type (\S+)$`)
+
+// structKeys records the YAML keys of the struct types reachable from t, by type name
+// as yaml.v2 reports them.
+func structKeys(t reflect.Type, keys map[string][]string) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		structKeys(t.Elem(), keys)
+	case reflect.Struct:
+		if _, ok := keys[t.String()]; ok {
+			return
+		}
+
+		keys[t.String()] = []string{}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+
+			keys[t.String()] = append(keys[t.String()], name)
+
+			structKeys(field.Type, keys)
+		}
+	}
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// closestName returns the candidate the name is most likely a typo of, ignoring case,
+// if any is close enough.
+func closestName(name string, candidates []string) (string, bool) {
+	best, bestDistance := "", len(name)/3+1
+
+	for _, candidate := range candidates {
+		if distance := levenshtein(strings.ToLower(name), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best, best != ""
+}
+
+func didYouMean(name string, candidates []string) string {
+	if suggestion, ok := closestName(name, candidates); ok {
+		return fmt.Sprintf(", did you mean '%s'?", suggestion)
+	}
+
+	return ""
+}
+
+func isDeprecatedKey(name string) bool {
+	for path := range deprecatedKeys {
+		if path == name || strings.HasSuffix(path, "."+name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unmarshalPolicy decodes the policy, rejecting the keys it does not know instead of
+// ignoring them, except the deprecated ones.
+func unmarshalPolicy(config string, commitPolicy *CommitPolicyConfig) error {
+	err := yaml.UnmarshalStrict([]byte(config), commitPolicy)
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	keys := map[string][]string{}
+	structKeys(reflect.TypeOf(*commitPolicy), keys)
+
+	problems := []string{}
+
+	for _, message := range typeErr.Errors {
+		if m := unknownFieldRegexp.FindStringSubmatch(message); m != nil {
+			if isDeprecatedKey(m[2]) {
+				continue
+			}
+
+			message = fmt.Sprintf("line %s: unknown key '%s'%s", m[1], m[2], didYouMean(m[2], keys[m[3]]))
+		}
+
+		problems = append(problems, message)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", strings.Join(problems, "; "), ErrPolicySchema)
+}
+
+// configLine returns the "line N: " prefix of the first line of the configuration
+// matching the regexp from the line matching the start one, or nothing when not found.
+func configLine(config string, start, re *regexp.Regexp) string {
+	started := start == nil
+
+	for i, line := range strings.Split(config, "\n") {
+		if !started {
+			started = start.MatchString(line)
+		}
+
+		if started && re.MatchString(line) {
+			return fmt.Sprintf("line %d: ", i+1)
+		}
+	}
+
+	return ""
+}
+
+// validateReferences checks that the patch types of TagOrder and the scopes of the
+// patch types are defined, as a typo silently disables the corresponding checks, and
+// that the patch types requiring a severity have a scope.
+func (c CommitPolicyConfig) validateReferences(config string) error {
+	problems := []string{}
+	patchTypes := sortedPatchTypes(c.PatchTypes)
+	scopes := sortedPatchScopes(c.PatchScopes)
+	tagOrderStart := regexp.MustCompile(`^["']?TagOrder["']?:`)
+
+	for i, alternative := range c.TagOrder {
+		for _, name := range alternative.PatchTypes {
+			if _, ok := c.PatchTypes[name]; ok {
+				continue
+			}
+
+			line := configLine(config, tagOrderStart, regexp.MustCompile(regexp.QuoteMeta(name)))
+			problems = append(problems, fmt.Sprintf("%sTagOrder alternative %d refers to undefined patch type '%s'%s",
+				line, i+1, name, didYouMean(name, patchTypes)))
+		}
+	}
+
+	for _, name := range patchTypes {
+		scope := c.PatchTypes[name].Scope
+		if scope == "" && c.PatchTypes[name].ScopeRequired {
+			problems = append(problems, fmt.Sprintf("patch type '%s' requires a severity but has no Scope", name))
+		}
+
+		if _, ok := c.PatchScopes[scope]; scope == "" || ok {
+			continue
+		}
+
+		line := configLine(config, nil, regexp.MustCompile(`["']?Scope["']?:\s*["']?`+regexp.QuoteMeta(scope)))
+		problems = append(problems, fmt.Sprintf("%spatch type '%s' refers to undefined scope '%s'%s",
+			line, name, scope, didYouMean(scope, scopes)))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", strings.Join(problems, "; "), ErrPolicySchema)
+}
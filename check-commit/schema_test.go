@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseCommitPolicySchema(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"default", defaultConf, ""},
+		{"unknown key", "MaxCommit: 3\n", "line 1: unknown key 'MaxCommit', did you mean 'MaxCommits'?"},
+		{"unknown nested key", `
+PatchTypes:
+  Tags:
+    Valuez: [BUG]
+`, "line 4: unknown key 'Valuez', did you mean 'Values'?"},
+		{"unknown key without suggestion", "Unrelated: true\n", "line 1: unknown key 'Unrelated': "},
+		{"undefined patch type", `
+PatchTypes:
+  HAProxy Standard Patch:
+    Values: [BUG]
+TagOrder:
+  - PatchTypes:
+    - HAProxy Standard Pach
+`, "line 7: TagOrder alternative 1 refers to undefined patch type 'HAProxy Standard Pach', " +
+			"did you mean 'HAProxy Standard Patch'?"},
+		{"undefined scope", `
+PatchScopes:
+  Severities: [MINOR]
+PatchTypes:
+  Tags:
+    Values: [BUG]
+    Scope: Severites
+TagOrder:
+  - PatchTypes: [Tags]
+`, "line 7: patch type 'Tags' refers to undefined scope 'Severites', did you mean 'Severities'?"},
+		{"several problems", "MaxCommit: 3\nHelpTxt: x\n", "line 1: unknown key 'MaxCommit', did you mean 'MaxCommits'?; " +
+			"line 2: unknown key 'HelpTxt', did you mean 'HelpText'?"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseCommitPolicy(tt.config)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("parseCommitPolicy() error = %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrPolicySchema) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseCommitPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClosestName(t *testing.T) {
+	t.Parallel()
+
+	candidates := []string{"PatchTypes", "PatchScopes", "TagOrder"}
+
+	for name, want := range map[string]string{"patchtypes": "PatchTypes", "PatchScope": "PatchScopes", "Order": "", "": ""} {
+		if got, _ := closestName(name, candidates); got != want {
+			t.Errorf("closestName(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
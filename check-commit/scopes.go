@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// scopeSourceT generates values of a scope from the repository tree, from one of: the
+// names of the subdirectories of Directory, e.g. the components of src/ in a monorepo,
+// or the subsystems owned in the CodeOwners file or listed in the Maintainers file,
+// but the values matching one of Exclude.
+type scopeSourceT struct {
+	Directory   string   `yaml:"Directory"`
+	CodeOwners  string   `yaml:"CodeOwners"`
+	Maintainers string   `yaml:"Maintainers"`
+	Exclude     []string `yaml:"Exclude"`
+}
+
+var ErrScopeSource = errors.New("invalid scope source")
+
+// location returns the path the source reads, whatever its kind, and how many kinds
+// are set.
+func (s scopeSourceT) location() (string, int) {
+	location, count := "", 0
+
+	for _, name := range []string{s.Directory, s.CodeOwners, s.Maintainers} {
+		if name != "" {
+			location = name
+			count++
+		}
+	}
+
+	return location, count
+}
+
+// validateScopeSources checks that the ScopeSources are those of scopes of the policy
+// and each read a single file or directory of the repository.
+func (c CommitPolicyConfig) validateScopeSources() error {
+	for name, source := range c.ScopeSources {
+		if !c.knownScope(name) {
+			return fmt.Errorf("ScopeSources: unknown scope '%s': %w", name, ErrScopeSource)
+		}
+
+		location, count := source.location()
+		if count != 1 {
+			return fmt.Errorf("ScopeSources: scope '%s' requires one of Directory, CodeOwners or Maintainers: %w",
+				name, ErrScopeSource)
+		}
+
+		if !inRepository(location) {
+			return fmt.Errorf("ScopeSources: scope '%s': '%s' is not in the repository: %w", name, location,
+				ErrScopeSource)
+		}
+
+		for _, pattern := range source.Exclude {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("ScopeSources: scope '%s': pattern '%s': %s: %w", name, pattern, err, ErrScopeSource)
+			}
+		}
+	}
+
+	return nil
+}
+
+// knownScope tells whether the scope is listed in PatchScopes or used by a patch type.
+func (c CommitPolicyConfig) knownScope(name string) bool {
+	if _, ok := c.PatchScopes[name]; ok {
+		return true
+	}
+
+	for _, patchType := range c.PatchTypes {
+		if patchType.Scope == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scopeValues returns the values of the source in the tree of rev.
+func (s scopeSourceT) scopeValues(repoPath, rev string) ([]string, error) {
+	location, _ := s.location()
+
+	var names []string
+
+	if s.Directory != "" {
+		out, err := runGit(repoPath, "ls-tree", "-d", "--name-only", rev+":"+path.Clean(location))
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s: %w", location, err)
+		}
+
+		names = strings.Split(strings.TrimSpace(out), "\n")
+	} else {
+		content, err := runGit(repoPath, "show", rev+":"+path.Clean(location))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", location, err)
+		}
+
+		names = ownedNames(ownedPatterns(content, s.CodeOwners != ""))
+	}
+
+	values := []string{}
+
+	for _, name := range names {
+		excluded := false
+
+		for _, pattern := range s.Exclude {
+			matched, _ := path.Match(pattern, name) // validated when loading
+			excluded = excluded || matched
+		}
+
+		if name != "" && !excluded && !containsString(values, name) {
+			values = append(values, name)
+		}
+	}
+
+	return values, nil
+}
+
+var maintainersFilesRegexp = regexp.MustCompile(`^(?:F|Files):\s*(.*)$`)
+
+// ownedPatterns returns the path patterns of a CODEOWNERS file, the first field of its
+// rules, or of a MAINTAINERS file, the values of its "F:" (Linux) or "Files:" (HAProxy)
+// lines.
+func ownedPatterns(content string, codeOwners bool) []string {
+	patterns := []string{}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case codeOwners:
+			patterns = append(patterns, strings.Fields(line)[0])
+		default:
+			if m := maintainersFilesRegexp.FindStringSubmatch(line); m != nil {
+				patterns = append(patterns, strings.FieldsFunc(m[1], func(r rune) bool {
+					return r == ',' || unicode.IsSpace(r)
+				})...)
+			}
+		}
+	}
+
+	return patterns
+}
+
+// ownedNames names the subsystems of the path patterns: the last element of each path,
+// without its extension, e.g. ssl for src/ssl/ and mux_h2 for src/mux_h2.c, the
+// patterns whose last element is a wildcard naming none.
+func ownedNames(patterns []string) []string {
+	names := []string{}
+
+	for _, pattern := range patterns {
+		name := strings.Trim(pattern, "/")
+		for strings.HasSuffix(name, "/*") || strings.HasSuffix(name, "/**") {
+			name = strings.TrimRight(strings.TrimSuffix(name, "*"), "*/")
+		}
+
+		name = path.Base(name)
+		if strings.ContainsAny(name, "*?[\\") || name == "." || name == "/" {
+			continue
+		}
+
+		if ext := path.Ext(name); ext != name {
+			name = strings.TrimSuffix(name, ext)
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// resolveScopeSources returns the policy with the values generated by the ScopeSources
+// from the tree of rev added to their PatchScopes, after those listed by the policy.
+func (c CommitPolicyConfig) resolveScopeSources(repoPath, rev string) (CommitPolicyConfig, error) {
+	if len(c.ScopeSources) == 0 {
+		return c, nil
+	}
+
+	scopes := make(map[string][]string, len(c.PatchScopes)+len(c.ScopeSources))
+	for name, values := range c.PatchScopes {
+		scopes[name] = values
+	}
+
+	names := make([]string, 0, len(c.ScopeSources))
+	for name := range c.ScopeSources {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		values, err := c.ScopeSources[name].scopeValues(repoPath, rev)
+		if err != nil {
+			return c, fmt.Errorf("scope '%s': %s: %w", name, err, ErrScopeSource)
+		}
+
+		merged := append([]string{}, scopes[name]...)
+
+		for _, value := range values {
+			if !containsString(merged, value) {
+				merged = append(merged, value)
+			}
+		}
+
+		location, _ := c.ScopeSources[name].location()
+		log.Printf("scope %s: %d value(s) from %s", name, len(merged)-len(scopes[name]), location)
+
+		scopes[name] = merged
+	}
+
+	c.PatchScopes = scopes
+
+	return c, nil
+}
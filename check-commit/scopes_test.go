@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveScopeSources(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+
+	files := map[string]string{
+		"src/mux-h2/mux.c": "\n",
+		"src/ssl/ssl.c":    "\n",
+		"src/tests/test.c": "\n",
+		"src/main.c":       "\n",
+		"doc/intro.txt":    "\n",
+		".github/CODEOWNERS": "# owners of the subsystems\n* @core\n/src/ssl/ @tls-team\nsrc/mux_h2.c @h2 @core\n" +
+			"doc/** @docs\n*.md @docs\n",
+		"MAINTAINERS": "Cache\nMaintainer: Alice <alice@example.com>\nFiles: src/cache.c, include/haproxy/cache*.h\n\n" +
+			"QUIC\nM:\tBob <bob@example.com>\nF:\tsrc/quic/\nF:\tsrc/xprt_quic.c\n",
+	}
+
+	writeTestFiles(t, repo, files)
+
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "MINOR: scopes: add the sources"}} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := parseCommitPolicy(conventionalFormatConf + `
+ScopeSources:
+  components:
+    Directory: src/
+    Exclude: [tests]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = c.resolveScopeSources(repo, "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"parser", "cli", "mux-h2", "ssl"}; !reflect.DeepEqual(c.PatchScopes["components"], want) {
+		t.Errorf("resolveScopeSources() = %v, want %v", c.PatchScopes["components"], want)
+	}
+
+	if err := c.CheckSubject([]byte("feat(mux-h2): add the settings of the streams")); err != nil {
+		t.Errorf("CheckSubject() error = %v", err)
+	}
+
+	sources := []struct {
+		source scopeSourceT
+		want   []string
+	}{
+		{scopeSourceT{CodeOwners: ".github/CODEOWNERS"}, []string{"ssl", "mux_h2", "doc"}},
+		{scopeSourceT{Maintainers: "MAINTAINERS", Exclude: []string{"xprt_*"}}, []string{"cache", "quic"}},
+	}
+
+	for _, tt := range sources {
+		if values, err := tt.source.scopeValues(repo, "HEAD"); err != nil || !reflect.DeepEqual(values, tt.want) {
+			t.Errorf("scopeValues(%+v) = %v, %v, want %v", tt.source, values, err, tt.want)
+		}
+	}
+
+	c.ScopeSources["components"] = scopeSourceT{Directory: "lib"}
+	if _, err := c.resolveScopeSources(repo, "HEAD"); !errors.Is(err, ErrScopeSource) {
+		t.Errorf("resolveScopeSources() of a missing directory error = %v, want %v", err, ErrScopeSource)
+	}
+}
+
+func TestValidateScopeSources(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{
+		"ScopeSources:\n  Components:\n    Directory: src\n",
+		"PatchScopes:\n  Components: []\nScopeSources:\n  Components:\n    Directory: ../src\n",
+		"PatchScopes:\n  Components: []\nScopeSources:\n  Components:\n    Directory: src\n    CodeOwners: CODEOWNERS\n",
+		"PatchScopes:\n  Components: []\nScopeSources:\n  Components:\n    Exclude: [tests]\n",
+		"PatchScopes:\n  Components: []\nScopeSources:\n  Components:\n    Directory: src\n    Exclude: ['[']\n",
+	} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrScopeSource) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrScopeSource)
+		}
+	}
+}
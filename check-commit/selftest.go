@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	expectPass = "pass"
+	expectFail = "fail"
+)
+
+// policyTestT is a sample commit of the Tests of the policy, with the outcome the
+// policy must give it: a Subject, or a whole Message for the rules on the body and
+// trailers, expected to pass or to fail, by the given Rule if any.
+type policyTestT struct {
+	Subject string `yaml:"Subject"`
+	Message string `yaml:"Message"`
+	Expect  string `yaml:"Expect"`
+	Rule    string `yaml:"Rule"`
+}
+
+var ErrPolicyTests = errors.New("invalid policy tests")
+
+func (t policyTestT) validate() error {
+	switch {
+	case (t.Subject == "") == (t.Message == ""):
+		return fmt.Errorf("test '%s' must have either a Subject or a Message: %w", t.message(), ErrPolicyTests)
+	case t.Expect != expectPass && t.Expect != expectFail:
+		return fmt.Errorf("test '%s': Expect must be %s or %s: %w", t.message(), expectPass, expectFail, ErrPolicyTests)
+	case t.Rule != "" && t.Expect != expectFail:
+		return fmt.Errorf("test '%s': a Rule can only be expected to fail: %w", t.message(), ErrPolicyTests)
+	}
+
+	return nil
+}
+
+func (t policyTestT) message() string {
+	if t.Message != "" {
+		return t.Message
+	}
+
+	return t.Subject
+}
+
+// run checks the sample commit, returning a description of the outcome and whether it
+// is the expected one. Shadow findings count as they would once enforced, and the
+// rules on the diff do not apply.
+func (t policyTestT) run(c CommitPolicyConfig) (string, bool) {
+	commits, _ := c.skipCommits([]commitT{{SHA: strings.Repeat("0", 40), Message: t.message()}})
+	c.resolveAliases(commits)
+
+	report := reportT{Commits: commits, severities: c.Severities}
+	c.checkCommits(commits, &report)
+
+	failures := []string{}
+	ruleFailed := false
+
+	for _, finding := range report.Findings {
+		if finding.Severity == severityError {
+			failures = append(failures, fmt.Sprintf("%s: %s", finding.Rule, finding.Message))
+			ruleFailed = ruleFailed || finding.Rule == t.Rule
+		}
+	}
+
+	switch {
+	case len(failures) == 0:
+		return "passed", t.Expect == expectPass
+	case t.Expect == expectFail && t.Rule != "" && !ruleFailed:
+		return fmt.Sprintf("failed, but not by %s: %s", t.Rule, strings.Join(failures, "; ")), false
+	}
+
+	return "failed: " + strings.Join(failures, "; "), t.Expect == expectFail
+}
+
+var ErrSelfTest = errors.New("policy tests failed")
+
+// selfTest runs the Tests of the policy, printing the outcome of each.
+func selfTest(opts optionsT, w io.Writer) error {
+	repoEnv, err := readGitEnvironment()
+	if err != nil {
+		repoEnv = LOCAL
+	}
+
+	commitPolicy, err := loadEffectivePolicy(opts, repoEnv)
+	if err != nil {
+		return err
+	}
+
+	if len(commitPolicy.Tests) == 0 {
+		return fmt.Errorf("the policy has no Tests: %w", ErrSelfTest)
+	}
+
+	failures := 0
+
+	for _, test := range commitPolicy.Tests {
+		outcome, ok := test.run(commitPolicy)
+
+		status := doctorOK
+		if !ok {
+			status = doctorFail
+			failures++
+		}
+
+		fmt.Fprintf(w, "%-4s %s (expected to %s): %s\n", status, commitSubject(test.message()), test.Expect, outcome)
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of %d test(s): %w", failures, len(commitPolicy.Tests), ErrSelfTest)
+	}
+
+	return nil
+}
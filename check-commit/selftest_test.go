@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyTestRun(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + `
+CustomRules:
+  - Name: no-wip
+    Regex: 'WIP'
+    Match: must-not
+    Message: "work in progress"
+    Shadow: true
+Ignore:
+  Subjects: ['^Merge ']
+`)
+	if err != nil {
+		t.Fatalf("parseCommitPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		test        policyTestT
+		wantOutcome string
+		wantOK      bool
+	}{
+		{policyTestT{Subject: "BUG/MINOR: config: fix the parsing of timeouts", Expect: expectPass}, "passed", true},
+		{policyTestT{Subject: "bug: fix the parsing of timeouts", Expect: expectFail}, "failed: tag: ", true},
+		{policyTestT{Subject: "bug: fix the parsing of timeouts", Expect: expectPass}, "failed: tag: ", false},
+		{policyTestT{Subject: "BUG/MINOR: config: fix the parsing of timeouts", Expect: expectFail}, "passed", false},
+		{policyTestT{Subject: "bug: fix the parsing of timeouts", Expect: expectFail, Rule: ruleTag}, "failed: tag: ", true},
+		{policyTestT{Subject: "bug: fix the parsing of timeouts", Expect: expectFail, Rule: ruleLanguage},
+			"failed, but not by language: tag: ", false},
+		{policyTestT{Subject: "MINOR: config: WIP on the timeouts", Expect: expectFail, Rule: "custom:no-wip"},
+			"failed: custom:no-wip: ", true},
+		{policyTestT{Subject: "Merge branch 'main'", Expect: expectPass}, "passed", true},
+	}
+
+	for _, tt := range tests {
+		outcome, ok := tt.test.run(c)
+		if !strings.HasPrefix(outcome, tt.wantOutcome) || ok != tt.wantOK {
+			t.Errorf("run(%+v) = %q, %t, want %q, %t", tt.test, outcome, ok, tt.wantOutcome, tt.wantOK)
+		}
+	}
+}
+
+func TestPolicyTestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []policyTestT{
+		{Expect: expectPass},
+		{Subject: "MINOR: a", Message: "MINOR: a\n\nbody", Expect: expectPass},
+		{Subject: "MINOR: a", Expect: "accepted"},
+		{Subject: "MINOR: a", Expect: expectPass, Rule: ruleTag},
+	}
+
+	for _, test := range tests {
+		if err := test.validate(); !errors.Is(err, ErrPolicyTests) {
+			t.Errorf("validate(%+v) error = %v, want %v", test, err, ErrPolicyTests)
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	t.Parallel()
+
+	repo := t.TempDir()
+	config := defaultConf + `
+Tests:
+  - Subject: "BUG/MINOR: config: fix the parsing of timeouts"
+    Expect: pass
+  - Message: "bug: fix the parsing of timeouts\n\nIt was wrong."
+    Expect: fail
+    Rule: tag
+`
+
+	if err := ioutil.WriteFile(filepath.Join(repo, policyFile), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := selfTest(optionsT{repoPath: repo}, &out); err != nil {
+		t.Errorf("selfTest() error = %v\n%s", err, out.String())
+	}
+
+	if want := "ok   bug: fix the parsing of timeouts (expected to fail): failed: tag: "; !strings.Contains(out.String(), want) {
+		t.Errorf("selfTest() output lacks %q:\n%s", want, out.String())
+	}
+
+	config = strings.Replace(config, "Expect: pass", "Expect: fail", 1)
+	if err := ioutil.WriteFile(filepath.Join(repo, policyFile), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+
+	if err := selfTest(optionsT{repoPath: repo}, &out); !errors.Is(err, ErrSelfTest) {
+		t.Errorf("selfTest() error = %v, want %v", err, ErrSelfTest)
+	}
+
+	if want := "FAIL BUG/MINOR: config: fix the parsing of timeouts (expected to fail): passed\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("selfTest() output lacks %q:\n%s", want, out.String())
+	}
+}
+
+func TestSelfTestRepositoryFiles(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+	writeTestFiles(t, repo, map[string]string{"src/ssl/ssl.c": "\n", "denylist.txt": "project falcon\n"})
+
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "MINOR: selftest: add the sources"}} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeTestFiles(t, repo, map[string]string{policyFile: conventionalFormatConf + `
+ScopeSources:
+  components:
+    Directory: src/
+Denylist:
+  File: denylist.txt
+Tests:
+  - Subject: "feat(ssl): add the ciphers of the handshake"
+    Expect: pass
+  - Subject: "feat(parser): add the keywords of project falcon"
+    Expect: fail
+    Rule: denylist
+`})
+
+	var out bytes.Buffer
+	if err := selfTest(optionsT{repoPath: repo}, &out); err != nil {
+		t.Errorf("selfTest() error = %v\n%s", err, out.String())
+	}
+}
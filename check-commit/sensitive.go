@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// sensitivePathT requires commits touching some paths to carry one of Tags, or to have
+// one of Trailers (e.g. "Acked-by"), either being enough when both are given.
+type sensitivePathT struct {
+	Paths    []string `yaml:"Paths"`
+	Tags     []string `yaml:"Tags"`
+	Trailers []string `yaml:"Trailers"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrSensitivePathConfig = errors.New("invalid sensitive path rule")
+
+func (s sensitivePathT) validate() error {
+	if len(s.Paths) == 0 {
+		return fmt.Errorf("sensitive path rule without Paths: %w", ErrSensitivePathConfig)
+	}
+
+	for _, pattern := range s.Paths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("sensitive path rule: pattern '%s': %s: %w", pattern, err, ErrSensitivePathConfig)
+		}
+	}
+
+	if len(s.Tags) == 0 && len(s.Trailers) == 0 {
+		return fmt.Errorf("sensitive path rule for [%s] requires neither Tags nor Trailers: %w",
+			strings.Join(s.Paths, ", "), ErrSensitivePathConfig)
+	}
+
+	if !validSeverity(s.Severity) {
+		return fmt.Errorf("sensitive path rule: unknown severity '%s': %w", s.Severity, ErrSensitivePathConfig)
+	}
+
+	return nil
+}
+
+func (s sensitivePathT) severity() string {
+	if s.Severity == "" {
+		return severityError
+	}
+
+	return s.Severity
+}
+
+func (s sensitivePathT) hasTrailer(commit commitT) bool {
+	for _, trailer := range commit.Trailers() {
+		for _, key := range s.Trailers {
+			if strings.HasPrefix(strings.ToLower(trailer), strings.ToLower(key)+": ") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// requirement describes what the commit lacks, for the error message.
+func (s sensitivePathT) requirement() string {
+	parts := []string{}
+	if len(s.Tags) > 0 {
+		parts = append(parts, "one of the ["+strings.Join(s.Tags, ", ")+"] tags")
+	}
+
+	if len(s.Trailers) > 0 {
+		parts = append(parts, "one of the ["+strings.Join(s.Trailers, ", ")+"] trailers")
+	}
+
+	return strings.Join(parts, " or ")
+}
+
+var ErrSensitivePath = errors.New("sensitive path modified without the required tag or trailer")
+
+func (s sensitivePathT) Check(commit commitT) error {
+	if !commit.HasDiff {
+		return nil
+	}
+
+	touched := []string{}
+	seen := map[string]bool{}
+
+	for _, file := range commit.Files {
+		for _, name := range []string{file.Path, file.OldPath} {
+			if name != "" && !seen[name] && matchAnyGlob(s.Paths, name) {
+				seen[name] = true
+				touched = append(touched, name)
+			}
+		}
+	}
+
+	if len(touched) == 0 || hasAnyValue(commit.Tags(), s.Tags) || s.hasTrailer(commit) {
+		return nil
+	}
+
+	return fmt.Errorf("commit modifies [%s] and requires %s: %w", strings.Join(touched, ", "), s.requirement(),
+		ErrSensitivePath)
+}
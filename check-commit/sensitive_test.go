@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSensitivePathCheck(t *testing.T) {
+	t.Parallel()
+
+	s := sensitivePathT{Paths: []string{"vendor/**", "src/ssl_*.c"}, Tags: []string{"BUILD"}, Trailers: []string{"Acked-by"}}
+
+	files := func(names ...string) []fileDiffT {
+		result := []fileDiffT{}
+		for _, name := range names {
+			result = append(result, fileDiffT{Path: name, OldPath: name, Status: fileModified})
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		commit  commitT
+		wantErr bool
+	}{
+		{"other paths", commitT{Message: "MINOR: mux: add a flag", Files: files("src/mux_h1.c"), HasDiff: true}, false},
+		{"vendored code", commitT{Message: "MINOR: deps: update", Files: files("vendor/lib/a.go"), HasDiff: true}, true},
+		{"tagged", commitT{Message: "BUILD: deps: update", Files: files("vendor/lib/a.go"), HasDiff: true}, false},
+		{"acked", commitT{Message: "BUG/MEDIUM: ssl: fix a leak\n\nAcked-by: Willy <w@example.com>",
+			Files: files("src/ssl_sock.c"), HasDiff: true}, false},
+		{"trailer not in the trailers paragraph", commitT{Message: "BUG/MEDIUM: ssl: fix a leak\n\nAcked-by: nobody\n\nsee above",
+			Files: files("src/ssl_sock.c"), HasDiff: true}, true},
+		{"moved out", commitT{Message: "MINOR: deps: unvendor", HasDiff: true,
+			Files: []fileDiffT{{Path: "lib/a.go", OldPath: "vendor/lib/a.go", Status: fileRenamed}}}, true},
+		{"diff not loaded", commitT{Message: "MINOR: deps: update"}, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := s.Check(tt.commit)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrSensitivePath)) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, config := range []string{
+		"SensitivePaths:\n  - Tags: [BUILD]\n",
+		"SensitivePaths:\n  - Paths: [vendor/**]\n",
+		"SensitivePaths:\n  - Paths: ['[']\n    Tags: [BUILD]\n",
+	} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrSensitivePathConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrSensitivePathConfig)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const severityOff = "off"
+
+// builtinRules are the rules whose severity can be set with Severities, besides the
+// custom rules.
+var builtinRules = []string{
+	ruleTag, ruleSubjectFormat, ruleLanguage, ruleProtectedBranch, ruleMaxCommits, ruleLabels, ruleLinkedIssues,
+	ruleApprovals, ruleRevertOfRevert, ruleReorgPurity, ruleCleanupNeutrality, ruleDocumentation, ruleEncoding,
+	ruleCommitSize, ruleSensitivePaths, ruleVersionFile, ruleSignatures, ruleTagConstraints, ruleComponent,
+	ruleForbiddenWords, ruleDeprecatedTag, rulePathRules, ruleBotFormat,
+	ruleImperativeMood, ruleTrailingPunct, ruleCapitalization, ruleCharset,
+	ruleWhitespace, ruleTicketReference, ruleAutosquash,
+	ruleRevert, ruleSpelling, ruleDuplicateSubject,
+	ruleSubjectURL, ruleVagueSubject, ruleForbiddenChars,
+	ruleDenylist, ruleTrailingIssue,
+}
+
+var ErrSeverities = errors.New("invalid severities")
+
+func (c CommitPolicyConfig) validateSeverities() error {
+	for rule, severity := range c.Severities {
+		rules := append(append([]string{}, builtinRules...), c.customRuleNames()...)
+		if !containsString(rules, rule) {
+			return fmt.Errorf("Severities: unknown rule '%s'%s: %w", rule, didYouMean(rule, rules), ErrSeverities)
+		}
+
+		switch severity {
+		case severityError, severityWarning, severityOff:
+		default:
+			return fmt.Errorf("Severities: rule '%s': unknown severity '%s', expected %s: %w", rule, severity,
+				strings.Join([]string{severityError, severityWarning, severityOff}, ", "), ErrSeverities)
+		}
+	}
+
+	return nil
+}
+
+func (c CommitPolicyConfig) customRuleNames() []string {
+	names := []string{}
+	for _, rule := range c.CustomRules {
+		names = append(names, ruleCustomPrefix+rule.Name)
+	}
+
+	return names
+}
+
+// downgradedTags tells whether the tag rule is set to something less than an error,
+// in which case the rest of the subject is still checked when the tags are invalid.
+func (c CommitPolicyConfig) downgradedTags() bool {
+	severity, ok := c.Severities[ruleTag]
+
+	return ok && severity != severityError
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSeverities(t *testing.T) {
+	t.Parallel()
+
+	commits := []commitT{
+		{SHA: "0123456789abcdef", Message: "fix the parser"},
+		{SHA: "fedcba9876543210", Message: "BUG/MINOR: parser: fix crash on empty files wip"},
+	}
+
+	tests := []struct {
+		name       string
+		severities string
+		want       []string // rule:severity of the findings
+	}{
+		{"defaults", "", []string{"tag:error", "custom:no-wip:error"}},
+		{"warning", "Severities:\n  custom:no-wip: warning\n", []string{"tag:error", "custom:no-wip:warning"}},
+		{"off", "Severities:\n  custom:no-wip: off\n", []string{"tag:error"}},
+		{"downgraded tags still check the wording", "Severities:\n  tag: warning\n",
+			[]string{"tag:warning", "subject-format:error", "custom:no-wip:error"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := parseCommitPolicy(defaultConf + tt.severities +
+				"CustomRules:\n  - Name: no-wip\n    Regex: '\\bwip\\b'\n    Match: must-not\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report := reportT{severities: c.Severities}
+			c.checkCommits(commits, &report)
+
+			got := []string{}
+			for _, finding := range report.Findings {
+				got = append(got, finding.Rule+":"+finding.Severity)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSeverities(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{"Severities:\n  tags: off\n", "Severities:\n  tag: fatal\n",
+		"Severities:\n  custom:missing: off\n"} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrSeverities) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want ErrSeverities", config, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+)
+
+// checkShadowPolicy evaluates the alternate policy of the options against the commits of
+// the report and adds its findings as shadow findings, e.g. to measure the impact of a
+// stricter policy before enforcing it.
+func checkShadowPolicy(opts optionsT, repoEnv string, report *reportT) error {
+	filename, repoPath := opts.shadowPolicy, opts.repoPath
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("error reading shadow policy: %w", err)
+	}
+
+	config, err := decodePolicy(string(data))
+	if err == nil {
+		config, err = resolveExtends(config, "")
+	}
+
+	if err == nil {
+		config, err = applyPreset(config, "")
+	}
+
+	if err == nil {
+		config, err = applyBranchOverrides(config, targetBranch())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	shadowPolicy, err := parseCommitPolicy(config)
+	if err == nil {
+		shadowPolicy, err = opts.resolvePolicyFiles(shadowPolicy, repoEnv)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	shadowPolicy.loadDiffs(repoPath, report.Commits)
+
+	shadowReport := shadowPolicy.newReport(report.Commits)
+	shadowReport.shadow = true
+	shadowPolicy.checkCommits(report.Commits, &shadowReport)
+	shadowPolicy.checkEncodings(repoPath, report.Commits, &shadowReport)
+	shadowPolicy.checkReverts(repoPath, report.Commits, &shadowReport)
+
+	log.Printf("shadow policy %s: %d error(s), %d warning(s) that would have been reported", filename,
+		shadowReport.CountShadow(severityError), shadowReport.CountShadow(severityWarning))
+
+	report.Findings = append(report.Findings, shadowReport.Findings...)
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestShadowFindings(t *testing.T) {
+	t.Parallel()
+
+	commit := commitT{SHA: "1111111111111111111111111111111111111111", Message: "BUG/MINOR: mux: fix a crash on close"}
+	report := reportT{Commits: []commitT{commit}}
+
+	c, err := parseCommitPolicy(defaultConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.CustomRules = []customRuleT{
+		{Name: "enforced", Regex: "crash", Match: matchMustNot},
+		{Name: "trial", Regex: "^BUG/MINOR: [A-Z]", Shadow: true},
+	}
+	c.checkCommits(report.Commits, &report)
+
+	filename := filepath.Join(t.TempDir(), "shadow.yml")
+
+	err = ioutil.WriteFile(filename, []byte(defaultConf+"CustomRules:\n  - Name: ticket\n    Target: trailer\n    Regex: '^Ticket: '\n"+
+		"    Severity: warning\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkShadowPolicy(optionsT{shadowPolicy: filename, repoPath: "."}, LOCAL, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := report.Count(severityError); got != 1 {
+		t.Errorf("Count(error) = %d, want 1", got)
+	}
+
+	if got := report.CountShadow(severityError); got != 1 {
+		t.Errorf("CountShadow(error) = %d, want 1", got)
+	}
+
+	if got := report.CountShadow(severityWarning); got != 1 {
+		t.Errorf("CountShadow(warning) = %d, want 1", got)
+	}
+
+	stats := report.stats()
+	if stats.Rules[ruleCustomPrefix+"ticket (shadow)"].Warnings != 1 || stats.Rules[ruleCustomPrefix+"enforced"].Errors != 1 {
+		t.Errorf("stats() = %+v", stats)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisign formats, see https://jedisct1.github.io/minisign/
+const (
+	minisignAlgLen    = 2
+	minisignKeyIDLen  = 8
+	minisignKeyLen    = minisignAlgLen + minisignKeyIDLen + ed25519.PublicKeySize
+	minisignSigLen    = minisignAlgLen + minisignKeyIDLen + ed25519.SignatureSize
+	trustedCommentTag = "trusted comment: "
+)
+
+type minisignKeyT struct {
+	id  []byte
+	key ed25519.PublicKey
+}
+
+var ErrPolicySignature = errors.New("invalid policy signature")
+
+// lastLine returns the last line of s that is neither empty nor a comment, so that
+// keys can be given either bare or as the content of a minisign.pub file.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "untrusted comment:") {
+			return line
+		}
+	}
+
+	return ""
+}
+
+func parseMinisignKey(s string) (minisignKeyT, error) {
+	data, err := base64.StdEncoding.DecodeString(lastLine(s))
+	if err != nil || len(data) != minisignKeyLen || string(data[:minisignAlgLen]) != "Ed" {
+		return minisignKeyT{}, fmt.Errorf("public key is not a minisign Ed25519 key: %w", ErrPolicySignature)
+	}
+
+	return minisignKeyT{
+		id:  data[minisignAlgLen : minisignAlgLen+minisignKeyIDLen],
+		key: ed25519.PublicKey(data[minisignAlgLen+minisignKeyIDLen:]),
+	}, nil
+}
+
+// verify checks a minisign signature of message: the signature itself, legacy or
+// pre-hashed, then the global signature covering the trusted comment.
+func (k minisignKeyT) verify(message []byte, signature string) error {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(signature, "\r\n", "\n")), "\n")
+
+	const signatureLines = 4
+	if len(lines) != signatureLines || !strings.HasPrefix(lines[2], trustedCommentTag) {
+		return fmt.Errorf("signature is not in minisign format: %w", ErrPolicySignature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != minisignSigLen {
+		return fmt.Errorf("signature is not in minisign format: %w", ErrPolicySignature)
+	}
+
+	if !bytes.Equal(sig[minisignAlgLen:minisignAlgLen+minisignKeyIDLen], k.id) {
+		return fmt.Errorf("signature was made with another key: %w", ErrPolicySignature)
+	}
+
+	switch string(sig[:minisignAlgLen]) {
+	case "Ed":
+	case "ED":
+		hash := blake2b.Sum512(message)
+		message = hash[:]
+	default:
+		return fmt.Errorf("unknown signature algorithm: %w", ErrPolicySignature)
+	}
+
+	if !ed25519.Verify(k.key, message, sig[minisignAlgLen+minisignKeyIDLen:]) {
+		return fmt.Errorf("signature does not match the policy: %w", ErrPolicySignature)
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid global signature: %w", ErrPolicySignature)
+	}
+
+	signed := append([]byte{}, sig[minisignAlgLen+minisignKeyIDLen:]...)
+	signed = append(signed, strings.TrimPrefix(lines[2], trustedCommentTag)...)
+
+	if !ed25519.Verify(k.key, signed, globalSig) {
+		return fmt.Errorf("trusted comment was tampered with: %w", ErrPolicySignature)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMinisignVerify(t *testing.T) {
+	t.Parallel()
+
+	key, err := parseMinisignKey("untrusted comment: minisign public key\nRWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := "untrusted comment: signature from minisign secret key\n" +
+		"RWQf6LRCGA9i59SLOFxz6NxvASXDJeRtuZykwQepbDEGt87ig1BNpWaVWuNrm73YiIiJbq71Wi+dP9eKL8OC351vwIasSSbXxwA=\n" +
+		"trusted comment: timestamp:1635442742\tfile:test\n" +
+		"0YteLgV960ia80vnA/fHbvkyjl/IoP/HNOCaZfrF0CdhAlp7ok+Tpkya+VpWPX5C/Is3q8a/kEDSY7fBmmgJCg==\n"
+	prehashed := "untrusted comment: signature from minisign secret key\n" +
+		"RUQf6LRCGA9i559r3g7V1qNyJDApGip8MfqcadIgT9CuhV3EMhHoN1mGTkUidF/z7SrlQgXdy8ofjb7bNJJylDOocrCo8KLzZwo=\n" +
+		"trusted comment: timestamp:1635443258\tfile:test\thashed\n" +
+		"/cj37GK60vryibFn+ftOgbCvW9NKhKYgjVpFFQUcWPAnjO23wrvVDTt7cloNC06maoBli9q6qwZDXXoaxweICQ==\n"
+
+	tests := []struct {
+		name      string
+		message   string
+		signature string
+		wantErr   bool
+	}{
+		{name: "legacy", message: "test", signature: legacy},
+		{name: "prehashed", message: "test", signature: prehashed},
+		{name: "tampered message", message: "test2", signature: prehashed, wantErr: true},
+		{
+			name:      "tampered trusted comment",
+			message:   "test",
+			signature: strings.Replace(legacy, "file:test", "file:other", 1),
+			wantErr:   true,
+		},
+		{name: "garbage", message: "test", signature: "not a signature", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := key.verify([]byte(tt.message), tt.signature)
+		if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrPolicySignature)) {
+			t.Errorf("%s: verify() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	other, err := parseMinisignKey("RWTB/yLPjeVJkXKtzk1nZI0TU+fZPqEaIzg1ABHwfnI8pZNWtifIpWBq")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := other.verify([]byte("test"), legacy); !errors.Is(err, ErrPolicySignature) {
+		t.Errorf("verify() with another key error = %v", err)
+	}
+
+	if _, err := parseMinisignKey("RWQf6LRC"); !errors.Is(err, ErrPolicySignature) {
+		t.Errorf("parseMinisignKey() error = %v", err)
+	}
+}
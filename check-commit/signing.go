@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+type signaturesT struct {
+	Require        bool   `yaml:"Require"`
+	AllowedSigners string `yaml:"AllowedSigners"`
+	Severity       string `yaml:"Severity"`
+	Shadow         bool   `yaml:"Shadow"`
+
+	signers string // content of the AllowedSigners file
+}
+
+var ErrSignaturesConfig = errors.New("invalid signatures rule")
+
+func (s signaturesT) validate() error {
+	if !validSeverity(s.Severity) {
+		return fmt.Errorf("signatures rule: unknown severity '%s': %w", s.Severity, ErrSignaturesConfig)
+	}
+
+	return nil
+}
+
+func (s signaturesT) severity() string {
+	if s.Severity == "" {
+		return severityError
+	}
+
+	return s.Severity
+}
+
+// resolveAllowedSigners returns the policy with the AllowedSigners file of the signatures
+// rule read from the tree of rev.
+func (c CommitPolicyConfig) resolveAllowedSigners(repoPath, rev string) (CommitPolicyConfig, error) {
+	if !c.Signatures.Require || c.Signatures.AllowedSigners == "" {
+		return c, nil
+	}
+
+	content, err := runGit(repoPath, "show", rev+":"+path.Clean(c.Signatures.AllowedSigners))
+	if err != nil {
+		return c, fmt.Errorf("error reading %s: %s: %w", c.Signatures.AllowedSigners, err, ErrSignaturesConfig)
+	}
+
+	c.Signatures.signers = content
+
+	return c, nil
+}
+
+var (
+	ErrUnsignedCommit      = errors.New("commit signature not verified")
+	ErrVerifierUnavailable = errors.New("signature verifier unavailable")
+)
+
+// signatureVerifierFunc verifies the signature of a commit, returning ErrUnsignedCommit
+// when it is missing or invalid, ErrVerifierUnavailable when the program verifying it is
+// missing, and other errors when it cannot tell.
+type signatureVerifierFunc func(sha string) error
+
+// signatureProgram returns the program git runs to verify the signature of the commit
+// object, or "" when the commit is not signed.
+func signatureProgram(repoPath, object string) string {
+	header := object
+	if i := strings.Index(object, "\n\n"); i >= 0 {
+		header = object[:i]
+	}
+
+	i := strings.Index(header, "\ngpgsig")
+	if i < 0 {
+		return ""
+	}
+
+	signature := header[i:]
+	keys, program := []string{"gpg.openpgp.program", "gpg.program"}, "gpg"
+
+	switch {
+	case strings.Contains(signature, "-----BEGIN SSH SIGNATURE-----"):
+		keys, program = []string{"gpg.ssh.program"}, "ssh-keygen"
+	case strings.Contains(signature, "-----BEGIN SIGNED MESSAGE-----"):
+		keys, program = []string{"gpg.x509.program"}, "gpgsm"
+	}
+
+	for _, key := range keys {
+		if configured, err := runGit(repoPath, "config", "--get", key); err == nil && strings.TrimSpace(configured) != "" {
+			return strings.TrimSpace(configured)
+		}
+	}
+
+	return program
+}
+
+// gitVerifier verifies signatures offline with git verify-commit: GPG signatures
+// against the keyring of the runner, SSH ones against the allowed signers file.
+func gitVerifier(repoPath, allowedSignersFile string) signatureVerifierFunc {
+	args := []string{}
+	if allowedSignersFile != "" {
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+allowedSignersFile)
+	}
+
+	return func(sha string) error {
+		object, err := runGit(repoPath, "cat-file", "commit", sha)
+		if err != nil {
+			return err
+		}
+
+		program := signatureProgram(repoPath, object)
+		if program == "" {
+			return fmt.Errorf("commit is not signed: %w", ErrUnsignedCommit)
+		}
+
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Errorf("%s is not available: %w", program, ErrVerifierUnavailable)
+		}
+
+		if _, err := runGit(repoPath, append(args, "verify-commit", sha)...); err != nil {
+			return fmt.Errorf("not signed by a trusted key: %w", ErrUnsignedCommit)
+		}
+
+		return nil
+	}
+}
+
+func githubVerifier() (signatureVerifierFunc, error) {
+	parts := strings.SplitN(os.Getenv("GITHUB_REPOSITORY"), "/", 2)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("no repository in GITHUB_REPOSITORY: %w", ErrGitEnvironment)
+	}
+
+	ctx := context.Background()
+	client := newGithubClient(ctx)
+
+	return func(sha string) error {
+		commit, _, err := client.Repositories.GetCommit(ctx, parts[0], parts[1], sha)
+		if err != nil {
+			return fmt.Errorf("error fetching commit %s: %w", shortSHA(sha), err)
+		}
+
+		if verification := commit.GetCommit().GetVerification(); !verification.GetVerified() {
+			return fmt.Errorf("signature %s: %w", verification.GetReason(), ErrUnsignedCommit)
+		}
+
+		return nil
+	}, nil
+}
+
+func gitlabVerifier() (signatureVerifierFunc, error) {
+	client, err := newGitlabClient()
+	if err != nil {
+		return nil, err
+	}
+
+	project := os.Getenv("CI_PROJECT_PATH")
+
+	return func(sha string) error {
+		signature, resp, err := client.Commits.GetGPGSiganature(project, sha)
+		if resp != nil && resp.StatusCode == 404 {
+			return fmt.Errorf("commit is not signed: %w", ErrUnsignedCommit)
+		} else if err != nil {
+			return fmt.Errorf("error fetching the signature of commit %s: %w", shortSHA(sha), err)
+		}
+
+		if signature.VerificationStatus != "verified" {
+			return fmt.Errorf("signature %s: %w", signature.VerificationStatus, ErrUnsignedCommit)
+		}
+
+		return nil
+	}, nil
+}
+
+// signatureVerifiers returns the ways to verify signatures in order of preference:
+// the platform API when there is a token, then git itself, which works offline.
+func (s signaturesT) signatureVerifiers(repoEnv, repoPath, allowedSignersFile string) []signatureVerifierFunc {
+	verifiers := []signatureVerifierFunc{}
+
+	if os.Getenv("API_TOKEN") != "" {
+		var verifier signatureVerifierFunc
+
+		var err error
+
+		switch repoEnv {
+		case GITHUB:
+			verifier, err = githubVerifier()
+		case GITLAB:
+			verifier, err = gitlabVerifier()
+		}
+
+		if err != nil {
+			log.Printf("warning: verifying signatures locally: %s", err)
+		} else if verifier != nil {
+			verifiers = append(verifiers, verifier)
+		}
+	}
+
+	return append(verifiers, gitVerifier(repoPath, allowedSignersFile))
+}
+
+// verifySignature tries the verifiers in turn until one can tell whether the signature is
+// valid, or the last one cannot be run.
+func verifySignature(verifiers []signatureVerifierFunc, sha string) error {
+	var err error
+
+	for _, verifier := range verifiers {
+		if err = verifier(sha); err == nil || errors.Is(err, ErrUnsignedCommit) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// writeAllowedSigners writes the allowed signers of the policy to a temporary file for
+// git, returning its name, or "" when there are none, and a function removing it.
+func (s signaturesT) writeAllowedSigners() (string, func(), error) {
+	if s.signers == "" {
+		return "", func() {}, nil
+	}
+
+	file, err := ioutil.TempFile("", "allowed_signers")
+	if err != nil {
+		return "", nil, err
+	}
+
+	remove := func() { os.Remove(file.Name()) }
+
+	if _, err = file.WriteString(s.signers); err == nil {
+		err = file.Close()
+	}
+
+	if err != nil {
+		file.Close()
+		remove()
+
+		return "", nil, err
+	}
+
+	return file.Name(), remove, nil
+}
+
+// checkSignatures reports the commits that are not signed, or whose signature cannot be
+// verified for lack of a verifier, and skips those missing from the clone.
+func (c CommitPolicyConfig) checkSignatures(repoEnv, repoPath string, commits []commitT, report *reportT) {
+	if !c.Signatures.Require {
+		return
+	}
+
+	allowedSignersFile, remove, err := c.Signatures.writeAllowedSigners()
+	if err != nil {
+		report.AddError(ruleSignatures, fmt.Errorf("error writing the allowed signers: %w", err))
+
+		return
+	}
+
+	defer remove()
+
+	verifiers := c.Signatures.signatureVerifiers(repoEnv, repoPath, allowedSignersFile)
+
+	for _, commit := range commits {
+		err := verifySignature(verifiers, commit.SHA)
+		if err != nil && !errors.Is(err, ErrUnsignedCommit) && !errors.Is(err, ErrVerifierUnavailable) {
+			log.Printf("warning: skipping signature check of commit %s: %s", shortSHA(commit.SHA), err)
+
+			continue
+		}
+
+		report.AddCommitFinding(ruleSignatures, c.Signatures.severity(), c.Signatures.Shadow, commit, err)
+	}
+}
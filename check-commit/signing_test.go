@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSignatures(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "BUG/MINOR: mux: fix a crash on close")
+
+	if _, err := runGit(repo, "config", "gpg.ssh.program", filepath.Join(t.TempDir(), "ssh-keygen")); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runGit(repo, "rev-parse", "HEAD", "HEAD^{tree}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shas := strings.Fields(out)
+	object := "tree " + shas[1] + "\n" +
+		"author Check Commit <check-commit@example.com> 0 +0000\n" +
+		"committer Check Commit <check-commit@example.com> 0 +0000\n" +
+		"gpgsig -----BEGIN SSH SIGNATURE-----\n U1NIU0lH\n -----END SSH SIGNATURE-----\n" +
+		"\nBUG/MINOR: mux: fix another crash on close\n"
+
+	signed, err := runGitInput(repo, object, "hash-object", "-t", "commit", "-w", "--stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commits := []commitT{
+		{SHA: shas[0]}, {SHA: strings.TrimSpace(signed)}, {SHA: "2222222222222222222222222222222222222222"},
+	}
+	report := reportT{Commits: commits}
+	policy := CommitPolicyConfig{Signatures: signaturesT{Require: true}}
+	policy.checkSignatures(LOCAL, repo, commits, &report)
+
+	if len(report.Findings) != 2 ||
+		report.Findings[0].SHA != commits[0].SHA ||
+		!strings.Contains(report.Findings[0].Message, ErrUnsignedCommit.Error()) ||
+		report.Findings[1].SHA != commits[1].SHA ||
+		!strings.Contains(report.Findings[1].Message, ErrVerifierUnavailable.Error()) {
+		t.Errorf("checkSignatures() = %+v", report.Findings)
+	}
+}
+
+func TestCheckSignaturesSSH(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not available")
+	}
+
+	repo := newTestRepo(t, "BUG/MINOR: mux: fix a crash on close")
+	key := filepath.Join(t.TempDir(), "key")
+
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", key).
+		CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %s: %s", err, out)
+	}
+
+	public, err := ioutil.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers := "test@example.com " + string(public)
+	if err := ioutil.WriteFile(filepath.Join(repo, "allowed_signers"), []byte(signers), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := CommitPolicyConfig{Signatures: signaturesT{Require: true, AllowedSigners: "allowed_signers"}}
+	if _, err := policy.resolveAllowedSigners(repo, "HEAD"); err == nil {
+		t.Error("resolveAllowedSigners() read the allowed signers from the working tree")
+	}
+
+	for _, args := range [][]string{
+		{"add", "allowed_signers"},
+		{"-c", "gpg.format=ssh", "-c", "user.signingkey=" + key, "commit", "-q", "-S", "-m",
+			"BUG/MINOR: mux: fix another crash on close"},
+	} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if policy, err = policy.resolveAllowedSigners(repo, "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runGit(repo, "rev-parse", "HEAD", "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shas := strings.Fields(out)
+	commits := []commitT{{SHA: shas[0]}, {SHA: shas[1]}}
+	report := reportT{Commits: commits}
+	policy.checkSignatures(LOCAL, repo, commits, &report)
+
+	if len(report.Findings) != 1 || report.Findings[0].SHA != shas[1] || report.Findings[0].Rule != ruleSignatures ||
+		!strings.Contains(report.Findings[0].Message, ErrUnsignedCommit.Error()) {
+		t.Errorf("checkSignatures() = %+v", report.Findings)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type commitSizeT struct {
+	MaxLines   int      `yaml:"MaxLines"`
+	MaxFiles   int      `yaml:"MaxFiles"`
+	ExemptTags []string `yaml:"ExemptTags"`
+	Severity   string   `yaml:"Severity"`
+	Shadow     bool     `yaml:"Shadow"`
+}
+
+var ErrCommitSizeConfig = errors.New("invalid commit size rule")
+
+func (s commitSizeT) validate() error {
+	if s.MaxLines < 0 || s.MaxFiles < 0 {
+		return fmt.Errorf("commit size rule: negative limit: %w", ErrCommitSizeConfig)
+	}
+
+	if !validSeverity(s.Severity) {
+		return fmt.Errorf("commit size rule: unknown severity '%s': %w", s.Severity, ErrCommitSizeConfig)
+	}
+
+	return nil
+}
+
+// severity defaults to warning: large commits are discouraged, some are legitimate.
+func (s commitSizeT) severity() string {
+	if s.Severity == "" {
+		return severityWarning
+	}
+
+	return s.Severity
+}
+
+// appliesTo skips commits carrying one of ExemptTags (tag or severity), such as REORG
+// commits moving whole files.
+func (s commitSizeT) appliesTo(commit commitT) bool {
+	return (s.MaxLines > 0 || s.MaxFiles > 0) && !hasAnyValue(commit.Tags(), s.ExemptTags)
+}
+
+var ErrCommitTooLarge = errors.New("commit too large")
+
+func (s commitSizeT) Check(commit commitT) error {
+	if !commit.HasDiff || !s.appliesTo(commit) {
+		return nil
+	}
+
+	lines := 0
+	for _, file := range commit.Files {
+		lines += len(file.Added) + len(file.Removed)
+	}
+
+	if s.MaxFiles > 0 && len(commit.Files) > s.MaxFiles {
+		return fmt.Errorf("%d files changed, more than the %d allowed, please split the commit: %w",
+			len(commit.Files), s.MaxFiles, ErrCommitTooLarge)
+	}
+
+	if s.MaxLines > 0 && lines > s.MaxLines && !commit.NamesOnly {
+		return fmt.Errorf("%d lines changed, more than the %d allowed, please split the commit: %w",
+			lines, s.MaxLines, ErrCommitTooLarge)
+	}
+
+	return nil
+}
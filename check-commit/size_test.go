@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommitSizeCheck(t *testing.T) {
+	t.Parallel()
+
+	s := commitSizeT{MaxLines: 4, MaxFiles: 2, ExemptTags: []string{"REORG"}}
+
+	file := func(name string, added int) fileDiffT {
+		return fileDiffT{Path: name, OldPath: name, Status: fileModified, Added: make([]string, added),
+			Removed: []string{"old"}}
+	}
+
+	tests := []struct {
+		name    string
+		commit  commitT
+		wantErr bool
+	}{
+		{"small", commitT{Message: "MINOR: mux: add a flag", Files: []fileDiffT{file("a.c", 2)}, HasDiff: true}, false},
+		{"too many lines", commitT{Message: "MINOR: mux: add a flag", Files: []fileDiffT{file("a.c", 4)}, HasDiff: true}, true},
+		{"too many files", commitT{Message: "MINOR: mux: add a flag",
+			Files: []fileDiffT{file("a.c", 0), file("b.c", 0), file("c.c", 0)}, HasDiff: true}, true},
+		{"exempt", commitT{Message: "REORG: mux: split the file", Files: []fileDiffT{file("a.c", 40)}, HasDiff: true}, false},
+		{"diff not loaded", commitT{Message: "MINOR: mux: add a flag"}, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := s.Check(tt.commit)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrCommitTooLarge)) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := parseCommitPolicy("CommitSize:\n  MaxLines: -1\n"); !errors.Is(err, ErrCommitSizeConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrCommitSizeConfig)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// spellCheckT flags the likely typos of the subjects, past their tags and component: the
+// words of a built-in list of common misspellings, completed and amended by the
+// Dictionary file of the repository. Each of its lines is either a word to accept, such
+// as the name of a project, or a misspelling and its correction as in codespell,
+// "recieve->receive".
+type spellCheckT struct {
+	Verify     bool   `yaml:"Verify"`
+	Dictionary string `yaml:"Dictionary"`
+	Severity   string `yaml:"Severity"`
+	Shadow     bool   `yaml:"Shadow"`
+
+	accepted    map[string]bool   // words of the Dictionary
+	corrections map[string]string // misspellings of the Dictionary
+}
+
+// misspellings are the common misspellings of the words of subjects, with their
+// correction.
+var misspellings = map[string]string{
+	"accomodate": "accommodate", "acheive": "achieve", "adress": "address", "agressive": "aggressive",
+	"allready": "already", "alot": "a lot", "arguement": "argument",
+	"asynchronus": "asynchronous", "automaticly": "automatically", "begining": "beginning",
+	"beween": "between", "buffred": "buffered", "calback": "callback", "comparision": "comparison",
+	"compatability": "compatibility", "compatiblity": "compatibility", "completly": "completely",
+	"conection": "connection", "configuraton": "configuration", "consistant": "consistent",
+	"correclty": "correctly", "currenly": "currently", "defintion": "definition", "dependancy": "dependency",
+	"dependant": "dependent", "deprected": "deprecated", "desciption": "description", "existant": "existent",
+	"explicitely": "explicitly", "functionnality": "functionality", "garantee": "guarantee",
+	"handeling": "handling", "immediatly": "immediately", "implemention": "implementation",
+	"incorect": "incorrect", "independant": "independent", "initalize": "initialize", "lenght": "length",
+	"mesage": "message", "neccessary": "necessary", "occured": "occurred", "occurence": "occurrence",
+	"paramter": "parameter", "perfomance": "performance", "posible": "possible",
+	"prefered": "preferred", "proccess": "process", "propery": "property", "reciever": "receiver",
+	"recieve": "receive", "recieved": "received", "recursivly": "recursively", "refered": "referred",
+	"retreive": "retrieve", "seperate": "separate", "seperator": "separator", "sucess": "success",
+	"succesful": "successful", "successfull": "successful", "supress": "suppress", "teh": "the",
+	"threshhold": "threshold", "transfered": "transferred", "truely": "truly", "unecessary": "unnecessary",
+	"untill": "until", "usefull": "useful", "wether": "whether", "wich": "which", "writting": "writing",
+}
+
+var ErrSpellCheckConfig = errors.New("invalid spell check rule")
+
+func (s spellCheckT) validate() error {
+	if s.Dictionary != "" && !inRepository(s.Dictionary) {
+		return fmt.Errorf("spell check rule: '%s' is not in the repository: %w", s.Dictionary, ErrSpellCheckConfig)
+	}
+
+	if !validSeverity(s.Severity) {
+		return fmt.Errorf("spell check rule: unknown severity '%s': %w", s.Severity, ErrSpellCheckConfig)
+	}
+
+	return nil
+}
+
+// severity defaults to warning, a word missing from the dictionaries being no proof of a
+// typo.
+func (s spellCheckT) severity() string {
+	if s.Severity == "" {
+		return severityWarning
+	}
+
+	return s.Severity
+}
+
+// parseDictionary reads the words and misspellings of a dictionary file, ignoring its
+// blank lines and # comments.
+func (s spellCheckT) parseDictionary(content string) (spellCheckT, error) {
+	s.accepted, s.corrections = map[string]bool{}, map[string]string{}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.Contains(line, "->"):
+			parts := strings.SplitN(line, "->", 2)
+			typo, correction := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+
+			if typo == "" || correction == "" || strings.IndexFunc(typo, unicode.IsSpace) >= 0 {
+				return s, fmt.Errorf("%s:%d: invalid misspelling '%s': %w", s.Dictionary, i+1, line, ErrSpellCheckConfig)
+			}
+
+			s.corrections[typo] = correction
+		case strings.IndexFunc(line, unicode.IsSpace) >= 0:
+			return s, fmt.Errorf("%s:%d: '%s' is not a word: %w", s.Dictionary, i+1, line, ErrSpellCheckConfig)
+		default:
+			s.accepted[strings.ToLower(line)] = true
+		}
+	}
+
+	return s, nil
+}
+
+// resolveDictionary returns the policy with the Dictionary of the spell check read from
+// the tree of rev.
+func (c CommitPolicyConfig) resolveDictionary(repoPath, rev string) (CommitPolicyConfig, error) {
+	if !c.SpellCheck.Verify || c.SpellCheck.Dictionary == "" {
+		return c, nil
+	}
+
+	content, err := runGit(repoPath, "show", rev+":"+path.Clean(c.SpellCheck.Dictionary))
+	if err != nil {
+		return c, fmt.Errorf("error reading %s: %s: %w", c.SpellCheck.Dictionary, err, ErrSpellCheckConfig)
+	}
+
+	c.SpellCheck, err = c.SpellCheck.parseDictionary(content)
+
+	return c, err
+}
+
+// isIdentifier tells whether the word looks like code rather than prose: made of other
+// characters than letters, such as tune.bufsize or h2_send(), or cased otherwise than by
+// its first letter, such as HTTP or setTimeout.
+func isIdentifier(word string) bool {
+	for i, r := range []rune(word) {
+		if !unicode.IsLetter(r) && r != '\'' || i > 0 && unicode.IsUpper(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// correction returns the correction of the word, if it is a known misspelling.
+func (s spellCheckT) correction(word string) (string, bool) {
+	word = strings.ToLower(word)
+	if s.accepted[word] {
+		return "", false
+	}
+
+	if correction, ok := s.corrections[word]; ok {
+		return correction, true
+	}
+
+	correction, ok := misspellings[word]
+
+	return correction, ok
+}
+
+var ErrSpelling = errors.New("possible misspelling in subject")
+
+// Check checks the words of the text of a subject, past its tags and component.
+func (s spellCheckT) Check(text string) error {
+	if !s.Verify {
+		return nil
+	}
+
+	if component, ok := subjectComponent(text); ok {
+		text = strings.TrimPrefix(text, component+": ")
+	}
+
+	typos := []string{}
+
+	for _, field := range strings.Fields(text) {
+		if strings.ContainsAny(field, "`\"") {
+			continue // quoted code or messages
+		}
+
+		word := strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) })
+		if word == "" || isIdentifier(word) {
+			continue
+		}
+
+		if correction, ok := s.correction(word); ok {
+			typos = append(typos, fmt.Sprintf("'%s' (%s?)", word, correction))
+		}
+	}
+
+	if len(typos) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("subject may contain typos: %s: %w", strings.Join(typos, ", "), ErrSpelling)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpellCheck(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t)
+
+	dictionary := "# words of the project\nhaproxy\nrecieve\nbuffr->buffer\n"
+	if err := ioutil.WriteFile(filepath.Join(repo, "dictionary.txt"), []byte(dictionary), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "MINOR: spelling: add the dictionary"}} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := parseCommitPolicy("SpellCheck:\n  Verify: true\n  Dictionary: dictionary.txt\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := c.SpellCheck
+
+	if c, err = c.resolveDictionary(repo, "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    spellCheckT
+		text    string
+		wantErr string
+	}{
+		{"correct", plain, "mux: fix a crash on close", ""},
+		{"typos", plain, "mux: Seperate the reciever from teh sender", "subject may contain typos: " +
+			"'Seperate' (separate?), 'reciever' (receiver?), 'teh' (the?)"},
+		{"component", plain, "teh: fix a crash", ""},
+		{"identifiers", plain, "h2: rename the h2_recieve() and sucessCount functions", ""},
+		{"quoted", plain, "cli: fix the \"wich\" message", ""},
+		{"accepted", c.SpellCheck, "mux: recieve in the h2 mux", ""},
+		{"added", c.SpellCheck, "mux: fix the size of the buffr", "subject may contain typos: 'buffr' (buffer?)"},
+		{"off", spellCheckT{}, "mux: Seperate", ""},
+	}
+
+	for _, tt := range tests {
+		err := tt.rule.Check(tt.text)
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrSpelling) || err.Error() != tt.wantErr+": "+ErrSpelling.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := (spellCheckT{}).parseDictionary("two words\n"); !errors.Is(err, ErrSpellCheckConfig) {
+		t.Errorf("parseDictionary() error = %v, want %v", err, ErrSpellCheckConfig)
+	}
+
+	c.SpellCheck.Dictionary = "missing.txt"
+	if _, err := c.resolveDictionary(repo, "HEAD"); !errors.Is(err, ErrSpellCheckConfig) {
+		t.Errorf("resolveDictionary() of a missing file error = %v, want %v", err, ErrSpellCheckConfig)
+	}
+
+	if _, err := parseCommitPolicy("SpellCheck:\n  Dictionary: ../words.txt\n"); !errors.Is(err, ErrSpellCheckConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrSpellCheckConfig)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+)
+
+type ruleStatsT struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
+func (r ruleStatsT) hits() int {
+	return r.Errors + r.Warnings
+}
+
+// statsT counts how often each rule fired, in one or, cumulated, several runs.
+type statsT struct {
+	Runs    int                   `json:"runs"`
+	Commits int                   `json:"commits"`
+	Rules   map[string]ruleStatsT `json:"rules"`
+}
+
+func (r reportT) stats() statsT {
+	stats := statsT{Runs: 1, Commits: len(r.Commits), Rules: map[string]ruleStatsT{}}
+
+	for _, finding := range r.Findings {
+		name := finding.Rule
+		if finding.Shadow {
+			name += " (shadow)"
+		}
+
+		rule := stats.Rules[name]
+
+		if finding.Severity == severityError {
+			rule.Errors++
+		} else {
+			rule.Warnings++
+		}
+
+		stats.Rules[name] = rule
+	}
+
+	return stats
+}
+
+func (s *statsT) add(other statsT) {
+	if s.Rules == nil {
+		s.Rules = map[string]ruleStatsT{}
+	}
+
+	s.Runs += other.Runs
+	s.Commits += other.Commits
+
+	for name, rule := range other.Rules {
+		total := s.Rules[name]
+		total.Errors += rule.Errors
+		total.Warnings += rule.Warnings
+		s.Rules[name] = total
+	}
+}
+
+// lines describes the rules from the noisiest to the quietest one.
+func (s statsT) lines() []string {
+	names := make([]string, 0, len(s.Rules))
+	for name := range s.Rules {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if hi, hj := s.Rules[names[i]].hits(), s.Rules[names[j]].hits(); hi != hj {
+			return hi > hj
+		}
+
+		return names[i] < names[j]
+	})
+
+	lines := []string{}
+
+	for _, name := range names {
+		rule := s.Rules[name]
+		line := fmt.Sprintf("%s: %d error(s), %d warning(s)", name, rule.Errors, rule.Warnings)
+
+		if s.Commits > 0 {
+			const percent = 100
+			line += fmt.Sprintf(", %.1f per 100 commits", float64(rule.hits()*percent)/float64(s.Commits))
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// updateStatsFile adds the stats of the run to the cumulative ones of the file.
+func updateStatsFile(filename string, stats statsT) (statsT, error) {
+	total := statsT{}
+
+	data, err := ioutil.ReadFile(filename)
+	if err == nil {
+		if err := json.Unmarshal(data, &total); err != nil {
+			return total, fmt.Errorf("error decoding stats %s: %w", filename, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return total, fmt.Errorf("error reading stats: %w", err)
+	}
+
+	total.add(stats)
+
+	if data, err = json.MarshalIndent(total, "", "  "); err != nil {
+		return total, fmt.Errorf("error encoding stats: %w", err)
+	}
+
+	const statsFileMode = 0o644
+	if err := ioutil.WriteFile(filename, append(data, '\n'), statsFileMode); err != nil {
+		return total, fmt.Errorf("error writing stats: %w", err)
+	}
+
+	return total, nil
+}
+
+func logStats(title string, stats statsT) {
+	if len(stats.Rules) == 0 {
+		return
+	}
+
+	log.Printf("%s (%d run(s), %d commit(s)):", title, stats.Runs, stats.Commits)
+
+	for _, line := range stats.lines() {
+		log.Printf("  %s", line)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	report := reportT{
+		Commits: make([]commitT, 4),
+		Findings: []findingT{
+			{Rule: ruleTag, Severity: severityError},
+			{Rule: ruleTag, Severity: severityError},
+			{Rule: ruleReorgPurity, Severity: severityWarning},
+			{Rule: ruleLanguage, Severity: severityError},
+			{Rule: ruleLanguage, Severity: severityWarning},
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "stats.json")
+
+	for i := 0; i < 2; i++ {
+		if _, err := updateStatsFile(filename, report.stats()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	total, err := updateStatsFile(filename, statsT{Runs: 1, Commits: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total.Runs != 3 || total.Commits != 10 || total.Rules[ruleTag].Errors != 4 {
+		t.Errorf("updateStatsFile() = %+v", total)
+	}
+
+	want := []string{
+		"language: 2 error(s), 2 warning(s), 40.0 per 100 commits",
+		"tag: 4 error(s), 0 warning(s), 40.0 per 100 commits",
+		"reorg-purity: 0 error(s), 2 warning(s), 20.0 per 100 commits",
+	}
+
+	if got := total.lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines() = %v, want %v", got, want)
+	}
+}
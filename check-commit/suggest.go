@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"strings"
+)
+
+// knownValues returns all the tags and severities of the configuration.
+func (c CommitPolicyConfig) knownValues() map[string]bool {
+	values := map[string]bool{}
+
+	for _, patchType := range c.PatchTypes {
+		for _, value := range patchType.Values {
+			values[value] = true
+		}
+	}
+
+	for _, scope := range c.PatchScopes {
+		for _, value := range scope {
+			values[value] = true
+		}
+	}
+
+	return values
+}
+
+// fixTagCase rewrites a leading "bug/medium : " style prefix to "BUG/MEDIUM: " when
+// its parts are known tags or severities, the prefixes of a TagFormat being left as
+// they are.
+func (c CommitPolicyConfig) fixTagCase(subject string) string {
+	if c.TagFormat != "" {
+		return subject
+	}
+
+	r := regexp.MustCompile(`^([A-Za-z]+)(?:\s*/\s*([A-Za-z]+))?\s*:\s*`)
+
+	m := r.FindStringSubmatchIndex(subject)
+	if m == nil {
+		return subject
+	}
+
+	known := c.knownValues()
+	prefix := strings.ToUpper(subject[m[2]:m[3]])
+
+	if !known[prefix] {
+		return subject
+	}
+
+	if m[4] >= 0 {
+		severity := strings.ToUpper(subject[m[4]:m[5]])
+		if !known[severity] {
+			return subject
+		}
+
+		prefix += "/" + severity
+	}
+
+	return prefix + ": " + subject[m[1]:]
+}
+
+// hasMechanicalMistake tells whether the subject fails one of the rules whose mistakes
+// suggestSubject fixes.
+func (c CommitPolicyConfig) hasMechanicalMistake(subject string) bool {
+	return c.CheckSubject([]byte(subject)) != nil || checkRevertOfRevert(subject) != nil ||
+		c.Whitespace.Check(subject) != nil || c.TrailingPunctuation.Check(subject) != nil ||
+		c.Capitalization.Check(c.stripTagPrefixes(subject)) != nil
+}
+
+// suggestSubject proposes a compliant rewording of a failing subject by fixing the
+// mechanical mistakes: encoding, spacing, case and aliases of the tags, trailing
+// punctuation, case of the first word and nested reverts. It returns false when the
+// subject is fine or cannot be fixed automatically.
+func (c CommitPolicyConfig) suggestSubject(subject string) (string, bool) {
+	if !c.hasMechanicalMistake(subject) {
+		return "", false
+	}
+
+	suggestion, _ := normalizeSubject(subject)
+	suggestion = strings.Join(strings.Fields(suggestion), " ")
+	suggestion = c.fixTagCase(suggestion)
+	suggestion, _ = c.canonicalSubject(suggestion)
+
+	if original, ok := originalOfRevertChain(suggestion); ok {
+		suggestion = original
+	}
+
+	suggestion = c.TrailingPunctuation.trim(suggestion)
+	if text := c.stripTagPrefixes(suggestion); strings.HasSuffix(suggestion, text) {
+		suggestion = suggestion[:len(suggestion)-len(text)] + c.Capitalization.recase(text)
+	}
+
+	if suggestion == subject || c.hasMechanicalMistake(suggestion) {
+		return "", false
+	}
+
+	return suggestion, true
+}
+
+// logSuggestions prints the suggested subjects of the commits failing the check, ready
+// to be copy-pasted.
+func (c CommitPolicyConfig) logSuggestions(report reportT) {
+	failing := map[string]bool{}
+
+	for _, finding := range report.Findings {
+		if finding.Severity == severityError && !finding.Shadow {
+			failing[finding.SHA] = true
+		}
+	}
+
+	for _, reword := range c.rewords(report.Commits) {
+		if failing[reword.SHA] {
+			log.Printf("suggested subject for commit %s: %s", shortSHA(reword.SHA), reword.Suggestion)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestSuggestSubject(t *testing.T) {
+	t.Parallel()
+
+	c, err := LoadCommitPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject string
+		want    string
+		wantOk  bool
+	}{
+		{subject: "BUG/MEDIUM: config: fix set-var parsing", wantOk: false},
+		{subject: "bug/medium: config: fix set-var parsing", want: "BUG/MEDIUM: config: fix set-var parsing", wantOk: true},
+		{subject: "Minor : config: add set-var keyword", want: "MINOR: config: add set-var keyword", wantOk: true},
+		{subject: "\ufeffbug/medium: config: fix set-var parsing", want: "BUG/MEDIUM: config: fix set-var parsing", wantOk: true},
+		{
+			subject: `Revert "Revert "MINOR: config: add set-var keyword""`,
+			want:    "MINOR: config: add set-var keyword",
+			wantOk:  true,
+		},
+		{subject: "fix: config: fix set-var parsing", wantOk: false},
+		{subject: "bug/moderate: config: fix set-var parsing", wantOk: false},
+		{subject: "BUG/MEDIUM: fix", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := c.suggestSubject(tt.subject)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("suggestSubject(%s) = '%s', %v, want '%s', %v", tt.subject, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestSuggestSubjectStyle(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(defaultConf + "TrailingPunctuation:\n  Characters: '.!'\nCapitalization:\n" +
+		"  Mode: require-lower\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"BUG/MEDIUM: config: fix set-var parsing.", "BUG/MEDIUM: config: fix set-var parsing"},
+		{"BUG/MEDIUM: config: Fix set-var parsing", "BUG/MEDIUM: config: fix set-var parsing"},
+		{"BUG/MEDIUM: Fix set-var parsing", "BUG/MEDIUM: fix set-var parsing"},
+		{"bug/medium: config:  Fix set-var parsing...", "BUG/MEDIUM: config: fix set-var parsing"},
+		{"BUG/MEDIUM: config: fix HTTP parsing", ""},
+	}
+
+	for _, tt := range tests {
+		if got, ok := c.suggestSubject(tt.subject); got != tt.want || ok != (tt.want != "") {
+			t.Errorf("suggestSubject(%s) = '%s', %v, want '%s'", tt.subject, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+const summaryMaxItems = 10
+
+// summaryDescriptions say what the commits listed under a rule have in common.
+var summaryDescriptions = map[string]string{
+	ruleTag:               "with an invalid or missing tag",
+	ruleSubjectFormat:     "with a subject of the wrong length or word count",
+	ruleLanguage:          "not written in English",
+	ruleRevertOfRevert:    "reverting a revert",
+	ruleReorgPurity:       "changing behavior in a reorganization",
+	ruleCleanupNeutrality: "changing behavior in a cleanup",
+	ruleDocumentation:     "with a documentation tag changing other files",
+	ruleEncoding:          "with a badly encoded message",
+	ruleCommitSize:        "too large",
+	ruleSensitivePaths:    "touching sensitive paths without the required tag or trailer",
+	rulePathRules:         "without the tag of the paths they change",
+	ruleSignatures:        "without a verified signature",
+	ruleTagConstraints:    "combining tags in a forbidden way",
+	ruleComponent:         "without a known component",
+	ruleForbiddenWords:    "marked as unfinished work",
+	ruleDeprecatedTag:     "with a deprecated tag",
+	ruleBotFormat:         "of bots not in the format of the bot",
+	ruleImperativeMood:    "with a subject not in the imperative mood",
+	ruleTrailingPunct:     "with a subject ending with punctuation",
+	ruleCapitalization:    "with a subject starting with the wrong case",
+	ruleCharset:           "with forbidden characters in the subject",
+	ruleWhitespace:        "with a badly spaced subject",
+	ruleTicketReference:   "without a ticket reference",
+	ruleAutosquash:        "still to be squashed",
+	ruleRevert:            "reverting a commit they do not describe",
+	ruleSpelling:          "with likely typos in the subject",
+	ruleDuplicateSubject:  "repeating the subject of an earlier commit",
+	ruleSubjectURL:        "with a URL in the subject",
+	ruleVagueSubject:      "with a subject saying too little",
+	ruleForbiddenChars:    "with forbidden characters or markers in the subject",
+	ruleDenylist:          "with a denylisted word in the message",
+	ruleTrailingIssue:     "with a missing, misplaced or forbidden issue reference at the end of the subject",
+}
+
+type summaryGroupT struct {
+	rule     string
+	commits  []string // short SHAs
+	values   []string // distinct offending values, e.g. tags
+	messages []string // findings about the whole request
+}
+
+// abbreviateList joins the items, eliding those beyond summaryMaxItems.
+func abbreviateList(items []string) string {
+	if len(items) > summaryMaxItems {
+		return strings.Join(items[:summaryMaxItems], ", ") + fmt.Sprintf(" and %d more", len(items)-summaryMaxItems)
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// line describes the group in the words of the catalog.
+func (g summaryGroupT) line(catalog catalogT) string {
+	if len(g.commits) == 0 {
+		return fmt.Sprintf("%s: %s", g.rule, strings.Join(g.messages, "; "))
+	}
+
+	line := fmt.Sprintf("%s: %d %s %s", g.rule, len(g.commits), catalog.text(catalog.Commits, "commit(s)"),
+		catalog.description(g.rule))
+	if len(g.values) > 0 {
+		line += fmt.Sprintf(" (%d %s: %s)", len(g.values), catalog.text(catalog.Distinct, "distinct"),
+			abbreviateList(g.values))
+	}
+
+	return line + ": " + abbreviateList(g.commits)
+}
+
+// findingTags returns the tags of the commit of the finding, in the TagFormat of the
+// policy.
+func (r reportT) findingTags(finding findingT) []subjectTagT {
+	for _, commit := range r.Commits {
+		if commit.SHA == finding.SHA {
+			return commit.Tags()
+		}
+	}
+
+	return commitT{Message: finding.Subject, tagFormat: r.tagFormat}.Tags()
+}
+
+// failureSummary groups the errors of the report by rule, the rules hitting the most
+// commits first, which is quicker to act on than the per-commit log of a long series.
+func (r reportT) failureSummary() []string {
+	groups := map[string]*summaryGroupT{}
+
+	for _, finding := range r.Findings {
+		if finding.Severity != severityError || finding.Shadow {
+			continue
+		}
+
+		group, ok := groups[finding.Rule]
+		if !ok {
+			group = &summaryGroupT{rule: finding.Rule}
+			groups[finding.Rule] = group
+		}
+
+		if finding.SHA == "" {
+			group.messages = append(group.messages, finding.Message)
+
+			continue
+		}
+
+		sha := shortSHA(finding.SHA)
+		if !containsString(group.commits, sha) {
+			group.commits = append(group.commits, sha)
+		}
+
+		if finding.Rule == ruleTag {
+			value := "no tag"
+			if tags := r.findingTags(finding); len(tags) > 0 {
+				value = tags[0].String()
+			}
+
+			if !containsString(group.values, value) {
+				group.values = append(group.values, value)
+			}
+		}
+	}
+
+	sorted := make([]*summaryGroupT, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if ci, cj := len(sorted[i].commits), len(sorted[j].commits); ci != cj {
+			return ci > cj
+		}
+
+		return sorted[i].rule < sorted[j].rule
+	})
+
+	lines := make([]string, 0, len(sorted))
+	for _, group := range sorted {
+		lines = append(lines, group.line(r.catalog))
+	}
+
+	return lines
+}
+
+func logFailureSummary(report reportT) {
+	lines := report.failureSummary()
+	if len(lines) == 0 {
+		return
+	}
+
+	log.Printf("%s:", report.catalog.text(report.catalog.Summary, "summary of the errors by rule"))
+
+	for _, line := range lines {
+		log.Printf("  %s", line)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFailureSummary(t *testing.T) {
+	t.Parallel()
+
+	report := reportT{}
+	report.Add(findingT{Rule: ruleTag, Severity: severityError, SHA: "1111111111", Subject: "BUG/MUXQUIC: fix a crash"})
+	report.Add(findingT{Rule: ruleTag, Severity: severityError, SHA: "2222222222", Subject: "CFG: fix the parser"})
+	report.Add(findingT{Rule: ruleTag, Severity: severityError, SHA: "3333333333", Subject: "fix the parser again"})
+	report.Add(findingT{Rule: ruleTag, Severity: severityError, SHA: "4444444444", Subject: "CFG: fix the parser"})
+	report.Add(findingT{Rule: ruleSubjectFormat, Severity: severityError, SHA: "2222222222", Subject: "CFG: fix"})
+	report.Add(findingT{Rule: ruleMaxCommits, Severity: severityError, Message: "too many commits"})
+	report.Add(findingT{Rule: ruleCommitSize, Severity: severityWarning, SHA: "5555555555", Subject: "MINOR: add it"})
+	report.Add(findingT{Rule: ruleSignatures, Severity: severityError, SHA: "5555555555", Shadow: true})
+
+	want := []string{
+		"tag: 4 commit(s) with an invalid or missing tag (3 distinct: BUG/MUXQUIC, CFG, no tag): " +
+			"11111111, 22222222, 33333333, 44444444",
+		"subject-format: 1 commit(s) with a subject of the wrong length or word count: 22222222",
+		"max-commits: too many commits",
+	}
+
+	if got := report.failureSummary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("failureSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestFailureSummaryTagFormat(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCommitPolicy(conventionalFormatConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := c.newReport(nil)
+	report.Add(findingT{Rule: ruleTag, Severity: severityError, SHA: "1111111111", Subject: "chore(ci): bump"})
+
+	want := []string{"tag: 1 commit(s) with an invalid or missing tag (1 distinct: chore/ci): 11111111"}
+	if got := report.failureSummary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("failureSummary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 5 subgroups, 4. is "/severity", 5. is "severity"
+func tagPrefixRegexp() *regexp.Regexp {
+	return regexp.MustCompile(`^(?P<match>(?P<tag>[A-Z]+)(\/(?P<severity>[A-Z]+))?: )`)
+}
+
+var ErrTagFormat = errors.New("invalid tag format")
+
+// validateTagFormat checks that TagFormat matches a non-empty prefix of the subject and
+// names its tag.
+func (c CommitPolicyConfig) validateTagFormat() error {
+	if c.TagFormat == "" {
+		return nil
+	}
+
+	r, err := regexp.Compile(c.TagFormat)
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("TagFormat: %s: %w", err, ErrTagFormat)
+	case !strings.HasPrefix(c.TagFormat, "^"):
+		return fmt.Errorf("TagFormat '%s' must be anchored with ^ to the start of the subject: %w", c.TagFormat,
+			ErrTagFormat)
+	case r.SubexpIndex("tag") < 0:
+		return fmt.Errorf("TagFormat '%s' has no (?P<tag>...) group: %w", c.TagFormat, ErrTagFormat)
+	case r.MatchString(""):
+		return fmt.Errorf("TagFormat '%s' matches empty prefixes: %w", c.TagFormat, ErrTagFormat)
+	}
+
+	return nil
+}
+
+// tagRegexp returns the regexp matching a tag prefix, TagFormat or the TAG/SEVERITY
+// format by default.
+func (c CommitPolicyConfig) tagRegexp() *regexp.Regexp {
+	if c.TagFormat == "" {
+		return tagPrefixRegexp()
+	}
+
+	return regexp.MustCompile(c.TagFormat) // validated when loading
+}
+
+// eachTagPrefix calls fn with every leading prefix of the subject r matches and the
+// indexes of its submatches, and returns the rest of the subject.
+func eachTagPrefix(r *regexp.Regexp, subject string, fn func(prefix string, m []int)) string {
+	for {
+		m := r.FindStringSubmatchIndex(subject)
+		if m == nil || m[1] == 0 {
+			return subject
+		}
+
+		fn(subject[:m[1]], m)
+		subject = subject[m[1]:]
+	}
+}
+
+// submatchIndex returns the bounds of the named group of the match, or -1, -1 when the
+// regexp has no such group or it did not match.
+func submatchIndex(r *regexp.Regexp, m []int, name string) (int, int) {
+	i := r.SubexpIndex(name)
+	if i < 0 {
+		return -1, -1
+	}
+
+	return m[2*i], m[2*i+1]
+}
+
+func submatchString(r *regexp.Regexp, s string, m []int, name string) string {
+	start, end := submatchIndex(r, m, name)
+	if start < 0 {
+		return ""
+	}
+
+	return s[start:end]
+}
+
+// matchTags returns the tags of the leading prefixes of the subject r matches.
+func matchTags(r *regexp.Regexp, subject string) []subjectTagT {
+	tags := []subjectTagT{}
+
+	eachTagPrefix(r, subject, func(prefix string, m []int) {
+		tags = append(tags, subjectTagT{
+			Tag:      submatchString(r, prefix, m, "tag"),
+			Severity: submatchString(r, prefix, m, "severity"),
+		})
+	})
+
+	return tags
+}
+
+// hasBreakingMarker tells whether one of the leading prefixes of the subject r matches
+// carries the breaking change marker its breaking group captures, as in "feat!: ".
+func hasBreakingMarker(r *regexp.Regexp, subject string) bool {
+	breaking := false
+
+	eachTagPrefix(r, subject, func(prefix string, m []int) {
+		breaking = breaking || submatchString(r, prefix, m, "breaking") != ""
+	})
+
+	return breaking
+}
+
+// misplacedBreakingRegexp matches the tag prefixes whose breaking change marker is not
+// right before the colon, "feat!(parser): " or "feat !: ".
+var misplacedBreakingRegexp = regexp.MustCompile(`^[^\s:!()]+(!\(|(\([^)]*\))?( !|!!))`)
+
+var ErrBreakingMarker = errors.New("misplaced breaking change marker")
+
+// checkBreakingMarker rejects the subjects whose breaking change marker is out of place,
+// when the TagFormat of the policy has a breaking group.
+func (c CommitPolicyConfig) checkBreakingMarker(subject string) error {
+	if c.tagRegexp().SubexpIndex("breaking") < 0 || !misplacedBreakingRegexp.MatchString(subject) {
+		return nil
+	}
+
+	return fmt.Errorf("the marker '!' must come right before the colon: 'type!: ' or 'type(scope)!: ': %w",
+		ErrBreakingMarker)
+}
+
+// stripTags returns the subject without the leading prefixes r matches.
+func stripTags(r *regexp.Regexp, subject string) string {
+	return eachTagPrefix(r, subject, func(string, []int) {})
+}
+
+// stripTagPrefixes returns the subject without its leading tags, in the TagFormat of the
+// policy.
+func (c CommitPolicyConfig) stripTagPrefixes(subject string) string {
+	return stripTags(c.tagRegexp(), subject)
+}
+
+type subjectTagT struct {
+	Tag      string
+	Severity string
+}
+
+func (t subjectTagT) String() string {
+	if t.Severity == "" {
+		return t.Tag
+	}
+
+	return t.Tag + "/" + t.Severity
+}
+
+// subjectTags returns every tag prefix of the subject in the TagFormat of the policy, in
+// order, regardless of whether the configuration allows them.
+func (c CommitPolicyConfig) subjectTags(subject string) []subjectTagT {
+	return matchTags(c.tagRegexp(), subject)
+}
+
+// hasAnyValue reports whether one of the tags or severities is in values.
+func hasAnyValue(tags []subjectTagT, values []string) bool {
+	for _, tag := range tags {
+		for _, value := range values {
+			if tag.Tag == value || (tag.Severity != "" && tag.Severity == value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSubjectTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		subject string
+		want    []subjectTagT
+	}{
+		{subject: "config: no tag at all", want: []subjectTagT{}},
+		{subject: "MINOR: config: add option", want: []subjectTagT{{Tag: "MINOR"}}},
+		{
+			subject: "BUG/MEDIUM: BUILD: makefile: fix flags",
+			want:    []subjectTagT{{Tag: "BUG", Severity: "MEDIUM"}, {Tag: "BUILD"}},
+		},
+	}
+
+	var c CommitPolicyConfig
+
+	for _, tt := range tests {
+		if got := c.subjectTags(tt.subject); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("subjectTags(%q) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+const bracketedConf = `
+TagFormat: '^\[(?P<tag>[A-Z]+)(/(?P<severity>[A-Z]+))?\] '
+PatchScopes:
+  severities:
+    - MAJOR
+    - MINOR
+PatchTypes:
+  bracketed:
+    Values:
+      - BUG
+      - DOC
+    Scope: severities
+TagOrder:
+  - PatchTypes:
+      - bracketed
+Aliases:
+  FIX: BUG
+`
+
+const conventionalFormatConf = `
+TagFormat: '^(?P<tag>[a-z]+)(\((?P<severity>[a-z0-9-]+)\))?!?: '
+PatchScopes:
+  components:
+    - parser
+    - cli
+PatchTypes:
+  conventional:
+    Values:
+      - feat
+      - fix
+    Scope: components
+TagOrder:
+  - PatchTypes:
+      - conventional
+`
+
+func TestTagFormat(t *testing.T) {
+	t.Parallel()
+
+	bracketed, err := parseCommitPolicy(bracketedConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conventional, err := parseCommitPolicy(conventionalFormatConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		c       CommitPolicyConfig
+		subject string
+		tags    []subjectTagT
+		wantErr bool
+	}{
+		{bracketed, "[BUG/MINOR] fix the parsing of timeouts", []subjectTagT{{Tag: "BUG", Severity: "MINOR"}}, false},
+		{bracketed, "[DOC] describe the timeouts in minutes", []subjectTagT{{Tag: "DOC"}}, false},
+		{bracketed, "[BUG/MINOR] [DOC] describe the timeouts", []subjectTagT{{"BUG", "MINOR"}, {Tag: "DOC"}}, true},
+		{bracketed, "BUG/MINOR: fix the parsing of timeouts", []subjectTagT{}, true},
+		{conventional, "feat(parser): accept the timeouts in minutes", []subjectTagT{{"feat", "parser"}}, false},
+		{conventional, "fix!: reject the negative timeouts", []subjectTagT{{Tag: "fix"}}, false},
+		{conventional, "feat(gui): accept the timeouts in minutes", []subjectTagT{{"feat", "gui"}}, true},
+		{conventional, "docs: describe the timeouts in minutes", []subjectTagT{{Tag: "docs"}}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.subjectTags(tt.subject); !reflect.DeepEqual(got, tt.tags) {
+			t.Errorf("subjectTags(%q) = %v, want %v", tt.subject, got, tt.tags)
+		}
+
+		if err := tt.c.CheckSubject([]byte(tt.subject)); (err != nil) != tt.wantErr {
+			t.Errorf("CheckSubject(%s) error = %v, wantErr %t", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	if got := conventional.stripTagPrefixes("feat(cli)!: add --quiet"); got != "add --quiet" {
+		t.Errorf("stripTagPrefixes() = %q, want %q", got, "add --quiet")
+	}
+
+	commits := []commitT{{SHA: "0123456789abcdef", Message: "[FIX/MINOR] fix the parsing of timeouts"}}
+	bracketed.resolveAliases(commits)
+
+	if want := "[BUG/MINOR] fix the parsing of timeouts"; commits[0].Subject() != want ||
+		!reflect.DeepEqual(commits[0].Tags(), []subjectTagT{{"BUG", "MINOR"}}) {
+		t.Errorf("resolveAliases() = %q, %v, want %q", commits[0].Subject(), commits[0].Tags(), want)
+	}
+}
+
+func TestValidateTagFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{`'^[(?P<tag>[A-Z]+)'`, `'\[(?P<tag>[A-Z]+)\] '`, `'^([A-Z]+): '`, `'^(?P<tag>[A-Z]*)'`} {
+		config := "TagFormat: " + format + "\n"
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrTagFormat) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrTagFormat)
+		}
+	}
+}
+
+func TestBreakingMarker(t *testing.T) {
+	t.Parallel()
+
+	conventional, err := parseCommitPolicy(conventionalCommitsConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	haproxy, _ := LoadCommitPolicy("")
+
+	tests := []struct {
+		c       CommitPolicyConfig
+		subject string
+		wantErr error
+	}{
+		{conventional, "feat(config)!: parse the timeouts in minutes", nil},
+		{conventional, "feat!(config): parse the timeouts in minutes", ErrBreakingMarker},
+		{conventional, "feat(config) !: parse the timeouts in minutes", ErrBreakingMarker},
+		{conventional, "fix!!: reject the negative timeouts", ErrBreakingMarker},
+		{haproxy, "MINOR!(config): parse the timeouts in minutes", nil},
+	}
+
+	for _, tt := range tests {
+		if err := tt.c.checkBreakingMarker(tt.subject); !errors.Is(err, tt.wantErr) {
+			t.Errorf("checkBreakingMarker(%s) error = %v, want %v", tt.subject, err, tt.wantErr)
+		}
+	}
+
+	if !hasBreakingMarker(conventional.tagRegexp(), "feat(parser)!: drop the legacy syntax") ||
+		hasBreakingMarker(haproxy.tagRegexp(), "MAJOR: parser: drop the legacy syntax") {
+		t.Error("hasBreakingMarker() did not tell the marked subjects")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ticketReferenceT requires the subjects, or with InBody the messages, of the commits
+// carrying one of Tags, of all commits when there are none, to reference a ticket
+// matching the Pattern, e.g. `JIRA-\d+` or `#\d+`.
+type ticketReferenceT struct {
+	Pattern  string   `yaml:"Pattern"`
+	Tags     []string `yaml:"Tags"`
+	InBody   bool     `yaml:"InBody"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrTicketReferenceConfig = errors.New("invalid ticket reference rule")
+
+func (t ticketReferenceT) validate() error {
+	if _, err := regexp.Compile(t.Pattern); err != nil {
+		return fmt.Errorf("ticket reference rule: %s: %w", err, ErrTicketReferenceConfig)
+	}
+
+	if t.Pattern == "" && (len(t.Tags) > 0 || t.InBody) {
+		return fmt.Errorf("ticket reference rule without Pattern: %w", ErrTicketReferenceConfig)
+	}
+
+	if !validSeverity(t.Severity) {
+		return fmt.Errorf("ticket reference rule: unknown severity '%s': %w", t.Severity, ErrTicketReferenceConfig)
+	}
+
+	return nil
+}
+
+func (t ticketReferenceT) severity() string {
+	if t.Severity == "" {
+		return severityError
+	}
+
+	return t.Severity
+}
+
+var ErrTicketReference = errors.New("missing ticket reference")
+
+// Check looks for a reference in the subject, and the body as well with InBody.
+func (t ticketReferenceT) Check(commit commitT) error {
+	if t.Pattern == "" || (len(t.Tags) > 0 && !hasAnyValue(commit.Tags(), t.Tags)) {
+		return nil
+	}
+
+	pattern := regexp.MustCompile(t.Pattern) // validated when loading
+
+	where := "subject"
+	if t.InBody {
+		where = "subject or body"
+	}
+
+	if pattern.MatchString(commit.Subject()) || (t.InBody && pattern.MatchString(commit.Body())) {
+		return nil
+	}
+
+	if len(t.Tags) > 0 {
+		return fmt.Errorf("commits tagged [%s] must reference a ticket matching '%s' in their %s: %w",
+			strings.Join(t.Tags, ", "), t.Pattern, where, ErrTicketReference)
+	}
+
+	return fmt.Errorf("commits must reference a ticket matching '%s' in their %s: %w", t.Pattern, where,
+		ErrTicketReference)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTicketReference(t *testing.T) {
+	t.Parallel()
+
+	bugs := ticketReferenceT{Pattern: `JIRA-\d+`, Tags: []string{"BUG"}}
+	body := ticketReferenceT{Pattern: `#\d+`, InBody: true}
+
+	tests := []struct {
+		name    string
+		rule    ticketReferenceT
+		message string
+		wantErr string
+	}{
+		{"off", ticketReferenceT{}, "BUG/MINOR: mux: fix a crash on close", ""},
+		{"in subject", bugs, "BUG/MINOR: mux: fix a crash on close (JIRA-12)", ""},
+		{
+			"missing", bugs, "BUG/MINOR: mux: fix a crash on close\n\nSee JIRA-12.",
+			"commits tagged [BUG] must reference a ticket matching 'JIRA-\\d+' in their subject",
+		},
+		{"other tag", bugs, "MINOR: mux: add a close callback", ""},
+		{"in body", body, "MINOR: mux: add a close callback\n\nFor #42.", ""},
+		{
+			"missing everywhere", body, "MINOR: mux: add a close callback",
+			"commits must reference a ticket matching '#\\d+' in their subject or body",
+		},
+	}
+
+	for _, tt := range tests {
+		err := tt.rule.Check(commitT{Message: tt.message})
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrTicketReference) ||
+			err.Error() != tt.wantErr+": "+ErrTicketReference.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	for _, config := range []string{"TicketReference:\n  Pattern: '(JIRA'\n", "TicketReference:\n  Tags: [BUG]\n"} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrTicketReferenceConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrTicketReferenceConfig)
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrTOML = errors.New("invalid TOML")
+
+var (
+	tomlIntegerRegexp = regexp.MustCompile(
+		`^([+-]?(0|[1-9](_?[0-9])*)|0x[0-9A-Fa-f](_?[0-9A-Fa-f])*|0o[0-7](_?[0-7])*|0b[01](_?[01])*)$`)
+	tomlFloatRegexp = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	tomlDateRegexp  = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2}|[0-9]{2}:[0-9]{2})`)
+)
+
+// tableStateT tells how a table was created: implicitly as the parent of another one,
+// which a header may still define, or by a header, dotted keys or an inline table.
+type tableStateT int
+
+const (
+	tableImplicit tableStateT = iota
+	tableDefined
+	tableInline
+)
+
+// tomlParserT parses the subset of TOML a policy needs: tables, arrays of tables,
+// dotted and quoted keys, strings, integers, floats, booleans, arrays and inline
+// tables. Anything else, such as dates and multi-line strings, is rejected.
+type tomlParserT struct {
+	input  string
+	pos    int
+	line   int
+	tables map[uintptr]tableStateT
+}
+
+func tableID(table map[string]interface{}) uintptr {
+	return reflect.ValueOf(table).Pointer()
+}
+
+func (p *tomlParserT) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s: %w", p.line, fmt.Sprintf(format, args...), ErrTOML)
+}
+
+func (p *tomlParserT) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+// skip skips blanks and comments, and newlines too when asked to.
+func (p *tomlParserT) skip(newlines bool) {
+	for p.pos < len(p.input) {
+		switch c := p.input[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endLine expects the end of the line after a key/value pair or a table header.
+func (p *tomlParserT) endLine() error {
+	p.skip(false)
+
+	switch p.peek() {
+	case 0:
+		return nil
+	case '\n':
+		p.pos++
+		p.line++
+
+		return nil
+	}
+
+	return p.errorf("unexpected '%c' after value", p.peek())
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// parseKey parses a possibly dotted key into its parts.
+func (p *tomlParserT) parseKey() ([]string, error) {
+	parts := []string{}
+
+	for {
+		p.skip(false)
+
+		var part string
+
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			s, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+
+			part = s
+		case isBareKeyChar(c):
+			start := p.pos
+			for p.pos < len(p.input) && isBareKeyChar(p.input[p.pos]) {
+				p.pos++
+			}
+
+			part = p.input[start:p.pos]
+		default:
+			return nil, p.errorf("key expected")
+		}
+
+		parts = append(parts, part)
+
+		p.skip(false)
+
+		if p.peek() != '.' {
+			return parts, nil
+		}
+
+		p.pos++
+	}
+}
+
+func (p *tomlParserT) parseString() (string, error) {
+	quote := p.input[p.pos]
+	p.pos++
+
+	if strings.HasPrefix(p.input[p.pos:], string([]byte{quote, quote})) {
+		return "", p.errorf("multi-line strings are not supported")
+	}
+
+	var b strings.Builder
+
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '\\' && quote == '"':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParserT) parseEscape(b *strings.Builder) error {
+	escapes := map[byte]string{'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", '"': "\"", '\\': "\\"}
+
+	c := p.peek()
+	p.pos++
+
+	if s, ok := escapes[c]; ok {
+		b.WriteString(s)
+
+		return nil
+	}
+
+	size := map[byte]int{'u': 4, 'U': 8}[c]
+	if size == 0 || p.pos+size > len(p.input) {
+		return p.errorf("invalid escape sequence '\\%c'", c)
+	}
+
+	code, err := strconv.ParseUint(p.input[p.pos:p.pos+size], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.errorf("invalid unicode escape '\\%c%s'", c, p.input[p.pos:p.pos+size])
+	}
+
+	b.WriteRune(rune(code))
+	p.pos += size
+
+	return nil
+}
+
+func (p *tomlParserT) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.input[p.pos])) {
+		p.pos++
+	}
+
+	word := p.input[start:p.pos]
+
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	switch number := strings.ReplaceAll(word, "_", ""); {
+	case tomlIntegerRegexp.MatchString(word):
+		// the syntax is checked by the regexp, base 0 only reads the prefixes
+		i, err := strconv.ParseInt(number, 0, 64)
+		if err != nil {
+			return nil, p.errorf("integer '%s' out of range", word)
+		}
+
+		return int(i), nil
+	case tomlFloatRegexp.MatchString(word):
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, p.errorf("float '%s' out of range", word)
+		}
+
+		return f, nil
+	case tomlDateRegexp.MatchString(word):
+		return nil, p.errorf("dates and times are not supported")
+	}
+
+	return nil, p.errorf("invalid value '%s'", word)
+}
+
+func (p *tomlParserT) parseArray() ([]interface{}, error) {
+	p.pos++ // [
+
+	values := []interface{}{}
+
+	for {
+		p.skip(true)
+
+		if p.peek() == ']' {
+			p.pos++
+
+			return values, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+
+		p.skip(true)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("',' or ']' expected in array")
+		}
+	}
+}
+
+func (p *tomlParserT) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++ // {
+
+	table := map[string]interface{}{}
+
+	for {
+		p.skip(false)
+
+		if p.peek() == '}' {
+			p.pos++
+			p.tables[tableID(table)] = tableInline
+
+			return table, nil
+		}
+
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skip(false)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("',' or '}' expected in inline table")
+		}
+	}
+}
+
+func (p *tomlParserT) parseKeyValue(table map[string]interface{}) error {
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	if p.peek() != '=' {
+		return p.errorf("'=' expected after key %s", strings.Join(key, "."))
+	}
+
+	p.pos++
+	p.skip(false)
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.descend(table, key[:len(key)-1], true)
+	if err != nil {
+		return err
+	}
+
+	name := key[len(key)-1]
+	if _, ok := parent[name]; ok {
+		return p.errorf("key %s defined twice", strings.Join(key, "."))
+	}
+
+	parent[name] = value
+
+	return nil
+}
+
+// descend returns the table at the path, creating the missing ones, defined when they
+// come from dotted keys; the path goes through the last table of arrays of tables.
+// Inline tables cannot be extended.
+func (p *tomlParserT) descend(table map[string]interface{}, path []string, define bool) (
+	map[string]interface{}, error) {
+	for _, name := range path {
+		switch child := table[name].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			table[name] = next
+			table = next
+
+			if define {
+				p.tables[tableID(next)] = tableDefined
+			}
+		case map[string]interface{}:
+			table = child
+		case []interface{}:
+			if len(child) == 0 {
+				return nil, p.errorf("%s is not a table", name)
+			}
+
+			last, ok := child[len(child)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("%s is not a table", name)
+			}
+
+			table = last
+		default:
+			return nil, p.errorf("%s is not a table", name)
+		}
+
+		if p.tables[tableID(table)] == tableInline {
+			return nil, p.errorf("inline table %s cannot be extended", name)
+		}
+	}
+
+	return table, nil
+}
+
+// isArrayOfTables tells whether the array was created by [[array of tables]] headers,
+// which static arrays cannot be extended with.
+func (p *tomlParserT) isArrayOfTables(values []interface{}) bool {
+	if len(values) == 0 {
+		return false
+	}
+
+	first, ok := values[0].(map[string]interface{})
+
+	return ok && p.tables[tableID(first)] == tableDefined
+}
+
+// parseTable parses a [table] or [[array of tables]] header and returns the table
+// the following keys belong to.
+func (p *tomlParserT) parseTable(root map[string]interface{}) (map[string]interface{}, error) {
+	array := strings.HasPrefix(p.input[p.pos:], "[[")
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+
+	if !strings.HasPrefix(p.input[p.pos:], closing) {
+		return nil, p.errorf("'%s' expected after table name %s", closing, strings.Join(key, "."))
+	}
+
+	p.pos += len(closing)
+
+	parent, err := p.descend(root, key[:len(key)-1], false)
+	if err != nil {
+		return nil, err
+	}
+
+	name := key[len(key)-1]
+	table := map[string]interface{}{}
+
+	if !array {
+		if existing, ok := parent[name].(map[string]interface{}); ok {
+			if p.tables[tableID(existing)] != tableImplicit {
+				return nil, p.errorf("table %s defined twice", strings.Join(key, "."))
+			}
+
+			table = existing
+		} else if parent[name] != nil {
+			return nil, p.errorf("%s is not a table", strings.Join(key, "."))
+		}
+
+		parent[name] = table
+		p.tables[tableID(table)] = tableDefined
+
+		return table, p.endLine()
+	}
+
+	p.tables[tableID(table)] = tableDefined
+
+	switch tables := parent[name].(type) {
+	case nil:
+		parent[name] = []interface{}{table}
+	case []interface{}:
+		if !p.isArrayOfTables(tables) {
+			return nil, p.errorf("%s is a static array", strings.Join(key, "."))
+		}
+
+		parent[name] = append(tables, table)
+	default:
+		return nil, p.errorf("%s is not an array of tables", strings.Join(key, "."))
+	}
+
+	return table, p.endLine()
+}
+
+// parseTOML parses a TOML document into nested maps.
+func parseTOML(input string) (map[string]interface{}, error) {
+	p := tomlParserT{input: input, line: 1, tables: map[uintptr]tableStateT{}}
+	root := map[string]interface{}{}
+	table := root
+
+	for {
+		p.skip(true)
+
+		if p.pos >= len(p.input) {
+			return root, nil
+		}
+
+		var err error
+
+		if p.peek() == '[' {
+			table, err = p.parseTable(root)
+		} else if err = p.parseKeyValue(table); err == nil {
+			err = p.endLine()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
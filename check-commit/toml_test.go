@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const defaultConfTOML = `# the built-in configuration, in TOML
+HelpText = "Please refer to https://github.com/haproxy/haproxy/blob/master/CONTRIBUTING#L632"
+
+[PatchScopes]
+"HAProxy Standard Scope" = ["MINOR", "MEDIUM", "MAJOR", "CRITICAL"]
+
+[PatchTypes."HAProxy Standard Patch"]
+Values = [
+  "BUG", "BUILD", "CLEANUP", "DOC", "LICENSE", "OPTIM", "RELEASE", "REORG", "TEST", "REVERT",
+]
+Scope = 'HAProxy Standard Scope'
+
+[PatchTypes."HAProxy Standard Feature Commit"]
+Values = ["MINOR", "MEDIUM", "MAJOR", "CRITICAL"]
+
+[[TagOrder]]
+PatchTypes = ["HAProxy Standard Patch", "HAProxy Standard Feature Commit"]
+`
+
+const defaultConfJSON = `{
+  "HelpText": "Please refer to https://github.com/haproxy/haproxy/blob/master/CONTRIBUTING#L632",
+  "PatchScopes": {"HAProxy Standard Scope": ["MINOR", "MEDIUM", "MAJOR", "CRITICAL"]},
+  "PatchTypes": {
+    "HAProxy Standard Patch": {
+      "Values": ["BUG", "BUILD", "CLEANUP", "DOC", "LICENSE", "OPTIM", "RELEASE", "REORG", "TEST", "REVERT"],
+      "Scope": "HAProxy Standard Scope"
+    },
+    "HAProxy Standard Feature Commit": {"Values": ["MINOR", "MEDIUM", "MAJOR", "CRITICAL"]}
+  },
+  "TagOrder": [{"PatchTypes": ["HAProxy Standard Patch", "HAProxy Standard Feature Commit"]}]
+}`
+
+func TestPolicyFormats(t *testing.T) {
+	t.Parallel()
+
+	want, err := parseCommitPolicy(defaultConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, config := range map[string]string{"yaml": defaultConf, "toml": defaultConfTOML, "json": defaultConfJSON} {
+		converted, err := policyToYAML(config)
+		if err != nil {
+			t.Errorf("policyToYAML(%s) error = %v", name, err)
+
+			continue
+		}
+
+		got, err := parseCommitPolicy(converted)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("parseCommitPolicy(%s) = %+v, %v, want %+v", name, got, err, want)
+		}
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseTOML("MaxCommits = 1_000 # limit\nRequireEnglish = true\nMask = 0x1F\nMode = 0o755\n" +
+		"Bits = 0b101\nRatio = -2.5e-1\n" +
+		"Commit.Size = { MaxLines = 400, ExemptTags = [\"REORG\"] }\n[[CustomRules]]\nName = \"a\\u00e9\"\n" +
+		"[[CustomRules]]\nName = 'b\\n'\n[Limits.Subject]\n[Limits]\nMinLines = +0\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"MaxCommits":     1000,
+		"RequireEnglish": true,
+		"Mask":           31,
+		"Mode":           493,
+		"Bits":           5,
+		"Ratio":          -0.25,
+		"Commit": map[string]interface{}{
+			"Size": map[string]interface{}{"MaxLines": 400, "ExemptTags": []interface{}{"REORG"}},
+		},
+		"Limits": map[string]interface{}{"Subject": map[string]interface{}{}, "MinLines": 0},
+		"CustomRules": []interface{}{
+			map[string]interface{}{"Name": "aé"},
+			map[string]interface{}{"Name": `b\n`},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTOML() = %#v, want %#v", got, want)
+	}
+
+	for _, input := range []string{
+		"MaxCommits = 1\nMaxCommits = 2\n",
+		"HelpText = \"unterminated\n",
+		"HelpText = \"\"\"multi-line\"\"\"\n",
+		"[PatchTypes\n",
+		"MaxCommits = 1 2\n",
+		"Values = [1, 2\n",
+		"MaxCommits = 1\n[MaxCommits]\n",
+		"MaxCommits = 010\n",
+		"MaxCommits = 0X10\n",
+		"MaxCommits = 1__0\n",
+		"MaxCommits = 99999999999999999999\n",
+		"Ratio = 1.\n",
+		"Ratio = Infinity\n",
+		"Ratio = 0x1p3\n",
+		"Since = 1979-05-27\n",
+		"Since = 07:32:00\n",
+		"[Commit]\nMaxLines = 1\n[Commit]\nMinLines = 1\n",
+		"Commit.Size.MaxLines = 1\n[Commit.Size]\n",
+		"Commit = { MaxLines = 1 }\nCommit.MinLines = 2\n",
+		"Commit = { MaxLines = 1 }\n[Commit.Size]\n",
+		"CustomRules = [{ Name = \"a\" }]\n[[CustomRules]]\n",
+		"CustomRules = []\n[[CustomRules]]\n",
+	} {
+		if _, err := parseTOML(input); !errors.Is(err, ErrTOML) {
+			t.Errorf("parseTOML(%q) error = %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// tracing is enabled when GitHub Actions step debug logging is, so that re-running a
+// failed job with debug logging gives the details support needs.
+var tracing = stepDebugEnabled(os.Getenv)
+
+var traceOutput io.Writer = os.Stdout
+
+func stepDebugEnabled(getenv func(string) string) bool {
+	return strings.EqualFold(getenv("ACTIONS_STEP_DEBUG"), "true") || getenv("RUNNER_DEBUG") == "1"
+}
+
+// escapeWorkflowData escapes a message for a workflow command, which ends at the
+// first newline.
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// tracef emits a ::debug:: line, shown in the log only when step debug logging is on.
+func tracef(format string, args ...interface{}) {
+	if !tracing {
+		return
+	}
+
+	fmt.Fprintf(traceOutput, "::debug::%s\n", escapeWorkflowData(fmt.Sprintf(format, args...)))
+}
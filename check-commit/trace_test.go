@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestStepDebugEnabled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"unset", map[string]string{}, false},
+		{"step debug", map[string]string{"ACTIONS_STEP_DEBUG": "true"}, true},
+		{"step debug disabled", map[string]string{"ACTIONS_STEP_DEBUG": "false"}, false},
+		{"runner debug", map[string]string{"RUNNER_DEBUG": "1"}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			getenv := func(name string) string { return tt.env[name] }
+			if got := stepDebugEnabled(getenv); got != tt.want {
+				t.Errorf("stepDebugEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeWorkflowData(t *testing.T) {
+	t.Parallel()
+
+	got := escapeWorkflowData("100% done\r\nnext line")
+	if want := "100%25 done%0D%0Anext line"; got != want {
+		t.Errorf("escapeWorkflowData() = %q, want %q", got, want)
+	}
+}
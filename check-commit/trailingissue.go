@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	trailingIssueRequire = "require"
+	trailingIssueForbid  = "forbid"
+
+	defaultTrailingIssueFormat = `\(#\d+\)`
+)
+
+// issueReferenceRegexp matches the GitHub style issue references of a subject, with the
+// parentheses around them, if any.
+var issueReferenceRegexp = regexp.MustCompile(`\(?#\d+\b\)?`)
+
+// trailingIssueT requires, or with Mode forbid rejects, the GitHub style reference to an
+// issue or pull request ending the subjects, such as the "(#1234)" of squash merges:
+// required, there must be exactly one, written as the Format, and it must be the last
+// word of the subject.
+type trailingIssueT struct {
+	Mode     string `yaml:"Mode"`
+	Format   string `yaml:"Format"`
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var ErrTrailingIssueConfig = errors.New("invalid trailing issue rule")
+
+func (t trailingIssueT) validate() error {
+	if t.Mode != "" && t.Mode != trailingIssueRequire && t.Mode != trailingIssueForbid {
+		return fmt.Errorf("trailing issue rule: unknown mode '%s', expected %s or %s: %w", t.Mode,
+			trailingIssueRequire, trailingIssueForbid, ErrTrailingIssueConfig)
+	}
+
+	if t.Format != "" && t.Mode != trailingIssueRequire {
+		return fmt.Errorf("trailing issue rule: Format requires the %s mode: %w", trailingIssueRequire,
+			ErrTrailingIssueConfig)
+	}
+
+	if _, err := regexp.Compile(t.Format); err != nil {
+		return fmt.Errorf("trailing issue rule: %s: %w", err, ErrTrailingIssueConfig)
+	}
+
+	if !validSeverity(t.Severity) {
+		return fmt.Errorf("trailing issue rule: unknown severity '%s': %w", t.Severity, ErrTrailingIssueConfig)
+	}
+
+	return nil
+}
+
+func (t trailingIssueT) severity() string {
+	if t.Severity == "" {
+		return severityError
+	}
+
+	return t.Severity
+}
+
+func (t trailingIssueT) format() string {
+	if t.Format == "" {
+		return defaultTrailingIssueFormat
+	}
+
+	return t.Format
+}
+
+var ErrTrailingIssue = errors.New("invalid trailing issue reference")
+
+// Check checks the issue references of the subject.
+func (t trailingIssueT) Check(subject string) error {
+	fields := strings.Fields(subject)
+	if t.Mode == "" || len(fields) == 0 {
+		return nil
+	}
+
+	last := fields[len(fields)-1]
+	refs := issueReferenceRegexp.FindAllString(subject, -1)
+
+	if t.Mode == trailingIssueForbid {
+		if issueReferenceRegexp.FindString(last) == last {
+			return fmt.Errorf("subject ends with the issue reference '%s', move it to the body: %w", last,
+				ErrTrailingIssue)
+		}
+
+		return nil
+	}
+
+	switch {
+	case len(refs) == 0:
+		return fmt.Errorf("subject must end with an issue reference matching '%s': %w", t.format(), ErrTrailingIssue)
+	case len(refs) > 1:
+		return fmt.Errorf("subject has %d issue references '%s', expected one at its end: %w", len(refs),
+			strings.Join(refs, "', '"), ErrTrailingIssue)
+	case refs[0] != last:
+		return fmt.Errorf("issue reference '%s' must be the last word of the subject: %w", refs[0], ErrTrailingIssue)
+	case !regexp.MustCompile(`^(?:` + t.format() + `)$`).MatchString(last): // validated when loading
+		return fmt.Errorf("issue reference '%s' does not match '%s': %w", last, t.format(), ErrTrailingIssue)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTrailingIssue(t *testing.T) {
+	t.Parallel()
+
+	require := trailingIssueT{Mode: trailingIssueRequire}
+	forbid := trailingIssueT{Mode: trailingIssueForbid}
+	bare := trailingIssueT{Mode: trailingIssueRequire, Format: `#\d+`}
+
+	tests := []struct {
+		name    string
+		rule    trailingIssueT
+		subject string
+		wantErr string
+	}{
+		{"required", require, "BUG/MINOR: mux: fix a crash on close (#1234)", ""},
+		{"missing", require, "BUG/MINOR: mux: fix a crash on close", "subject must end with an issue reference " +
+			"matching '\\(#\\d+\\)'"},
+		{"several", require, "BUG/MINOR: mux: fix #12 and a crash (#1234)", "subject has 2 issue references " +
+			"'#12', '(#1234)', expected one at its end"},
+		{"not last", require, "BUG/MINOR: mux: fix (#1234) a crash on close", "issue reference '(#1234)' must be " +
+			"the last word of the subject"},
+		{"format", require, "BUG/MINOR: mux: fix a crash on close #1234", "issue reference '#1234' does not match " +
+			"'\\(#\\d+\\)'"},
+		{"custom format", bare, "BUG/MINOR: mux: fix a crash on close #1234", ""},
+		{"forbidden", forbid, "BUG/MINOR: mux: fix a crash on close (#1234)", "subject ends with the issue " +
+			"reference '(#1234)', move it to the body"},
+		{"forbidden elsewhere", forbid, "BUG/MINOR: mux: fix the #1234 crash on close", ""},
+		{"off", trailingIssueT{}, "BUG/MINOR: mux: fix a crash on close", ""},
+	}
+
+	for _, tt := range tests {
+		err := tt.rule.Check(tt.subject)
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrTrailingIssue) ||
+			err.Error() != tt.wantErr+": "+ErrTrailingIssue.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	for _, config := range []string{
+		"TrailingIssue:\n  Mode: always\n",
+		"TrailingIssue:\n  Mode: forbid\n  Format: '#\\d+'\n",
+		"TrailingIssue:\n  Mode: require\n  Format: '('\n",
+	} {
+		if _, err := parseCommitPolicy(config); !errors.Is(err, ErrTrailingIssueConfig) {
+			t.Errorf("parseCommitPolicy(%q) error = %v, want %v", config, err, ErrTrailingIssueConfig)
+		}
+	}
+}
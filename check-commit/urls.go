@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// subjectURLsT rejects the links in subjects, which blow the length limits and break
+// the rendering of changelogs, when Verify is set.
+type subjectURLsT struct {
+	Verify   bool   `yaml:"Verify"`
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var urlRegexp = regexp.MustCompile(`(?i)\bhttps?://\S+`)
+
+var ErrSubjectURLsConfig = errors.New("invalid subject URLs rule")
+
+func (u subjectURLsT) validate() error {
+	if !validSeverity(u.Severity) {
+		return fmt.Errorf("subject URLs rule: unknown severity '%s': %w", u.Severity, ErrSubjectURLsConfig)
+	}
+
+	return nil
+}
+
+func (u subjectURLsT) severity() string {
+	if u.Severity == "" {
+		return severityError
+	}
+
+	return u.Severity
+}
+
+var ErrSubjectURL = errors.New("URL in subject")
+
+func (u subjectURLsT) Check(subject string) error {
+	if !u.Verify {
+		return nil
+	}
+
+	url := urlRegexp.FindString(subject)
+	if url == "" {
+		return nil
+	}
+
+	return fmt.Errorf("subject contains the URL '%s', move it to the body or a trailer: %w", url, ErrSubjectURL)
+}
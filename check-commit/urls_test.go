@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSubjectURLs(t *testing.T) {
+	t.Parallel()
+
+	verify := subjectURLsT{Verify: true}
+
+	tests := []struct {
+		name    string
+		rule    subjectURLsT
+		subject string
+		wantErr string
+	}{
+		{"none", verify, "BUG/MINOR: mux: fix a crash on close", ""},
+		{
+			"https", verify, "BUG/MINOR: mux: fix https://github.com/haproxy/haproxy/issues/12",
+			"subject contains the URL 'https://github.com/haproxy/haproxy/issues/12', move it to the body or a trailer",
+		},
+		{"upper case", verify, "DOC: link HTTP://example.com", "subject contains the URL " +
+			"'HTTP://example.com', move it to the body or a trailer"},
+		{"scheme only", verify, "MINOR: http: reject http:// without a host", ""},
+		{"off by default", subjectURLsT{}, "DOC: link https://example.com", ""},
+	}
+
+	for _, tt := range tests {
+		err := tt.rule.Check(tt.subject)
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrSubjectURL) || err.Error() != tt.wantErr+": "+ErrSubjectURL.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := parseCommitPolicy("SubjectURLs:\n  Severity: fatal\n"); !errors.Is(err, ErrSubjectURLsConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrSubjectURLsConfig)
+	}
+}
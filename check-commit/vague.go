@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// vagueSubjectsT flags the low-information subjects the word count lets through, such as
+// "BUG/MINOR: mux: fix stuff": those whose text past the tags and component is entirely
+// matched by one of the built-in vague phrases or of Patterns, regardless of case.
+type vagueSubjectsT struct {
+	Verify   bool     `yaml:"Verify"`
+	Patterns []string `yaml:"Patterns"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+// vaguePhrases are the built-in patterns of the vague subjects.
+var vaguePhrases = []string{
+	`(?:misc|various|minor|small|some|more)(?: (?:changes|fixes|updates|tweaks|improvements|stuff|things))?`,
+	`(?:changes|fixes|updates|tweaks|improvements|stuff|wip|typos?|cleanup|refactor(?:ing)?)`,
+	`(?:fix|fixed|update|updated|change|changed|tweak|tweaked|improve|improved|clean ?up|cleaned up|refactor)` +
+		`(?: (?:it|this|that|things|stuff|code|bugs?|issues?|some (?:things|stuff|bugs|issues)|more))?`,
+}
+
+var ErrVagueSubjectsConfig = errors.New("invalid vague subjects rule")
+
+func (v vagueSubjectsT) validate() error {
+	for _, pattern := range v.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("vague subjects rule: %s: %w", err, ErrVagueSubjectsConfig)
+		}
+	}
+
+	if !validSeverity(v.Severity) {
+		return fmt.Errorf("vague subjects rule: unknown severity '%s': %w", v.Severity, ErrVagueSubjectsConfig)
+	}
+
+	return nil
+}
+
+// severity defaults to warning, the information a subject carries being guessed.
+func (v vagueSubjectsT) severity() string {
+	if v.Severity == "" {
+		return severityWarning
+	}
+
+	return v.Severity
+}
+
+var ErrVagueSubject = errors.New("vague subject")
+
+// Check checks the text of a subject, past its tags and component.
+func (v vagueSubjectsT) Check(text string) error {
+	if !v.Verify {
+		return nil
+	}
+
+	if component, ok := subjectComponent(text); ok {
+		text = strings.TrimPrefix(text, component+": ")
+	}
+
+	phrase := strings.TrimRight(strings.Join(strings.Fields(text), " "), ".!")
+
+	for _, pattern := range append(append([]string{}, vaguePhrases...), v.Patterns...) {
+		if regexp.MustCompile(`(?i)^(?:` + pattern + `)$`).MatchString(phrase) { // validated when loading
+			return fmt.Errorf("subject '%s' says too little about the change, describe what it does and why: %w",
+				phrase, ErrVagueSubject)
+		}
+	}
+
+	return nil
+}
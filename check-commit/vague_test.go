@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVagueSubjects(t *testing.T) {
+	t.Parallel()
+
+	rule := vagueSubjectsT{Verify: true, Patterns: []string{`address (?:review|comments)`}}
+
+	tests := []struct {
+		text    string
+		wantErr bool
+	}{
+		{"mux: fix stuff", true},
+		{"Update", true},
+		{"misc changes.", true},
+		{"config: minor fixes", true},
+		{"cleanup code", true},
+		{"h2: address review", true},
+		{"mux: fix a crash on close", false},
+		{"config: update the default timeouts", false},
+		{"cli: add the misc command", false},
+	}
+
+	for _, tt := range tests {
+		if err := rule.Check(tt.text); (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrVagueSubject) {
+			t.Errorf("Check(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+		}
+	}
+
+	err := rule.Check("mux:  Fix  stuff.")
+	if want := "subject 'Fix stuff' says too little about the change, describe what it does and why: " +
+		ErrVagueSubject.Error(); err == nil || err.Error() != want {
+		t.Errorf("Check() error = %v, want %s", err, want)
+	}
+
+	if err := (vagueSubjectsT{}).Check("fix stuff"); err != nil {
+		t.Errorf("Check() without Verify error = %v", err)
+	}
+
+	if _, err := parseCommitPolicy("VagueSubjects:\n  Patterns: ['(fix']\n"); !errors.Is(err, ErrVagueSubjectsConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrVagueSubjectsConfig)
+	}
+}
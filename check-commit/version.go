@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v35/github"
+)
+
+const (
+	bumpNone  = "none"
+	bumpPatch = "patch"
+	bumpMinor = "minor"
+	bumpMajor = "major"
+)
+
+type versionBumpT struct {
+	Major []string `yaml:"Major"`
+	Minor []string `yaml:"Minor"`
+	Patch []string `yaml:"Patch"`
+}
+
+// versionBump computes the semantic version bump of a set of commits: breaking commits
+// always bump the major version, otherwise the highest level listing one of the tags
+// or severities wins. Without any Patch list every tagged commit is at least a patch.
+func (c CommitPolicyConfig) versionBump(commits []commitT) string {
+	levels := []struct {
+		name   string
+		values []string
+	}{
+		{name: bumpPatch, values: c.VersionBump.Patch},
+		{name: bumpMinor, values: c.VersionBump.Minor},
+		{name: bumpMajor, values: c.VersionBump.Major},
+	}
+	level := -1
+
+	for _, commit := range commits {
+		if c.isBreaking(commit) {
+			return bumpMajor
+		}
+
+		tags := c.subjectTags(commit.Subject())
+		if len(tags) > 0 && len(c.VersionBump.Patch) == 0 && level < 0 {
+			level = 0
+		}
+
+		for i, l := range levels {
+			if i > level && hasAnyValue(tags, l.values) {
+				level = i
+			}
+		}
+	}
+
+	if level < 0 {
+		return bumpNone
+	}
+
+	return levels[level].name
+}
+
+// nextVersion applies bump to a vMAJOR.MINOR.PATCH version, keeping the optional "v" prefix.
+func nextVersion(current, bump string) (string, bool) {
+	r := regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+	m := r.FindStringSubmatch(current)
+	if m == nil {
+		return "", false
+	}
+
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+
+	switch bump {
+	case bumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case bumpMinor:
+		minor, patch = minor+1, 0
+	case bumpPatch:
+		patch++
+	default:
+		return current, true
+	}
+
+	return fmt.Sprintf("%s%d.%d.%d", m[1], major, minor, patch), true
+}
+
+// latestVersionTag returns the highest version tag of the local clone.
+func latestVersionTag(repoPath string) (string, error) {
+	out, err := runGit(repoPath, "tag", "--list", "--sort=-v:refname")
+	if err != nil {
+		return "", err
+	}
+
+	for _, tag := range strings.Fields(out) {
+		if _, ok := nextVersion(tag, bumpNone); ok {
+			return tag, nil
+		}
+	}
+
+	return "", nil
+}
+
+// isDefaultBranchPush reports whether the run was triggered by commits landing on the
+// default branch, e.g. after a merge.
+func isDefaultBranchPush(repoEnv string) bool {
+	switch repoEnv {
+	case GITHUB:
+		if os.Getenv("GITHUB_EVENT_NAME") != "push" {
+			return false
+		}
+
+		var event github.PushEvent
+		if err := readGithubEvent(&event); err != nil {
+			return false
+		}
+
+		defaultBranch := event.GetRepo().GetDefaultBranch()
+
+		return defaultBranch != "" && event.GetRef() == "refs/heads/"+defaultBranch
+	case GITLAB:
+		return os.Getenv("CI_PIPELINE_SOURCE") == "push" &&
+			os.Getenv("CI_COMMIT_BRANCH") != "" &&
+			os.Getenv("CI_COMMIT_BRANCH") == os.Getenv("CI_DEFAULT_BRANCH")
+	}
+
+	return false
+}
+
+func (c CommitPolicyConfig) versionOutputs(repoPath string, commits []commitT) []outputT {
+	bump := c.versionBump(commits)
+	outputs := []outputT{{Name: "version_bump", Value: bump}}
+
+	current, err := latestVersionTag(repoPath)
+	if err != nil {
+		log.Printf("warning: unable to find the current version: %s", err)
+	}
+
+	if next, ok := nextVersion(current, bump); ok {
+		log.Printf("suggested version bump: %s (%s -> %s)", bump, current, next)
+
+		outputs = append(outputs,
+			outputT{Name: "current_version", Value: current},
+			outputT{Name: "next_version", Value: next},
+		)
+	} else {
+		log.Printf("suggested version bump: %s", bump)
+	}
+
+	return outputs
+}
+
+// versionFileT requires the version file to be updated by requests containing breaking
+// commits: ones carrying one of Values, or breaking as defined by isBreaking.
+type versionFileT struct {
+	Paths    []string `yaml:"Paths"`
+	Pattern  string   `yaml:"Pattern"`
+	Values   []string `yaml:"Values"`
+	Severity string   `yaml:"Severity"`
+	Shadow   bool     `yaml:"Shadow"`
+}
+
+var ErrVersionFileConfig = errors.New("invalid version file rule")
+
+func (v versionFileT) validate() error {
+	if _, err := regexp.Compile(v.Pattern); err != nil {
+		return fmt.Errorf("version file rule: %s: %w", err, ErrVersionFileConfig)
+	}
+
+	if !validSeverity(v.Severity) {
+		return fmt.Errorf("version file rule: unknown severity '%s': %w", v.Severity, ErrVersionFileConfig)
+	}
+
+	return nil
+}
+
+func (v versionFileT) enabled() bool {
+	return len(v.Paths) > 0
+}
+
+func (v versionFileT) values() []string {
+	if len(v.Values) == 0 {
+		return []string{"MAJOR", "CRITICAL"}
+	}
+
+	return v.Values
+}
+
+// severity defaults to warning: not every breaking change needs a release right away.
+func (v versionFileT) severity() string {
+	if v.Severity == "" {
+		return severityWarning
+	}
+
+	return v.Severity
+}
+
+// updates tells whether the commit changes the version file, on a line matching
+// Pattern when one is set (e.g. the VERSION variable of a Makefile).
+func (v versionFileT) updates(commit commitT) bool {
+	for _, file := range commit.Files {
+		if !matchAnyGlob(v.Paths, file.Path) {
+			continue
+		}
+
+		if v.Pattern == "" || commit.NamesOnly { // the changed lines are unknown, give it the benefit of the doubt
+			return true
+		}
+
+		r := regexp.MustCompile(v.Pattern) // validated when loading the configuration
+		for _, line := range file.Added {
+			if r.MatchString(line) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var ErrVersionNotBumped = errors.New("version file not updated")
+
+func (c CommitPolicyConfig) CheckVersionFile(commits []commitT) error {
+	v := c.VersionFile
+	if !v.enabled() {
+		return nil
+	}
+
+	breaking := []string{}
+
+	for _, commit := range commits {
+		if !commit.HasDiff {
+			return nil // unable to tell, the clone lacks some commits
+		}
+
+		if v.updates(commit) {
+			return nil
+		}
+
+		if c.isBreaking(commit) || hasAnyValue(c.subjectTags(commit.Subject()), v.values()) {
+			breaking = append(breaking, shortSHA(commit.SHA))
+		}
+	}
+
+	if len(breaking) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("breaking commits [%s] require updating the version in [%s]: %w",
+		strings.Join(breaking, ", "), strings.Join(v.Paths, ", "), ErrVersionNotBumped)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersionBump(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{VersionBump: versionBumpT{
+		Major: []string{"CRITICAL"},
+		Minor: []string{"MEDIUM"},
+		Patch: []string{"BUG"},
+	}}
+
+	tests := []struct {
+		name     string
+		messages []string
+		want     string
+	}{
+		{name: "nothing relevant", messages: []string{"DOC: config: fix typo"}, want: bumpNone},
+		{name: "patch", messages: []string{"DOC: config: fix typo", "BUG/MINOR: config: fix crash"}, want: bumpPatch},
+		{name: "minor by severity", messages: []string{"BUG/MEDIUM: config: fix crash"}, want: bumpMinor},
+		{name: "major", messages: []string{"MINOR: config: add kw", "CRITICAL: config: new engine"}, want: bumpMajor},
+		{name: "breaking footer", messages: []string{"MINOR: config: drop kw\n\nBREAKING CHANGE: kw is gone"}, want: bumpMajor},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			commits := []commitT{}
+			for _, message := range tt.messages {
+				commits = append(commits, commitT{Message: message})
+			}
+			if got := c.versionBump(commits); got != tt.want {
+				t.Errorf("versionBump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (CommitPolicyConfig{}).versionBump([]commitT{{Message: "DOC: config: fix typo"}}); got != bumpPatch {
+		t.Errorf("versionBump() without Patch list = %v, want %v", got, bumpPatch)
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		current, bump, want string
+		ok                  bool
+	}{
+		{current: "v1.2.3", bump: bumpMajor, want: "v2.0.0", ok: true},
+		{current: "1.2.3", bump: bumpMinor, want: "1.3.0", ok: true},
+		{current: "v1.2.3", bump: bumpPatch, want: "v1.2.4", ok: true},
+		{current: "v1.2.3", bump: bumpNone, want: "v1.2.3", ok: true},
+		{current: "release-1", bump: bumpPatch, want: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := nextVersion(tt.current, tt.bump); got != tt.want || ok != tt.ok {
+			t.Errorf("nextVersion(%s, %s) = %v, %v, want %v, %v", tt.current, tt.bump, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLatestVersionTag(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "MINOR: git: first commit of the test repository")
+	for _, tag := range []string{"v1.9.0", "v1.10.0", "nightly"} {
+		if _, err := runGit(repo, "tag", tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, err := latestVersionTag(repo); err != nil || got != "v1.10.0" {
+		t.Errorf("latestVersionTag() = %v, %v, want v1.10.0", got, err)
+	}
+}
+
+func TestCheckVersionFile(t *testing.T) {
+	t.Parallel()
+
+	c := CommitPolicyConfig{VersionFile: versionFileT{Paths: []string{"VERSION", "Makefile"}, Pattern: `^VERSION *=`}}
+
+	commit := func(message string, files ...fileDiffT) commitT {
+		return commitT{SHA: "1111111111111111111111111111111111111111", Message: message, Files: files, HasDiff: true}
+	}
+	makefile := func(added ...string) fileDiffT {
+		return fileDiffT{Path: "Makefile", OldPath: "Makefile", Status: fileModified, Added: added}
+	}
+
+	tests := []struct {
+		name    string
+		commits []commitT
+		wantErr bool
+	}{
+		{"not breaking", []commitT{commit("BUG/MINOR: mux: fix a crash")}, false},
+		{"major without bump", []commitT{commit("MAJOR: mux: new engine"), commit("MINOR: mux: add flag")}, true},
+		{"breaking footer without bump", []commitT{commit("MINOR: cfg: drop kw\n\nBREAKING CHANGE: kw is gone")}, true},
+		{"bumped", []commitT{commit("MAJOR: mux: new engine"), commit("RELEASE: 3.0", makefile("VERSION = 3.0"))}, false},
+		{"makefile changed elsewhere", []commitT{commit("MAJOR: mux: new engine", makefile("CFLAGS += -O2"))}, true},
+		{"diff not loaded", []commitT{{Message: "MAJOR: mux: new engine"}}, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.CheckVersionFile(tt.commits)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrVersionNotBumped)) {
+				t.Errorf("CheckVersionFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	versionKey = "Version"
+	// policyVersion is the version of the policy schema this binary understands,
+	// policies without Version being of the first one.
+	policyVersion = 1
+)
+
+// migrationT translates a policy of version From to the next version.
+type migrationT struct {
+	From        int
+	Description string
+	Migrate     func(document map[interface{}]interface{})
+}
+
+// policyMigrations lists the migrations between the successive schema versions.
+var policyMigrations = []migrationT{}
+
+var ErrPolicyVersion = errors.New("unsupported policy version")
+
+// migratePolicy migrates the policy from its Version to the current one, logging what
+// was translated, and refuses the policies newer than the current version.
+func migratePolicy(config string, migrations []migrationT, current int) (string, error) {
+	document := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(config), &document); err != nil {
+		return config, nil // reported when loading the policy
+	}
+
+	version := 1
+
+	if value, ok := document[versionKey]; ok {
+		if version, ok = value.(int); !ok || version < 1 {
+			return "", fmt.Errorf("%s must be a positive integer: %w", versionKey, ErrPolicyVersion)
+		}
+	}
+
+	switch {
+	case version > current:
+		return "", fmt.Errorf("policy version %d is newer than version %d supported by this check-commit, "+
+			"please upgrade it: %w", version, current, ErrPolicyVersion)
+	case version == current:
+		return config, nil
+	}
+
+	for ; version < current; version++ {
+		migrated := false
+
+		for _, migration := range migrations {
+			if migration.From == version {
+				migration.Migrate(document)
+				log.Printf("policy migrated from version %d to %d: %s", version, version+1, migration.Description)
+
+				migrated = true
+			}
+		}
+
+		if !migrated {
+			return "", fmt.Errorf("no migration from policy version %d: %w", version, ErrPolicyVersion)
+		}
+	}
+
+	document[versionKey] = current
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("error migrating the policy: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// decodePolicy converts the policy to YAML and migrates it to the current schema.
+func decodePolicy(config string) (string, error) {
+	config, err := policyToYAML(config)
+	if err != nil {
+		return "", err
+	}
+
+	return migratePolicy(config, policyMigrations, policyVersion)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMigratePolicy(t *testing.T) {
+	t.Parallel()
+
+	migrations := []migrationT{{
+		From:        1,
+		Description: "HelpText renamed to Help",
+		Migrate: func(document map[interface{}]interface{}) {
+			if value, ok := document["HelpText"]; ok {
+				document["Help"] = value
+				delete(document, "HelpText")
+			}
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		config  string
+		want    string
+		wantErr string
+	}{
+		{"current", "Version: 2\nHelpText: x\n", "Version: 2\nHelpText: x\n", ""},
+		{"implicit first version", "HelpText: x\n", "Help: x\nVersion: 2\n", ""},
+		{"explicit first version", "Version: 1\nHelpText: x\n", "Help: x\nVersion: 2\n", ""},
+		{"newer", "Version: 3\n", "", "policy version 3 is newer than version 2 supported by this check-commit"},
+		{"invalid", "Version: one\n", "", "Version must be a positive integer"},
+		{"zero", "Version: 0\n", "", "Version must be a positive integer"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := migratePolicy(tt.config, migrations, 2)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrPolicyVersion) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("migratePolicy() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("migratePolicy() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratePolicyMissingMigration(t *testing.T) {
+	t.Parallel()
+
+	if _, err := migratePolicy("HelpText: x\n", nil, 2); !errors.Is(err, ErrPolicyVersion) {
+		t.Errorf("migratePolicy() error = %v, want %v", err, ErrPolicyVersion)
+	}
+}
+
+func TestParseCommitPolicyVersion(t *testing.T) {
+	t.Parallel()
+
+	config, err := decodePolicy("Version: 1\nMaxCommits: 3\n")
+	if err != nil {
+		t.Fatalf("decodePolicy() error = %v", err)
+	}
+
+	if _, err := parseCommitPolicy(config); err != nil {
+		t.Errorf("parseCommitPolicy() error = %v", err)
+	}
+
+	if _, err := decodePolicy("Version: 2\n"); !errors.Is(err, ErrPolicyVersion) {
+		t.Errorf("decodePolicy() error = %v, want %v", err, ErrPolicyVersion)
+	}
+}
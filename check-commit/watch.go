@@ -0,0 +1,131 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxWatchCommits bounds the commits checked at once, e.g. when switching to a
+// branch with a long unrelated history.
+const maxWatchCommits = 50
+
+// watcherT follows HEAD of a clone and yields the commits it gains, which covers
+// new commits, amends and each step of an interactive rebase.
+type watcherT struct {
+	repoPath string
+	head     string
+	checked  map[string]bool
+}
+
+func newWatcher(repoPath string) *watcherT {
+	w := &watcherT{repoPath: repoPath, checked: map[string]bool{}}
+	w.head, _ = gitHead(repoPath)
+
+	return w
+}
+
+func gitHead(repoPath string) (string, error) {
+	out, err := runGit(repoPath, "rev-parse", "-q", "--verify", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// poll returns the commits that became reachable from HEAD since the previous call
+// and were not already returned.
+func (w *watcherT) poll() ([]commitT, error) {
+	// an unborn HEAD just means there is nothing to check yet
+	head, _ := gitHead(w.repoPath)
+	if head == "" || head == w.head {
+		return nil, nil
+	}
+
+	args := []string{"log", gitCommitFormat, "-n", strconv.Itoa(maxWatchCommits), head}
+	if w.head != "" {
+		args = append(args, "--not", w.head)
+	}
+
+	w.head = head
+
+	out, err := runGit(w.repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []commitT{}
+
+	for _, commit := range parseGitCommits(out) {
+		if !w.checked[commit.SHA] {
+			w.checked[commit.SHA] = true
+
+			commits = append(commits, commit)
+		}
+	}
+
+	// oldest first, in the order they were written
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+
+	return commits, nil
+}
+
+// watch checks new commits of the clone as they appear, until an error occurs. The
+// configuration is reloaded for each batch so that edits apply immediately.
+func watch(opts optionsT) error {
+	repoPath := opts.repoPath
+	w := newWatcher(repoPath)
+
+	log.Printf("watching %s for new commits, press Ctrl-C to stop", repoPath)
+
+	for {
+		commits, err := w.poll()
+		if err != nil {
+			return err
+		}
+
+		if len(commits) > 0 {
+			read, err := opts.locatePolicy(localPolicyReader(repoPath))
+
+			var commitPolicy CommitPolicyConfig
+			if err == nil {
+				commitPolicy, err = loadPolicy(read, opts.policyKey, opts.preset, opts.profile)
+			}
+
+			if err == nil {
+				commitPolicy, err = opts.resolvePolicyFiles(commitPolicy, LOCAL)
+			}
+
+			if err != nil {
+				log.Printf("error reading configuration: %s", err)
+			} else {
+				commitPolicy.watchReport(repoPath, commits)
+			}
+		}
+
+		time.Sleep(opts.interval)
+	}
+}
+
+func (c CommitPolicyConfig) watchReport(repoPath string, commits []commitT) {
+	commits, ignored := c.skipCommits(commits)
+	for _, exception := range ignored {
+		log.Print(exception.Reason)
+	}
+
+	c.resolveAliases(commits)
+	c.loadDiffs(repoPath, commits)
+
+	for _, commit := range commits {
+		report := c.newReport([]commitT{commit})
+		c.checkCommits(report.Commits, &report)
+
+		if len(report.Findings) == 0 {
+			log.Printf("ok, commit %s '%s'", shortSHA(commit.SHA), commit.Subject())
+		}
+	}
+}
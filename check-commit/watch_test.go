@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestWatcherPoll(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepo(t, "MINOR: watch: first commit of the test repository")
+	w := newWatcher(repo)
+
+	commit := func(args ...string) {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		name  string
+		git   []string
+		wants []string
+	}{
+		{name: "unchanged"},
+		{
+			name:  "commit",
+			git:   []string{"commit", "-q", "--allow-empty", "-m", "BUG/MINOR: watch: second commit"},
+			wants: []string{"BUG/MINOR: watch: second commit"},
+		},
+		{
+			name:  "amend",
+			git:   []string{"commit", "-q", "--amend", "--allow-empty", "-m", "BUG/MINOR: watch: amended commit"},
+			wants: []string{"BUG/MINOR: watch: amended commit"},
+		},
+		{name: "reset", git: []string{"reset", "-q", "--hard", "HEAD~1"}},
+		{name: "back to an already checked commit", git: []string{"reset", "-q", "--hard", "HEAD@{1}"}},
+	}
+
+	for _, step := range steps {
+		if step.git != nil {
+			commit(step.git...)
+		}
+
+		commits, err := w.poll()
+		if err != nil {
+			t.Fatalf("%s: poll() error = %v", step.name, err)
+		}
+
+		if len(commits) != len(step.wants) {
+			t.Fatalf("%s: poll() = %d commits, want %d", step.name, len(commits), len(step.wants))
+		}
+
+		for i, want := range step.wants {
+			if got := commits[i].Subject(); got != want {
+				t.Errorf("%s: poll()[%d] = '%s', want '%s'", step.name, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// whitespaceT rejects the subjects whose spacing is not made of single plain spaces:
+// leading or trailing whitespace, runs of spaces, tabs, and non-breaking or other
+// exotic spaces, which would otherwise only be normalized.
+type whitespaceT struct {
+	Severity string `yaml:"Severity"`
+	Shadow   bool   `yaml:"Shadow"`
+}
+
+var ErrWhitespaceConfig = errors.New("invalid whitespace rule")
+
+func (w whitespaceT) validate() error {
+	if !validSeverity(w.Severity) {
+		return fmt.Errorf("whitespace rule: unknown severity '%s': %w", w.Severity, ErrWhitespaceConfig)
+	}
+
+	return nil
+}
+
+func (w whitespaceT) severity() string {
+	if w.Severity == "" {
+		return severityError
+	}
+
+	return w.Severity
+}
+
+// isNonBreakingSpace tells whether the rune is one of the non-breaking spaces.
+func isNonBreakingSpace(r rune) bool {
+	switch r {
+	case '\u00a0', '\u2007', '\u202f':
+		return true
+	}
+
+	return false
+}
+
+var ErrWhitespace = errors.New("invalid whitespace in subject")
+
+// Check checks the spacing of the raw subject, listing all its problems at once.
+func (w whitespaceT) Check(subject string) error {
+	issues := []string{}
+	add := func(issue string) {
+		if !containsString(issues, issue) {
+			issues = append(issues, issue)
+		}
+	}
+
+	if trimmed := strings.TrimLeftFunc(subject, unicode.IsSpace); trimmed != subject {
+		add("leading whitespace")
+	}
+
+	if trimmed := strings.TrimRightFunc(subject, unicode.IsSpace); trimmed != subject {
+		add("trailing whitespace")
+	}
+
+	inner := strings.TrimFunc(subject, unicode.IsSpace)
+	previousSpace := false
+
+	for _, r := range subject {
+		switch {
+		case r == '\t':
+			add("tabs")
+		case isNonBreakingSpace(r):
+			add(fmt.Sprintf("a non-breaking space %U", r))
+		case r != ' ' && unicode.IsSpace(r):
+			add(fmt.Sprintf("the whitespace character %U", r))
+		}
+	}
+
+	for _, r := range inner {
+		space := unicode.IsSpace(r)
+		if space && previousSpace {
+			add("consecutive spaces")
+		}
+
+		previousSpace = space
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("subject contains %s: %w", strings.Join(issues, ", "), ErrWhitespace)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWhitespace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		subject string
+		wantErr string
+	}{
+		{"single spaces", "BUG/MINOR: mux: fix a crash on close", ""},
+		{"double spaces", "BUG/MINOR: mux:  fix a crash on close", "subject contains consecutive spaces"},
+		{"leading", " BUG/MINOR: mux: fix a crash on close", "subject contains leading whitespace"},
+		{"trailing", "BUG/MINOR: mux: fix a crash on close ", "subject contains trailing whitespace"},
+		{"tab", "BUG/MINOR: mux:\tfix a crash on close", "subject contains tabs"},
+		{
+			"non-breaking space", "BUG/MINOR: mux:\u00a0fix a crash on close",
+			"subject contains a non-breaking space U+00A0",
+		},
+		{
+			"all of them", "\tBUG/MINOR: mux: \u2003fix a crash on close ",
+			"subject contains leading whitespace, trailing whitespace, tabs, the whitespace character U+2003, " +
+				"consecutive spaces",
+		},
+	}
+
+	for _, tt := range tests {
+		err := whitespaceT{}.Check(tt.subject)
+
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Check() error = %v", tt.name, err)
+		case tt.wantErr != "" && (!errors.Is(err, ErrWhitespace) || err.Error() != tt.wantErr+": "+ErrWhitespace.Error()):
+			t.Errorf("%s: Check() error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := parseCommitPolicy("Whitespace:\n  Severity: fatal\n"); !errors.Is(err, ErrWhitespaceConfig) {
+		t.Errorf("parseCommitPolicy() error = %v, want %v", err, ErrWhitespaceConfig)
+	}
+}